@@ -0,0 +1,32 @@
+// Package atomicfile replaces files atomically, so that a crash while writing a state file does not
+// corrupt its previous contents.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes contents to a temporary file next to path, syncs it to disk, and renames it over
+// path. Readers see either the previous contents of the file or the new contents, never a mix.
+func WriteFile(path string, contents []byte) error {
+	tmpFile, tmpErr := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if tmpErr != nil {
+		return tmpErr
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(contents); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
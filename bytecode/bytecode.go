@@ -0,0 +1,208 @@
+// Package bytecode checks whether the code deployed at an address is the code of a contract built into
+// this module's bindings.
+//
+// The bindings embed each contract's creation bytecode, not its runtime bytecode, so the runtime
+// bytecode is embedded separately: artifacts/<Contract>.json holds the deployedBytecode object and
+// immutableReferences of the forge artifact that the bindings were generated from (see the Makefile).
+// Bytes which hold immutable variables are written by the constructor, so they are ignored when
+// comparing. The CBOR-encoded metadata which solc appends to the runtime bytecode (which includes a hash
+// of the contract's source and compiler settings) is compared separately, since it changes with edits
+// that do not affect the compiled code.
+package bytecode
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/bindings/DevDegenGambit"
+)
+
+var ErrNoCode error = errors.New("no code at address")
+
+//go:embed artifacts/*.json
+var artifactFiles embed.FS
+
+// Artifact is a contract as built into the bindings.
+type Artifact struct {
+	Name string
+	// Creation bytecode, without constructor arguments, as embedded in the bindings.
+	CreationCode []byte
+	// Runtime bytecode, including metadata, from the forge artifact's deployedBytecode.
+	RuntimeCode []byte
+	// Location of each immutable variable in RuntimeCode, keyed by the AST ID of its declaration.
+	ImmutableReferences map[string][]ImmutableReference
+}
+
+// ImmutableReference is the location of an immutable variable in runtime bytecode.
+type ImmutableReference struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// deployedBytecode is the part of a forge artifact's deployedBytecode which is embedded.
+type deployedBytecode struct {
+	Object              hexutil.Bytes                   `json:"object"`
+	ImmutableReferences map[string][]ImmutableReference `json:"immutableReferences"`
+}
+
+// KnownArtifacts returns the contracts which Identify compares deployed code against.
+func KnownArtifacts() ([]Artifact, error) {
+	creationCodes := []struct {
+		name string
+		bin  string
+	}{
+		{"DegenGambit", DegenGambit.DegenGambitMetaData.Bin},
+		{"DevDegenGambit", DevDegenGambit.DevDegenGambitMetaData.Bin},
+	}
+
+	artifacts := make([]Artifact, len(creationCodes))
+	for i, creationCode := range creationCodes {
+		contents, readErr := artifactFiles.ReadFile("artifacts/" + creationCode.name + ".json")
+		if readErr != nil {
+			return nil, readErr
+		}
+		var deployed deployedBytecode
+		if err := json.Unmarshal(contents, &deployed); err != nil {
+			return nil, fmt.Errorf("failed to parse runtime bytecode of %s: %v", creationCode.name, err)
+		}
+		creation, creationErr := hexutil.Decode(creationCode.bin)
+		if creationErr != nil {
+			return nil, fmt.Errorf("failed to decode creation bytecode of %s: %v", creationCode.name, creationErr)
+		}
+		// Without immutables, the constructor copies the runtime bytecode out of the creation bytecode
+		// unchanged, so an embedded runtime bytecode which it does not contain is from a different build.
+		if len(deployed.ImmutableReferences) == 0 && !bytes.Contains(creation, deployed.Object) {
+			return nil, fmt.Errorf("runtime bytecode of %s was not built with its bindings, regenerate it with make generate", creationCode.name)
+		}
+		artifacts[i] = Artifact{
+			Name:                creationCode.name,
+			CreationCode:        creation,
+			RuntimeCode:         deployed.Object,
+			ImmutableReferences: deployed.ImmutableReferences,
+		}
+	}
+	return artifacts, nil
+}
+
+// Reference is the expected runtime bytecode of an artifact.
+type Reference struct {
+	Artifact Artifact
+	// Runtime bytecode, without metadata.
+	Code []byte
+	// CBOR-encoded metadata appended to the runtime bytecode, including its 2-byte length suffix.
+	Metadata []byte
+	// Immutable[i] is true if byte i of Code is part of an immutable variable.
+	Immutable []bool
+}
+
+// ImmutableBytes returns the number of bytes of the runtime bytecode which hold immutable variables.
+func (r Reference) ImmutableBytes() int {
+	count := 0
+	for _, immutable := range r.Immutable {
+		if immutable {
+			count++
+		}
+	}
+	return count
+}
+
+// NewReference derives the expected runtime bytecode of an artifact.
+func NewReference(artifact Artifact) (Reference, error) {
+	code, metadata := SplitMetadata(artifact.RuntimeCode)
+
+	immutable := make([]bool, len(code))
+	for id, references := range artifact.ImmutableReferences {
+		for _, reference := range references {
+			if reference.Start < 0 || reference.Length < 0 || reference.Start+reference.Length > len(code) {
+				return Reference{}, fmt.Errorf("immutable %s of %s is outside of its runtime bytecode", id, artifact.Name)
+			}
+			for i := reference.Start; i < reference.Start+reference.Length; i++ {
+				immutable[i] = true
+			}
+		}
+	}
+
+	return Reference{Artifact: artifact, Code: code, Metadata: metadata, Immutable: immutable}, nil
+}
+
+// SplitMetadata separates the CBOR-encoded metadata which solc appends to runtime bytecode from the
+// code itself. The last two bytes of the bytecode are the big-endian length of the metadata. If the
+// bytecode does not end in metadata, it is returned unchanged.
+func SplitMetadata(code []byte) (body, metadata []byte) {
+	if len(code) < 2 {
+		return code, nil
+	}
+	length := int(binary.BigEndian.Uint16(code[len(code)-2:]))
+	// Metadata is a CBOR map, so its first byte is a map header (major type 5).
+	if length == 0 || length+2 > len(code) || code[len(code)-2-length]>>5 != 5 {
+		return code, nil
+	}
+	split := len(code) - 2 - length
+	return code[:split], code[split:]
+}
+
+// Comparison is the result of comparing deployed code against a reference.
+type Comparison struct {
+	Reference Reference
+	// Whether the code matches the reference, ignoring immutables and metadata.
+	CodeMatches bool
+	// Whether the metadata (and therefore the source and compiler settings) matches as well.
+	MetadataMatches bool
+	// Offset of the first byte (outside of immutables) at which the code differs from the reference,
+	// or -1 if the code matches.
+	FirstDifference int
+}
+
+// Compare compares deployed runtime bytecode against a reference.
+func Compare(deployed []byte, reference Reference) Comparison {
+	code, metadata := SplitMetadata(deployed)
+	comparison := Comparison{Reference: reference, FirstDifference: -1}
+
+	for i := 0; i < len(code) && i < len(reference.Code); i++ {
+		if !reference.Immutable[i] && code[i] != reference.Code[i] {
+			comparison.FirstDifference = i
+			break
+		}
+	}
+	if comparison.FirstDifference == -1 && len(code) != len(reference.Code) {
+		comparison.FirstDifference = min(len(code), len(reference.Code))
+	}
+
+	comparison.CodeMatches = comparison.FirstDifference == -1
+	comparison.MetadataMatches = comparison.CodeMatches && bytes.Equal(metadata, reference.Metadata)
+	return comparison
+}
+
+// Identify compares deployed runtime bytecode against every known artifact. match is the comparison
+// with the artifact whose code matches, or nil if there is none.
+func Identify(deployed []byte) (match *Comparison, comparisons []Comparison, err error) {
+	if len(deployed) == 0 {
+		return nil, nil, ErrNoCode
+	}
+
+	artifacts, artifactsErr := KnownArtifacts()
+	if artifactsErr != nil {
+		return nil, nil, artifactsErr
+	}
+
+	comparisons = make([]Comparison, len(artifacts))
+	for i, artifact := range artifacts {
+		reference, referenceErr := NewReference(artifact)
+		if referenceErr != nil {
+			return nil, nil, referenceErr
+		}
+		comparisons[i] = Compare(deployed, reference)
+		if comparisons[i].CodeMatches && match == nil {
+			match = &comparisons[i]
+		}
+	}
+
+	return match, comparisons, nil
+}
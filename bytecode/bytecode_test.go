@@ -0,0 +1,90 @@
+package bytecode
+
+import (
+	"testing"
+)
+
+func TestIdentifyKnownArtifacts(t *testing.T) {
+	artifacts, err := KnownArtifacts()
+	if err != nil {
+		t.Fatalf("failed to load artifacts: %v", err)
+	}
+
+	for _, artifact := range artifacts {
+		match, comparisons, identifyErr := Identify(artifact.RuntimeCode)
+		if identifyErr != nil {
+			t.Fatalf("failed to identify %s: %v", artifact.Name, identifyErr)
+		}
+		if match == nil || match.Reference.Artifact.Name != artifact.Name || !match.MetadataMatches {
+			t.Fatalf("expected the runtime bytecode of %s to be identified as itself, got %+v", artifact.Name, match)
+		}
+		if len(comparisons) != len(artifacts) {
+			t.Fatalf("expected a comparison with each of the %d artifacts, got %d", len(artifacts), len(comparisons))
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	artifacts, err := KnownArtifacts()
+	if err != nil {
+		t.Fatalf("failed to load artifacts: %v", err)
+	}
+	reference, referenceErr := NewReference(artifacts[0])
+	if referenceErr != nil {
+		t.Fatalf("failed to create reference: %v", referenceErr)
+	}
+	if len(reference.Metadata) == 0 {
+		t.Fatalf("expected %s to end in metadata", reference.Artifact.Name)
+	}
+
+	// Different metadata (e.g. a different comment in the source) with the same code.
+	otherMetadata := append([]byte{}, artifacts[0].RuntimeCode...)
+	otherMetadata[len(reference.Code)+10] ^= 0xff
+	if comparison := Compare(otherMetadata, reference); !comparison.CodeMatches || comparison.MetadataMatches {
+		t.Fatalf("expected the code to match with different metadata, got %+v", comparison)
+	}
+
+	otherCode := append([]byte{}, artifacts[0].RuntimeCode...)
+	otherCode[100] ^= 0xff
+	if comparison := Compare(otherCode, reference); comparison.CodeMatches || comparison.FirstDifference != 100 {
+		t.Fatalf("expected the code to differ at byte 100, got %+v", comparison)
+	}
+
+	// Immutable bytes are ignored.
+	withImmutable := reference
+	withImmutable.Immutable = make([]bool, len(reference.Code))
+	withImmutable.Immutable[100] = true
+	if comparison := Compare(otherCode, withImmutable); !comparison.CodeMatches {
+		t.Fatalf("expected a difference in an immutable to be ignored, got %+v", comparison)
+	}
+
+	if comparison := Compare(reference.Code[:len(reference.Code)-1], reference); comparison.CodeMatches || comparison.FirstDifference != len(reference.Code)-1 {
+		t.Fatalf("expected truncated code not to match, got %+v", comparison)
+	}
+}
+
+func TestNewReferenceMarksImmutables(t *testing.T) {
+	artifact := Artifact{
+		Name:                "Test",
+		RuntimeCode:         make([]byte, 64),
+		ImmutableReferences: map[string][]ImmutableReference{"12": {{Start: 4, Length: 32}}},
+	}
+	reference, err := NewReference(artifact)
+	if err != nil {
+		t.Fatalf("failed to create reference: %v", err)
+	}
+	if reference.ImmutableBytes() != 32 || !reference.Immutable[4] || !reference.Immutable[35] || reference.Immutable[36] {
+		t.Fatalf("expected bytes 4 to 35 to be immutable, got %v", reference.Immutable)
+	}
+
+	artifact.ImmutableReferences["12"][0].Start = 40
+	if _, err := NewReference(artifact); err == nil {
+		t.Fatalf("expected an immutable outside of the code to be rejected")
+	}
+}
+
+func TestIdentifyRejectsEmptyCode(t *testing.T) {
+	if _, _, err := Identify(nil); err != ErrNoCode {
+		t.Fatalf("expected ErrNoCode, got: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// formatWeiFloat formats an amount of wei, as a float64, in whole tokens.
+func formatWeiFloat(amount float64) string {
+	return fmt.Sprintf("%.6f", amount/math.Pow10(gambit.Decimals))
+}
+
+func CreateBoostPlannerCommand() *cobra.Command {
+	var rpc, contractAddressRaw string
+	var days, spinsPerDay int
+	var gambitPrice float64
+	var timeout uint
+	var contractAddress, player common.Address
+
+	cmd := &cobra.Command{
+		Use:   "boost-planner <address>",
+		Short: "Plan when a player should spend GAMBIT on boosted spins",
+		Long: `Plan when a player should spend GAMBIT on boosted spins.
+
+A boosted spin burns 1 GAMBIT and uses the improved reels instead of the unmodified ones. The planner
+computes the exact expected value of boosted and unboosted spins at the current pot (from the reels'
+cumulative mass functions and prizes()), shows how the benefit of boosting grows with the jackpot
+(balance>>1), and compares the player's GAMBIT budget (their balance plus the streak rewards they will
+earn spinning --spins-per-day times a day for --days days) with the number of spins they plan to make.
+
+With --gambit-price (the value of 1 GAMBIT in native tokens), the planner also computes the pot above
+which boosting pays for the GAMBIT it burns.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if !common.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid player address: %s", args[0])
+			}
+			player = common.HexToAddress(args[0])
+
+			if days <= 0 {
+				return fmt.Errorf("--days must be positive")
+			}
+			if spinsPerDay < 0 {
+				return fmt.Errorf("--spins-per-day must not be negative")
+			}
+			if gambitPrice < 0 {
+				return fmt.Errorf("--gambit-price must not be negative")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, cancel := DegenGambit.NewChainContext(timeout)
+			defer cancel()
+			callOpts := &bind.CallOpts{Context: ctx}
+
+			balance, balanceErr := client.BalanceAt(ctx, contractAddress, nil)
+			if balanceErr != nil {
+				return balanceErr
+			}
+			costToSpin, costToSpinErr := contract.CostToSpin(callOpts)
+			if costToSpinErr != nil {
+				return costToSpinErr
+			}
+			costToRespin, costToRespinErr := contract.CostToRespin(callOpts)
+			if costToRespinErr != nil {
+				return costToRespinErr
+			}
+			prizes, prizesErr := contract.Prizes(callOpts)
+			if prizesErr != nil {
+				return prizesErr
+			}
+			if len(prizes.PrizesAmount) < gambit.NumPrizes {
+				return fmt.Errorf("prizes returned %d prizes, expected %d", len(prizes.PrizesAmount), gambit.NumPrizes)
+			}
+			unmodifiedReels, unmodifiedErr := gambit.ReadReels(callOpts, contract, false)
+			if unmodifiedErr != nil {
+				return unmodifiedErr
+			}
+			improvedReels, improvedErr := gambit.ReadReels(callOpts, contract, true)
+			if improvedErr != nil {
+				return improvedErr
+			}
+
+			gambitBalance, gambitBalanceErr := contract.BalanceOf(callOpts, player)
+			if gambitBalanceErr != nil {
+				return gambitBalanceErr
+			}
+			state, stateErr := gambit.ReadStreakState(callOpts, contract, player)
+			if stateErr != nil {
+				return stateErr
+			}
+			rewards, rewardsErr := gambit.ReadStreakRewards(callOpts, contract)
+			if rewardsErr != nil {
+				return rewardsErr
+			}
+
+			model := gambit.NewPotModel(costToSpin, costToRespin, gambit.ComputeOdds(unmodifiedReels), gambit.ComputeOdds(improvedReels))
+			pot, _ := new(big.Float).SetInt(balance).Float64()
+			majorGambitPrize, _ := new(big.Float).SetInt(prizes.PrizesAmount[0]).Float64()
+			minorGambitPrize, _ := new(big.Float).SetInt(prizes.PrizesAmount[1]).Float64()
+			comparison := model.CompareBoost(pot, majorGambitPrize, minorGambitPrize)
+
+			cmd.Printf("Pot: %s (jackpot: %s)\n\n", gambit.FormatUnits(balance, gambit.Decimals), gambit.FormatUnits(prizes.PrizesAmount[gambit.JackpotPrizeIndex], gambit.Decimals))
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Per spin\tUnboosted\tBoosted\tDifference")
+			fmt.Fprintf(w, "Chance of any prize\t%.4f%%\t%.4f%%\t%+.4f%%\n", 100*comparison.Unboosted.WinProbability, 100*comparison.Boosted.WinProbability, 100*(comparison.Boosted.WinProbability-comparison.Unboosted.WinProbability))
+			fmt.Fprintf(w, "Chance of a native prize\t%.4f%%\t%.4f%%\t%+.4f%%\n", 100*comparison.Unboosted.NativeWinProbability, 100*comparison.Boosted.NativeWinProbability, 100*(comparison.Boosted.NativeWinProbability-comparison.Unboosted.NativeWinProbability))
+			fmt.Fprintf(w, "Expected native won\t%s\t%s\t%s\n", formatWeiFloat(comparison.Unboosted.Native), formatWeiFloat(comparison.Boosted.Native), formatWeiFloat(comparison.NativeGain()))
+			fmt.Fprintf(w, "Expected GAMBIT won\t%s\t%s\t%s\n", formatWeiFloat(comparison.Unboosted.Gambit), formatWeiFloat(comparison.Boosted.Gambit), formatWeiFloat(comparison.Boosted.Gambit-comparison.Unboosted.Gambit))
+			if flushErr := w.Flush(); flushErr != nil {
+				return flushErr
+			}
+			cmd.Printf("\nA boost burns 1 GAMBIT (%s net of the extra GAMBIT prizes it is expected to win) and adds %s native tokens to the expected winnings of a spin.\n\n", formatWeiFloat(comparison.GambitCost()), formatWeiFloat(comparison.NativeGain()))
+
+			w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Pot\tJackpot\tNative gain per boost")
+			for _, multiple := range []float64{0.25, 0.5, 1, 2, 4} {
+				scaled := model.CompareBoost(pot*multiple, majorGambitPrize, minorGambitPrize)
+				jackpot, _ := model.PrizeAmount(gambit.JackpotPrizeIndex, pot*multiple+model.CostToSpin)
+				fmt.Fprintf(w, "%s\t%s\t%s\n", formatWeiFloat(pot*multiple), formatWeiFloat(jackpot), formatWeiFloat(scaled.NativeGain()))
+			}
+			if flushErr := w.Flush(); flushErr != nil {
+				return flushErr
+			}
+			cmd.Println()
+
+			today := gambit.Day(uint64(time.Now().Unix()))
+			spinOn := gambit.SpinEvery(today, 1)
+			if spinsPerDay == 0 {
+				spinOn = func(day uint64) bool { return false }
+			}
+			forecast := gambit.ForecastStreaks(state, rewards, today, days, spinOn)
+			budget := new(big.Int).Add(gambitBalance, forecast.Gambit)
+			boosts := new(big.Int).Quo(budget, gambit.BoostCost)
+			plannedSpins := int64(spinsPerDay) * int64(days)
+
+			cmd.Printf("GAMBIT balance: %s\n", gambit.FormatUnits(gambitBalance, gambit.Decimals))
+			cmd.Printf("Streak rewards over the next %d days: %s\n", days, gambit.FormatUnits(forecast.Gambit, gambit.Decimals))
+			cmd.Printf("Boosts available: %s for %d planned spins\n\n", boosts.String(), plannedSpins)
+
+			cmd.Println("Recommendation:")
+			switch {
+			case comparison.NativeGain() <= 0:
+				cmd.Println("  Boosting does not improve your expected native winnings at the current pot. Hold your GAMBIT.")
+			case gambitPrice > 0 && !comparison.Worthwhile(gambitPrice):
+				breakEven := model.BoostBreakEven(gambitPrice, majorGambitPrize, minorGambitPrize)
+				if math.IsInf(breakEven, 1) {
+					cmd.Printf("  At %g native tokens per GAMBIT, boosting never pays for the GAMBIT it burns. Hold your GAMBIT.\n", gambitPrice)
+				} else {
+					cmd.Printf("  At %g native tokens per GAMBIT, boosting pays for itself once the pot reaches %s. Hold your GAMBIT until then.\n", gambitPrice, formatWeiFloat(breakEven))
+				}
+			case boosts.IsInt64() && boosts.Int64() >= plannedSpins:
+				cmd.Println("  Boost every spin: your GAMBIT covers all of your planned spins, and boosting increases your expected winnings.")
+			case boosts.Sign() == 0:
+				cmd.Println("  You do not have enough GAMBIT to boost. Keep your streaks going to earn more.")
+			default:
+				cmd.Printf("  Your GAMBIT covers %s of your %d planned spins. The gain from boosting grows with the jackpot, so save boosts for spins when the pot is at or above its current level (%s) and spin unboosted when it is lower.\n", boosts.String(), plannedSpins, gambit.FormatUnits(balance, gambit.Decimals))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().IntVar(&days, "days", 7, "Number of days to plan for")
+	cmd.Flags().IntVar(&spinsPerDay, "spins-per-day", 1, "Number of spins the player plans to make each day")
+	cmd.Flags().Float64Var(&gambitPrice, "gambit-price", 0, "Value of 1 GAMBIT in native tokens (default: GAMBIT is only valued for boosting)")
+
+	return cmd
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
 	"github.com/PermissionlessGames/degen-casino/version"
 )
 
@@ -21,10 +22,62 @@ func CreateRootCommand() *cobra.Command {
 
 	completionCmd := CreateCompletionCommand(rootCmd)
 	versionCmd := CreateVersionCommand()
-	rootCmd.AddCommand(completionCmd, versionCmd)
+	deployCmd := CreateDeployCommand()
+	safeCmd := CreateSafeCommand()
+	rootCmd.AddCommand(completionCmd, versionCmd, deployCmd, safeCmd)
 
 	gambitCmd := DegenGambit.CreateDegenGambitCommand()
 	gambitCmd.Use = "gambit"
+	signer.WrapTransactionCommands(gambitCmd)
+
+	toolsGroup := &cobra.Group{
+		ID: "tools", Title: "Degen Casino tools",
+	}
+	gambitCmd.AddGroup(toolsGroup)
+
+	boostPlannerCmd := CreateBoostPlannerCommand()
+	boostPlannerCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(boostPlannerCmd)
+
+	fundCmd := CreateFundCommand()
+	fundCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(fundCmd)
+
+	keeperCmd := CreateKeeperCommand()
+	keeperCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(keeperCmd)
+
+	relayerCmd := CreateRelayerCommand()
+	relayerCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(relayerCmd)
+
+	indexCmd := CreateIndexCommand()
+	indexCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(indexCmd)
+
+	playerCmd := CreatePlayerCommand()
+	playerCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(playerCmd)
+
+	leaderboardCmd := CreateLeaderboardCommand()
+	leaderboardCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(leaderboardCmd)
+
+	pnlCmd := CreatePnlCommand()
+	pnlCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(pnlCmd)
+
+	tokenCmd := CreateTokenCommand()
+	tokenCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(tokenCmd)
+
+	streaksCmd := CreateStreaksCommand()
+	streaksCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(streaksCmd)
+
+	streakRemindersCmd := CreateStreakRemindersCommand()
+	streakRemindersCmd.GroupID = toolsGroup.ID
+	gambitCmd.AddCommand(streakRemindersCmd)
 
 	rootCmd.AddCommand(gambitCmd)
 
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/deployment"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+func CreateDeployCommand() *cobra.Command {
+	var manifestFile, rpc, outfile, salt string
+	var predict, fundExisting bool
+	var timeout uint
+	var manifest *deployment.Manifest
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploy the Degen Casino contracts from a manifest",
+		Long: `Deploy the Degen Casino contracts from a YAML manifest.
+
+The manifest gives the DegenGambit constructor parameters and, optionally, whether to deploy a
+BlockInspector, an amount (in wei) to fund the pot with, the chain ID to deploy to, and the number of
+confirmations to wait for:
+
+  name: game7-testnet
+  rpc: https://testnet-rpc.game7.io
+  chain-id: 13746
+  degen-gambit:
+    blocks-to-act: 50
+    cost-to-spin: 10
+    cost-to-respin: 7
+    version: "1"
+  block-inspector: true
+  fund: 1000000000000000000
+  confirmations: 2
+  artifact: deployments/game7-testnet.json
+
+After DegenGambit is deployed, the command reads BlocksToAct, CostToSpin, CostToRespin, and version()
+back from the contract and fails unless they match the manifest. The addresses, transaction hashes, and
+verified parameters are recorded in a JSON deployment artifact (--output, or the manifest's artifact,
+or stdout). The artifact is written even if a step fails, so that deployed contracts are not lost.
+
+If the manifest has a salt (or --salt is specified), the contracts are deployed with CREATE2 through the
+deterministic-deployment-proxy (0x4e59b44847b379578588920cA78FbF26c0B4956C), so the same manifest
+deploys them to the same addresses on every chain, regardless of the deploying account. A salt is
+either a 0x-prefixed hex string of at most 32 bytes or a label, which is hashed with keccak256. Contracts
+already deployed at their predicted addresses are verified but not deployed again, and the pot of an
+already deployed DegenGambit is not funded again unless --fund-existing is specified. Use --predict to
+print the addresses without accessing a chain.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if manifestFile == "" {
+				return fmt.Errorf("--file not specified")
+			}
+			var loadErr error
+			manifest, loadErr = deployment.LoadManifest(manifestFile)
+			if loadErr != nil {
+				return loadErr
+			}
+
+			if salt != "" {
+				manifest.Salt = salt
+				if err := manifest.Validate(); err != nil {
+					return fmt.Errorf("--salt: %v", err)
+				}
+			}
+
+			if predict {
+				return nil
+			}
+
+			if rpc == "" {
+				rpc = manifest.RPC
+			}
+			if outfile == "" {
+				outfile = manifest.Artifact
+			}
+
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if predict {
+				prediction, predictErr := deployment.Predict(manifest)
+				if predictErr != nil {
+					return predictErr
+				}
+				cmd.Printf("Salt: %s\n", prediction.Salt.Hex())
+				cmd.Printf("DegenGambit: %s\n", prediction.DegenGambit.Hex())
+				if manifest.BlockInspector {
+					cmd.Printf("BlockInspector: %s\n", prediction.BlockInspector.Hex())
+				}
+				return nil
+			}
+
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			deployer, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			chainIDCtx, cancelChainIDCtx := DegenGambit.NewChainContext(timeout)
+			defer cancelChainIDCtx()
+			chainID, chainIDErr := client.ChainID(chainIDCtx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(deployer, chainID), chainID, "")
+			if managerErr != nil {
+				return managerErr
+			}
+
+			d := deployment.NewDeployer(client, manager, manifest, cmd.OutOrStdout())
+			d.FundExisting = fundExisting
+			artifact, deployErr := d.Deploy(ctx)
+			if artifact == nil {
+				return deployErr
+			}
+
+			if outfile == "" {
+				if err := artifact.Write(cmd.OutOrStdout()); err != nil {
+					return err
+				}
+				return deployErr
+			}
+			if err := artifact.Save(outfile); err != nil {
+				if deployErr != nil {
+					return fmt.Errorf("%v (and failed to save the deployment artifact: %v)", deployErr, err)
+				}
+				return fmt.Errorf("failed to save the deployment artifact: %v", err)
+			}
+			cmd.Printf("Saved the deployment artifact to %s\n", outfile)
+			return deployErr
+		},
+	}
+
+	cmd.Flags().StringVarP(&manifestFile, "file", "f", "", "Path to the deployment manifest")
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use (default: the manifest's rpc)")
+	cmd.Flags().StringVarP(&outfile, "output", "o", "", "Path to write the deployment artifact to (default: the manifest's artifact, or stdout)")
+	cmd.Flags().StringVar(&salt, "salt", "", "Salt for deterministic deployment through the deterministic-deployment-proxy (default: the manifest's salt)")
+	cmd.Flags().BoolVar(&predict, "predict", false, "Print the addresses the contracts will be deployed to, without sending any transactions")
+	cmd.Flags().BoolVar(&fundExisting, "fund-existing", false, "Fund the pot of DegenGambit even if it was already deployed at its predicted address")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/funder"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// parseWei parses an optional big integer flag.
+func parseWei(flag, value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount := new(big.Int)
+	if _, ok := amount.SetString(value, 0); !ok {
+		return nil, fmt.Errorf("--%s is not a valid big integer", flag)
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flag)
+	}
+	return amount, nil
+}
+
+func CreateFundCommand() *cobra.Command {
+	var rpc, contractAddressRaw, amountRaw, targetRaw, jackpotFloorRaw, dailyLimitRaw, minAmountRaw, stateFile, spendFile string
+	var daemon, dryRun bool
+	var pollInterval time.Duration
+	var timeout uint
+	var contractAddress common.Address
+	var policy funder.Policy
+	var dailyLimit, minAmount *big.Int
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "fund",
+		Short: "Fund the pot of a DegenGambit contract",
+		Long: `Fund the pot (native token balance) of a DegenGambit contract.
+
+Exactly one funding policy must be chosen (all amounts are in wei):
+  --amount         send a fixed amount once
+  --target         top the contract's balance up to the given amount
+  --jackpot-floor  keep the jackpot (prizes()[6], which is half of the balance) at or above the given amount
+
+Funds are sent as plain value transfers from the treasury account (selected with the usual signer flags,
+e.g. --keyfile), which the contract accepts through its receive function.
+
+By default, the command applies the policy once and exits. With --daemon, it instead watches Award events
+and reapplies the --target or --jackpot-floor policy whenever a prize is paid out, sending at most
+--daily-limit per UTC day. Use --spend-file so that restarting the daemon does not reset the daily limit.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			policies := 0
+			for _, candidate := range []struct{ kind, flag, value string }{
+				{funder.PolicyAmount, "amount", amountRaw},
+				{funder.PolicyTarget, "target", targetRaw},
+				{funder.PolicyJackpotFloor, "jackpot-floor", jackpotFloorRaw},
+			} {
+				if candidate.value == "" {
+					continue
+				}
+				amount, parseErr := parseWei(candidate.flag, candidate.value)
+				if parseErr != nil {
+					return parseErr
+				}
+				policy = funder.Policy{Kind: candidate.kind, Amount: amount}
+				policies++
+			}
+			if policies != 1 {
+				return fmt.Errorf("exactly one of --amount, --target and --jackpot-floor must be specified")
+			}
+			if err := policy.Validate(); err != nil {
+				return err
+			}
+			if daemon && policy.Kind == funder.PolicyAmount {
+				return fmt.Errorf("--daemon requires --target or --jackpot-floor")
+			}
+
+			var parseErr error
+			if dailyLimit, parseErr = parseWei("daily-limit", dailyLimitRaw); parseErr != nil {
+				return parseErr
+			}
+			if minAmount, parseErr = parseWei("min-amount", minAmountRaw); parseErr != nil {
+				return parseErr
+			}
+
+			if dryRun {
+				if daemon {
+					return fmt.Errorf("--dry-run cannot be used with --daemon")
+				}
+				return nil
+			}
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			var treasury signer.Signer
+			var chainID *big.Int
+			if !dryRun {
+				var signerErr error
+				treasury, signerErr = signerFlags.Signer()
+				if signerErr != nil {
+					return signerErr
+				}
+
+				chainIDCtx, cancelChainIDCtx := DegenGambit.NewChainContext(timeout)
+				defer cancelChainIDCtx()
+				var chainIDErr error
+				chainID, chainIDErr = client.ChainID(chainIDCtx)
+				if chainIDErr != nil {
+					return chainIDErr
+				}
+			}
+
+			if daemon {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer stop()
+
+				manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(treasury, chainID), chainID, stateFile)
+				if managerErr != nil {
+					return managerErr
+				}
+
+				config := funder.Config{
+					Contract:     contractAddress,
+					Policy:       policy,
+					DailyLimit:   dailyLimit,
+					MinAmount:    minAmount,
+					PollInterval: pollInterval,
+					SpendFile:    spendFile,
+				}
+				f, funderErr := funder.New(client, manager, config, cmd.OutOrStdout())
+				if funderErr != nil {
+					return funderErr
+				}
+
+				cmd.Printf("Treasury %s maintaining %s policy (%s) on %s\n", treasury.Address().Hex(), policy.Kind, policy.Amount.String(), contractAddress.Hex())
+				runErr := f.Run(ctx)
+
+				cmd.Println()
+				f.Stats().Report(cmd.OutOrStdout())
+
+				if errors.Is(runErr, context.Canceled) {
+					return nil
+				}
+				return runErr
+			}
+
+			ctx, cancel := DegenGambit.NewChainContext(timeout)
+			defer cancel()
+
+			pot, potErr := funder.ReadPot(ctx, client, contract, contractAddress)
+			if potErr != nil {
+				return potErr
+			}
+			cmd.Printf("Balance: %s\nJackpot: %s\n", gambit.FormatUnits(pot.Balance, gambit.Decimals), gambit.FormatUnits(pot.Jackpot, gambit.Decimals))
+
+			amount := policy.Shortfall(pot.Balance, pot.Jackpot)
+			if amount.Sign() == 0 || (minAmount != nil && amount.Cmp(minAmount) < 0) {
+				cmd.Println("Nothing to send")
+				return nil
+			}
+			if dailyLimit != nil && amount.Cmp(dailyLimit) > 0 {
+				cmd.Printf("Limiting top-up of %s to --daily-limit\n", gambit.FormatUnits(amount, gambit.Decimals))
+				amount = new(big.Int).Set(dailyLimit)
+			}
+			if dryRun {
+				cmd.Printf("Would send %s (%s wei)\n", gambit.FormatUnits(amount, gambit.Decimals), amount.String())
+				return nil
+			}
+
+			opts := signer.NewTransactOpts(treasury, chainID)
+			opts.Context = ctx
+			tx, txErr := funder.Send(opts, contract, amount)
+			if txErr != nil {
+				return txErr
+			}
+			cmd.Printf("Sent %s (%s wei): %s\n", gambit.FormatUnits(amount, gambit.Decimals), amount.String(), tx.Hash().Hex())
+
+			receipt, receiptErr := bind.WaitMined(ctx, client, tx)
+			if receiptErr != nil {
+				return receiptErr
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+			}
+
+			newPot, newPotErr := funder.ReadPot(ctx, client, contract, contractAddress)
+			if newPotErr != nil {
+				return newPotErr
+			}
+			cmd.Printf("New balance: %s\nNew jackpot: %s\n", gambit.FormatUnits(newPot.Balance, gambit.Decimals), gambit.FormatUnits(newPot.Jackpot, gambit.Decimals))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&amountRaw, "amount", "", "Send this amount (in wei) once")
+	cmd.Flags().StringVar(&targetRaw, "target", "", "Top the contract's balance up to this amount (in wei)")
+	cmd.Flags().StringVar(&jackpotFloorRaw, "jackpot-floor", "", "Keep the jackpot at or above this amount (in wei)")
+	cmd.Flags().BoolVar(&daemon, "daemon", false, "Keep applying the policy whenever a prize is paid out")
+	cmd.Flags().StringVar(&dailyLimitRaw, "daily-limit", "", "Maximum amount (in wei) to send per UTC day (default: no limit)")
+	cmd.Flags().StringVar(&minAmountRaw, "min-amount", "", "Do not send top-ups smaller than this amount (in wei)")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", funder.DefaultPollInterval, "Interval at which to check for new awards (with --daemon)")
+	cmd.Flags().StringVar(&stateFile, "state-file", "", "Path to a file in which to persist the daemon's transaction state across restarts (optional)")
+	cmd.Flags().StringVar(&spendFile, "spend-file", "", "Path to a file in which to persist the daemon's daily spending across restarts (optional)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the amount that would be sent instead of sending it")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/index"
+)
+
+func CreateIndexCommand() *cobra.Command {
+	var rpc, contractAddressRaw, indexFile string
+	var fromBlock, toBlock, confirmations uint64
+	var rebuild bool
+	var contractAddress common.Address
+
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Build or update a local index of DegenGambit events",
+		Long: `Build or update a local index of DegenGambit events.
+
+The index records every Spin, Award, DailyStreak and WeeklyStreak event emitted by the contract in a
+JSON file (--index-file), along with which prize each award paid, who paid for each spin, and every
+GAMBIT mint and burn. Running the command again picks up where the previous run left off. Use
+--rebuild to re-index from the start, e.g. to fill in data that was not recorded by older versions of
+the index. Other commands (e.g. player, leaderboard) use the index for historical statistics which
+cannot be read from the contract's state.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if indexFile == "" {
+				return fmt.Errorf("--index-file not specified")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			idx, openErr := index.Open(indexFile, contractAddress, fromBlock)
+			if openErr != nil {
+				return openErr
+			}
+			if rebuild {
+				idx = index.New(contractAddress, idx.StartBlock)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			if toBlock == 0 {
+				head, headErr := client.BlockNumber(ctx)
+				if headErr != nil {
+					return headErr
+				}
+				if head < confirmations {
+					return nil
+				}
+				toBlock = head - confirmations
+			}
+
+			syncErr := idx.Sync(ctx, client, toBlock, cmd.OutOrStdout())
+			if syncErr == nil {
+				syncErr = idx.ResolvePrizes(ctx, client, cmd.OutOrStdout())
+			}
+			// Save whatever was indexed, even if the sync was interrupted.
+			if saveErr := idx.Save(indexFile); saveErr != nil {
+				return saveErr
+			}
+			if syncErr != nil && !errors.Is(syncErr, context.Canceled) {
+				return syncErr
+			}
+
+			if idx.NextBlock <= idx.StartBlock {
+				cmd.Println("Index is empty")
+				return nil
+			}
+			cmd.Printf("Index contains %d spins, %d awards, %d streaks, %d mints and %d burns up to block %d\n", len(idx.Spins), len(idx.Awards), len(idx.Streaks), len(idx.Mints), len(idx.Burns), idx.NextBlock-1)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().StringVar(&indexFile, "index-file", "", "Path to the index file (created if it does not exist)")
+	cmd.Flags().Uint64Var(&fromBlock, "from-block", 0, "Block from which to start indexing (only used when creating a new index; ideally the contract's deployment block)")
+	cmd.Flags().Uint64Var(&toBlock, "to-block", 0, "Block up to which to index (default: the current block minus --confirmations)")
+	cmd.Flags().BoolVar(&rebuild, "rebuild", false, "Discard the indexed events and re-index from the index's start block")
+	cmd.Flags().Uint64Var(&confirmations, "confirmations", 0, "Number of recent blocks to leave unindexed in case they are reorganized")
+
+	return cmd
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/keeper"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+func CreateKeeperCommand() *cobra.Command {
+	var rpc, contractAddressRaw, playersRaw, playersFile, stateFile string
+	var fromBlock uint64
+	var pollInterval time.Duration
+	var timeout uint
+	var contractAddress common.Address
+	var players []common.Address
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "keeper",
+		Short: "Accept winning spins on behalf of opted-in players before their deadline",
+		Long: `Accept winning spins on behalf of opted-in players before their deadline.
+
+The keeper watches Spin events for the given players. Whenever a player's spin has a prize (according
+to hasPrize), the keeper calls acceptFor(player) before LastSpinBlock + BlocksToAct, paying for gas from
+its own account (selected with the usual signer flags, e.g. --keyfile). Spins which the player accepts
+themselves are skipped. When the keeper is stopped (Ctrl+C), it prints a summary of the claims it made
+and the gas it spent.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			var playerAddresses []string
+			if playersRaw != "" {
+				playerAddresses = append(playerAddresses, strings.Split(playersRaw, ",")...)
+			}
+			if playersFile != "" {
+				contents, readErr := os.ReadFile(playersFile)
+				if readErr != nil {
+					return readErr
+				}
+				playerAddresses = append(playerAddresses, strings.Fields(string(contents))...)
+			}
+			for _, playerAddress := range playerAddresses {
+				playerAddress = strings.TrimSpace(playerAddress)
+				if playerAddress == "" {
+					continue
+				}
+				if !common.IsHexAddress(playerAddress) {
+					return fmt.Errorf("invalid player address: %s", playerAddress)
+				}
+				players = append(players, common.HexToAddress(playerAddress))
+			}
+			if len(players) == 0 {
+				return fmt.Errorf("no players specified (use --players or --players-file)")
+			}
+
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			keeperSigner, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			chainIDCtx, cancelChainIDCtx := DegenGambit.NewChainContext(timeout)
+			defer cancelChainIDCtx()
+			chainID, chainIDErr := client.ChainID(chainIDCtx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(keeperSigner, chainID), chainID, stateFile)
+			if managerErr != nil {
+				return managerErr
+			}
+
+			config := keeper.Config{
+				Contract:     contractAddress,
+				Players:      players,
+				FromBlock:    fromBlock,
+				PollInterval: pollInterval,
+			}
+			k, keeperErr := keeper.New(client, manager, config, cmd.OutOrStdout())
+			if keeperErr != nil {
+				return keeperErr
+			}
+
+			cmd.Printf("Keeper %s watching %d players on %s\n", keeperSigner.Address().Hex(), len(players), contractAddress.Hex())
+			runErr := k.Run(ctx)
+
+			cmd.Println()
+			k.Stats().Report(cmd.OutOrStdout())
+
+			if errors.Is(runErr, context.Canceled) {
+				return nil
+			}
+			return runErr
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&playersRaw, "players", "", "Comma-separated addresses of the players who opted in")
+	cmd.Flags().StringVar(&playersFile, "players-file", "", "Path to a file containing the addresses of the players who opted in (one per line)")
+	cmd.Flags().Uint64Var(&fromBlock, "from-block", 0, "Block from which to look for spins (default: BlocksToAct blocks before the current block)")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", keeper.DefaultPollInterval, "Interval at which to check for new spins")
+	cmd.Flags().StringVar(&stateFile, "state-file", "", "Path to a file in which to persist the keeper's transaction state across restarts (optional)")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/index"
+)
+
+// parseTime parses a date (2006-01-02, in UTC), an RFC3339 time, or a Unix timestamp.
+func parseTime(value string) (uint64, error) {
+	if timestamp, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return timestamp, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return uint64(t.Unix()), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected YYYY-MM-DD, RFC3339 or a Unix timestamp)", value)
+	}
+	return uint64(t.Unix()), nil
+}
+
+// leaderboardWindow returns the time window for a leaderboard period ("all", "day" or "week", where
+// days and weeks are the UTC streak days and weeks containing now), narrowed by since and until if they
+// are not empty.
+func leaderboardWindow(period, since, until string, now time.Time) (uint64, uint64, error) {
+	var start, end uint64
+	today := gambit.Day(uint64(now.Unix()))
+	switch period {
+	case "", "all":
+	case "day":
+		start = uint64(gambit.DayStart(today).Unix())
+		end = uint64(gambit.DayStart(today + 1).Unix())
+	case "week":
+		week := gambit.Week(today)
+		start = uint64(gambit.WeekStart(week).Unix())
+		end = uint64(gambit.WeekStart(week + 1).Unix())
+	default:
+		return 0, 0, fmt.Errorf("invalid period %q (expected all, day or week)", period)
+	}
+
+	if since != "" {
+		sinceTimestamp, err := parseTime(since)
+		if err != nil {
+			return 0, 0, err
+		}
+		if sinceTimestamp > start {
+			start = sinceTimestamp
+		}
+	}
+	if until != "" {
+		untilTimestamp, err := parseTime(until)
+		if err != nil {
+			return 0, 0, err
+		}
+		if end == 0 || untilTimestamp < end {
+			end = untilTimestamp
+		}
+	}
+	return start, end, nil
+}
+
+func formatLeaderboardValue(metric string, value *big.Int) string {
+	switch metric {
+	case index.MetricNativeWon, index.MetricLargestAward:
+		return gambit.FormatUnits(value, gambit.Decimals) + " native"
+	case index.MetricStreakGambit:
+		return gambit.FormatUnits(value, gambit.Decimals) + " GAMBIT"
+	default:
+		return value.String()
+	}
+}
+
+func CreateLeaderboardCommand() *cobra.Command {
+	var indexFile, metric, period, since, until, serve string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "leaderboard",
+		Short: "Rank players using the event index",
+		Long: `Rank players using the event index (see the index command).
+
+Metrics (--by):
+  native-won     total native tokens won
+  largest-award  largest single native token prize
+  jackpots       number of jackpots (prize 6) won
+  daily-streak   longest daily streak
+  streak-gambit  GAMBIT earned from daily and weekly streak rewards
+  spins          number of spins
+
+--period restricts the leaderboard to the current UTC day or streak week, and --since/--until to an
+arbitrary window. With --serve, the command instead serves leaderboards over HTTP at
+GET /leaderboard?by=<metric>&period=<period>&since=<time>&until=<time>&limit=<n>, reading the index file
+afresh for every request so that it reflects the latest sync.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if indexFile == "" {
+				return fmt.Errorf("--index-file not specified")
+			}
+			if limit < 0 {
+				return fmt.Errorf("--limit must not be negative")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idx, loadErr := index.Load(indexFile)
+			if loadErr != nil {
+				return loadErr
+			}
+
+			if serve != "" {
+				return serveLeaderboards(cmd, serve, indexFile)
+			}
+
+			start, end, windowErr := leaderboardWindow(period, since, until, time.Now())
+			if windowErr != nil {
+				return windowErr
+			}
+
+			entries, leaderboardErr := idx.Leaderboard(index.LeaderboardQuery{Metric: metric, Since: start, Until: end, Limit: limit})
+			if leaderboardErr != nil {
+				return leaderboardErr
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Rank\tPlayer\t%s\n", metric)
+			for _, entry := range entries {
+				fmt.Fprintf(w, "%d\t%s\t%s\n", entry.Rank, entry.Player.Hex(), formatLeaderboardValue(metric, entry.Value))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&indexFile, "index-file", "", "Path to the index file (see the index command)")
+	cmd.Flags().StringVar(&metric, "by", index.MetricNativeWon, fmt.Sprintf("Metric to rank players by (%s)", strings.Join(index.Metrics, ", ")))
+	cmd.Flags().StringVar(&period, "period", "all", "Period to rank players over: all, day (current UTC day) or week (current streak week)")
+	cmd.Flags().StringVar(&since, "since", "", "Only count events at or after this time (YYYY-MM-DD, RFC3339 or Unix timestamp)")
+	cmd.Flags().StringVar(&until, "until", "", "Only count events before this time (YYYY-MM-DD, RFC3339 or Unix timestamp)")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of players to show (0 means no limit)")
+	cmd.Flags().StringVar(&serve, "serve", "", "Serve leaderboards over HTTP on this address (e.g. 127.0.0.1:8080) instead of printing one")
+
+	return cmd
+}
+
+func serveLeaderboards(cmd *cobra.Command, addr, indexFile string) error {
+	writeJSON := func(w http.ResponseWriter, status int, value interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(value)
+	}
+	type errorResponse struct {
+		Error string `json:"error"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /leaderboard", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+
+		metric := query.Get("by")
+		if metric == "" {
+			metric = index.MetricNativeWon
+		}
+		limit := 10
+		if rawLimit := query.Get("limit"); rawLimit != "" {
+			parsedLimit, parseErr := strconv.Atoi(rawLimit)
+			if parseErr != nil || parsedLimit < 0 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid limit"})
+				return
+			}
+			limit = parsedLimit
+		}
+		start, end, windowErr := leaderboardWindow(query.Get("period"), query.Get("since"), query.Get("until"), time.Now())
+		if windowErr != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: windowErr.Error()})
+			return
+		}
+
+		idx, loadErr := index.Load(indexFile)
+		if loadErr != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to load index"})
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", loadErr)
+			return
+		}
+
+		entries, leaderboardErr := idx.Leaderboard(index.LeaderboardQuery{Metric: metric, Since: start, Until: end, Limit: limit})
+		if leaderboardErr != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: leaderboardErr.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, entries)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	cmd.Printf("Serving leaderboards from %s on %s\n", indexFile, addr)
+	serveErr := server.ListenAndServe()
+	if errors.Is(serveErr, http.ErrServerClosed) {
+		return nil
+	}
+	return serveErr
+}
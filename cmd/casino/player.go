@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/index"
+)
+
+func CreatePlayerCommand() *cobra.Command {
+	var rpc, contractAddressRaw, indexFile string
+	var timeout uint
+	var contractAddress, player common.Address
+
+	cmd := &cobra.Command{
+		Use:   "player <address>",
+		Short: "Show everything about a player in one view",
+		Long: `Show everything about a player in one view.
+
+This shows the player's pending spin (the block they spun at, how many blocks they have left to accept,
+whether they have a prize and the decoded outcome), the cost of their next spin, their GAMBIT balance,
+and their daily and weekly streaks (including whether spinning now will extend them). If --index-file is
+given (see the index command), lifetime statistics are shown as well.`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if !common.IsHexAddress(args[0]) {
+				return fmt.Errorf("player is not a valid Ethereum address")
+			}
+			player = common.HexToAddress(args[0])
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, cancel := DegenGambit.NewChainContext(timeout)
+			defer cancel()
+
+			// All reads are made against the same block so that the view is consistent.
+			header, headerErr := client.HeaderByNumber(ctx, nil)
+			if headerErr != nil {
+				return headerErr
+			}
+			head := header.Number.Uint64()
+			callOpts := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+
+			lastSpinBlockRaw, lastSpinBlockErr := contract.LastSpinBlock(callOpts, player)
+			if lastSpinBlockErr != nil {
+				return lastSpinBlockErr
+			}
+			lastSpinBlock := lastSpinBlockRaw.Uint64()
+
+			lastSpinBoosted, lastSpinBoostedErr := contract.LastSpinBoosted(callOpts, player)
+			if lastSpinBoostedErr != nil {
+				return lastSpinBoostedErr
+			}
+
+			blocksToActRaw, blocksToActErr := contract.BlocksToAct(callOpts)
+			if blocksToActErr != nil {
+				return blocksToActErr
+			}
+			blocksToAct := blocksToActRaw.Uint64()
+
+			spinCost, spinCostErr := contract.SpinCost(callOpts, player)
+			if spinCostErr != nil {
+				return spinCostErr
+			}
+
+			balance, balanceErr := contract.BalanceOf(callOpts, player)
+			if balanceErr != nil {
+				return balanceErr
+			}
+
+			streaks, streaksErr := gambit.ReadStreakState(callOpts, contract, player)
+			if streaksErr != nil {
+				return streaksErr
+			}
+
+			cmd.Printf("Player: %s\n", player.Hex())
+			cmd.Printf("Contract: %s (block %d, %s)\n", contractAddress.Hex(), head, formatTimestamp(header.Time))
+
+			cmd.Println()
+			cmd.Println("Pending spin:")
+			deadline := lastSpinBlock + blocksToAct
+			switch {
+			case lastSpinBlock == 0:
+				cmd.Println("  None")
+			case head > deadline:
+				cmd.Printf("  Spun at block %d (boosted: %t), expired at block %d\n", lastSpinBlock, lastSpinBoosted, deadline)
+			default:
+				cmd.Printf("  Spun at block %d (boosted: %t), %d blocks left to accept or respin (deadline: block %d)\n", lastSpinBlock, lastSpinBoosted, deadline-head, deadline)
+
+				hasPrize, hasPrizeErr := contract.HasPrize(callOpts, player)
+				if hasPrizeErr != nil {
+					return hasPrizeErr
+				}
+				cmd.Printf("  Has prize: %t\n", hasPrize)
+
+				if head <= lastSpinBlock {
+					cmd.Println("  Outcome: not yet known (waiting for the next block)")
+				} else {
+					outcome, outcomeErr := contract.InspectOutcome(callOpts, player)
+					if outcomeErr != nil {
+						return fmt.Errorf("failed to inspect outcome: %v", outcomeErr)
+					}
+					left, center, right := outcome.Left.Uint64(), outcome.Center.Uint64(), outcome.Right.Uint64()
+					cmd.Printf("  Outcome: %s | %s | %s (%d, %d, %d)\n", gambit.SymbolName(left), gambit.SymbolName(center), gambit.SymbolName(right), left, center, right)
+					if prizeIndex, ok := gambit.PrizeIndex(left, center, right); ok && outcome.Prize.Sign() > 0 {
+						cmd.Printf("  Prize: %s (%s)\n", formatPrize(outcome.Prize, outcome.TypeOfPrize.Uint64()), gambit.PrizeDescriptions[prizeIndex])
+					} else {
+						cmd.Println("  Prize: none")
+					}
+				}
+			}
+
+			cmd.Println()
+			cmd.Printf("Next spin cost: %s (%s wei)\n", gambit.FormatUnits(spinCost, gambit.Decimals), spinCost.String())
+			cmd.Printf("GAMBIT balance: %s GAMBIT\n", gambit.FormatUnits(balance, gambit.Decimals))
+
+			currentDay := gambit.Day(header.Time)
+			currentWeek := gambit.Week(currentDay)
+			cmd.Println()
+			cmd.Printf("Streaks (UTC day %d, week %d):\n", currentDay, currentWeek)
+			cmd.Printf("  Daily: %s\n", describeStreak(streaks.CurrentDailyStreakLength, streaks.LastStreakDay, currentDay, "day", "today", "tomorrow"))
+			cmd.Printf("  Weekly: %s\n", describeStreak(streaks.CurrentWeeklyStreakLength, streaks.LastStreakWeek, currentWeek, "week", "this week", "next week"))
+
+			if indexFile != "" {
+				idx, loadErr := index.Load(indexFile)
+				if loadErr != nil {
+					return loadErr
+				}
+				if idx.Contract != contractAddress {
+					return fmt.Errorf("index in %s is for contract %s, not %s", indexFile, idx.Contract.Hex(), contractAddress.Hex())
+				}
+
+				stats := idx.PlayerStats(player)
+				cmd.Println()
+				if idx.NextBlock > idx.StartBlock {
+					cmd.Printf("Lifetime (indexed blocks %d-%d):\n", idx.StartBlock, idx.NextBlock-1)
+				} else {
+					cmd.Println("Lifetime (index is empty):")
+				}
+				cmd.Printf("  Spins: %d (%d boosted)\n", stats.Spins, stats.BoostedSpins)
+				cmd.Printf("  Accepts: %d (%d native prizes, %d GAMBIT prizes)\n", stats.Accepts, stats.NativeWins, stats.GambitWins)
+				cmd.Printf("  Native won: %s (largest prize: %s)\n", gambit.FormatUnits(stats.NativeWon, gambit.Decimals), gambit.FormatUnits(stats.LargestNativeWin, gambit.Decimals))
+				cmd.Printf("  GAMBIT won: %s GAMBIT\n", gambit.FormatUnits(stats.GambitWon, gambit.Decimals))
+				cmd.Printf("  Streaks extended: %d daily, %d weekly\n", stats.DailyStreaks, stats.WeeklyStreaks)
+				if stats.Spins > 0 {
+					cmd.Printf("  First spin: %s\n  Last spin: %s\n", formatTimestamp(stats.FirstSpin), formatTimestamp(stats.LastSpin))
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&indexFile, "index-file", "", "Path to an index file (see the index command) to show lifetime statistics from (optional)")
+
+	return cmd
+}
+
+func describeStreak(length, last, current uint64, period, thisPeriod, nextPeriod string) string {
+	effective := gambit.EffectiveStreakLength(length, last, current)
+	switch gambit.StreakStatusAt(last, current) {
+	case gambit.StreakExtends:
+		return fmt.Sprintf("length %d, spin %s to extend it and earn the streak reward", effective, thisPeriod)
+	case gambit.StreakCurrent:
+		return fmt.Sprintf("length %d, already spun %s (next chance to extend it: %s)", effective, thisPeriod, nextPeriod)
+	default:
+		if last == 0 {
+			return fmt.Sprintf("no streak, spin %s to start one", thisPeriod)
+		}
+		return fmt.Sprintf("broken (last spun in %s %d), spin %s to start a new one", period, last, thisPeriod)
+	}
+}
+
+func formatPrize(prize *big.Int, typeOfPrize uint64) string {
+	if typeOfPrize == gambit.PrizeTypeGambit {
+		return fmt.Sprintf("%s GAMBIT", gambit.FormatUnits(prize, gambit.Decimals))
+	}
+	return fmt.Sprintf("%s native (%s wei)", gambit.FormatUnits(prize, gambit.Decimals), prize.String())
+}
+
+func formatTimestamp(timestamp uint64) string {
+	return time.Unix(int64(timestamp), 0).UTC().Format("2006-01-02 15:04:05 UTC")
+}
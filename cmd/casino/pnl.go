@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/index"
+)
+
+// writeStatementsCSV writes statements as CSV. Amounts are written in wei so that the output can be
+// processed without loss of precision.
+func writeStatementsCSV(out io.Writer, statements []index.Statement) error {
+	w := csv.NewWriter(out)
+	header := []string{
+		"period", "period_start", "player", "spins", "unknown_value_spins", "spin_value_wei", "native_paid_wei",
+		"native_won_wei", "native_net_wei", "gambit_prizes_wei", "gambit_streaks_wei", "gambit_burned_wei", "gambit_net_wei",
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, s := range statements {
+		record := []string{
+			s.Period,
+			strconv.FormatUint(s.PeriodStart, 10),
+			s.Player.Hex(),
+			strconv.Itoa(s.Spins),
+			strconv.Itoa(s.UnknownValueSpins),
+			s.SpinValue.String(),
+			s.NativePaid.String(),
+			s.NativeWon.String(),
+			s.NativeNet().String(),
+			s.GambitPrizes.String(),
+			s.GambitStreaks.String(),
+			s.GambitBurned.String(),
+			s.GambitNet().String(),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func CreatePnlCommand() *cobra.Command {
+	var indexFile, playersRaw, period, since, until, csvFile string
+	var players []common.Address
+	var start, end uint64
+
+	cmd := &cobra.Command{
+		Use:   "pnl",
+		Short: "Per-player profit and loss statements from the event index",
+		Long: `Per-player profit and loss statements from the event index (see the index command).
+
+For each player and period, the statement shows:
+  spins        number of spins made for the player, and the native tokens sent with them
+  paid         native tokens the player sent with spin transactions (including spinFor calls on behalf
+               of other players)
+  won          native token prizes received
+  net          won - paid
+  GAMBIT       GAMBIT minted as prizes and as streak rewards, GAMBIT burned to boost spins, and the net
+
+The value of spins made through intermediary contracts (e.g. multicalls or relayers) cannot be
+attributed and is reported as unknown. Indexes built before spin payers were recorded must be rebuilt
+(index --rebuild) for spin values to be counted.
+
+--period breaks statements down by UTC day, streak week (starting on Thursday), or UTC month. With
+--csv, statements are written as CSV (amounts in wei) to the given file, or to stdout if it is "-".`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if indexFile == "" {
+				return fmt.Errorf("--index-file not specified")
+			}
+
+			if playersRaw != "" {
+				for _, address := range strings.Split(playersRaw, ",") {
+					address = strings.TrimSpace(address)
+					if !common.IsHexAddress(address) {
+						return fmt.Errorf("invalid player address: %s", address)
+					}
+					players = append(players, common.HexToAddress(address))
+				}
+			}
+
+			if since != "" {
+				sinceTimestamp, parseErr := parseTime(since)
+				if parseErr != nil {
+					return parseErr
+				}
+				start = sinceTimestamp
+			}
+			if until != "" {
+				untilTimestamp, parseErr := parseTime(until)
+				if parseErr != nil {
+					return parseErr
+				}
+				end = untilTimestamp
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idx, loadErr := index.Load(indexFile)
+			if loadErr != nil {
+				return loadErr
+			}
+
+			statements, statementsErr := idx.Statements(index.StatementQuery{Players: players, Period: period, Since: start, Until: end})
+			if statementsErr != nil {
+				return statementsErr
+			}
+
+			if csvFile == "-" {
+				return writeStatementsCSV(cmd.OutOrStdout(), statements)
+			} else if csvFile != "" {
+				file, createErr := os.Create(csvFile)
+				if createErr != nil {
+					return createErr
+				}
+				if writeErr := writeStatementsCSV(file, statements); writeErr != nil {
+					file.Close()
+					return writeErr
+				}
+				if closeErr := file.Close(); closeErr != nil {
+					return closeErr
+				}
+				cmd.Printf("Wrote %d statements to %s\n", len(statements), csvFile)
+				return nil
+			}
+
+			if len(statements) == 0 {
+				cmd.Println("No activity found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Period\tPlayer\tSpins\tSpin value\tPaid\tWon\tNet\tGAMBIT prizes\tGAMBIT streaks\tGAMBIT burned\tGAMBIT net")
+			for _, s := range statements {
+				spinValue := gambit.FormatUnits(s.SpinValue, gambit.Decimals)
+				if s.UnknownValueSpins > 0 {
+					spinValue += fmt.Sprintf(" (+%d unknown)", s.UnknownValueSpins)
+				}
+				fmt.Fprintf(
+					w,
+					"%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					s.Period,
+					s.Player.Hex(),
+					s.Spins,
+					spinValue,
+					gambit.FormatUnits(s.NativePaid, gambit.Decimals),
+					gambit.FormatUnits(s.NativeWon, gambit.Decimals),
+					gambit.FormatUnits(s.NativeNet(), gambit.Decimals),
+					gambit.FormatUnits(s.GambitPrizes, gambit.Decimals),
+					gambit.FormatUnits(s.GambitStreaks, gambit.Decimals),
+					gambit.FormatUnits(s.GambitBurned, gambit.Decimals),
+					gambit.FormatUnits(s.GambitNet(), gambit.Decimals),
+				)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&indexFile, "index-file", "", "Path to the index file (see the index command)")
+	cmd.Flags().StringVar(&playersRaw, "players", "", "Comma-separated addresses of the players to report on (default: every player in the index)")
+	cmd.Flags().StringVar(&period, "period", index.PeriodAll, fmt.Sprintf("Period to break statements down by (%s)", strings.Join(index.Periods, ", ")))
+	cmd.Flags().StringVar(&since, "since", "", "Only count events at or after this time (YYYY-MM-DD, RFC3339 or Unix timestamp)")
+	cmd.Flags().StringVar(&until, "until", "", "Only count events before this time (YYYY-MM-DD, RFC3339 or Unix timestamp)")
+	cmd.Flags().StringVar(&csvFile, "csv", "", "Write statements as CSV to this file (\"-\" for stdout) instead of printing a table")
+
+	return cmd
+}
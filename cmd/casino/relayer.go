@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/relayer"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+func CreateRelayerCommand() *cobra.Command {
+	var rpc, contractAddressRaw, listen, webhookURL, dailyBudgetRaw, stateFile, playerStateFile string
+	var rateLimit int
+	var rateLimitWindow, waitTimeout time.Duration
+	var allowBoost bool
+	var timeout uint
+	var contractAddress common.Address
+	var dailyBudget *big.Int
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "relayer",
+		Short: "Run a gasless play relayer which submits signed player intents",
+		Long: `Run a gasless play relayer which submits signed player intents.
+
+Players sign EIP-712 intents (SpinIntent or AcceptIntent) and POST them to /spin or /accept. The relayer
+checks the signature, the intent's nonce and deadline, and the player's rate limit and daily budget. It
+then calls spinFor(player, player, boost) - paying the spin cost - or acceptFor(player) from its own
+account (selected with the usual signer flags, e.g. --keyfile), so that players do not need any native
+tokens to play.
+
+GET /domain returns the EIP-712 domain and types that intents must be signed with. The result of each
+relayed intent is returned in the HTTP response and, if --webhook is set, POSTed to the webhook.
+
+Each player's last intent nonce, rate limit window, and daily spend are persisted to --player-state-file
+(by default, --state-file with ".players" appended). Without either flag they are only kept in memory,
+and any unexpired intent can be relayed again after the relayer restarts.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if rateLimit < 0 {
+				return fmt.Errorf("--rate-limit must not be negative")
+			}
+			if rateLimit > 0 && rateLimitWindow <= 0 {
+				return fmt.Errorf("--rate-limit-window must be positive")
+			}
+
+			if dailyBudgetRaw != "" {
+				dailyBudget = new(big.Int)
+				_, ok := dailyBudget.SetString(dailyBudgetRaw, 0)
+				if !ok {
+					return fmt.Errorf("--daily-budget is not a valid big integer")
+				}
+			}
+
+			if playerStateFile == "" && stateFile != "" {
+				playerStateFile = stateFile + ".players"
+			}
+
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			relayerSigner, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			chainIDCtx, cancelChainIDCtx := DegenGambit.NewChainContext(timeout)
+			defer cancelChainIDCtx()
+			chainID, chainIDErr := client.ChainID(chainIDCtx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(relayerSigner, chainID), chainID, stateFile)
+			if managerErr != nil {
+				return managerErr
+			}
+			manager.Log = cmd.ErrOrStderr()
+			// Keep rebroadcasting stuck transactions in the background.
+			go manager.Run(ctx)
+
+			config := relayer.Config{
+				Contract:        contractAddress,
+				RateLimit:       rateLimit,
+				RateLimitWindow: rateLimitWindow,
+				DailyBudget:     dailyBudget,
+				AllowBoost:      allowBoost,
+				StatePath:       playerStateFile,
+			}
+			r, relayerErr := relayer.New(client, manager, chainID, config)
+			if relayerErr != nil {
+				return relayerErr
+			}
+			r.Log = cmd.ErrOrStderr()
+
+			server := relayer.NewServer(r, webhookURL, cmd.ErrOrStderr())
+			server.WaitTimeout = waitTimeout
+
+			httpServer := &http.Server{Addr: listen, Handler: server.Handler()}
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancelShutdown()
+				httpServer.Shutdown(shutdownCtx)
+			}()
+
+			cmd.Printf("Relayer %s listening on %s for intents on %s\n", relayerSigner.Address().Hex(), listen, contractAddress.Hex())
+			serveErr := httpServer.ListenAndServe()
+			if errors.Is(serveErr, http.ErrServerClosed) {
+				return nil
+			}
+			return serveErr
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8080", "Address on which the relayer serves HTTP requests")
+	cmd.Flags().StringVar(&webhookURL, "webhook", "", "URL to which the result of each relayed intent is POSTed (optional)")
+	cmd.Flags().IntVar(&rateLimit, "rate-limit", 60, "Maximum number of intents a player may submit per --rate-limit-window (0 means no limit)")
+	cmd.Flags().DurationVar(&rateLimitWindow, "rate-limit-window", time.Hour, "Window over which the rate limit applies")
+	cmd.Flags().StringVar(&dailyBudgetRaw, "daily-budget", "", "Maximum amount (in wei) spent on spin costs and gas for each player per UTC day (default: no limit)")
+	cmd.Flags().BoolVar(&allowBoost, "allow-boost", false, "Relay boosted spins (these burn GAMBIT from the relayer's account)")
+	cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", relayer.DefaultWaitTimeout, "Amount of time to wait for a relayed transaction to be mined before responding")
+	cmd.Flags().StringVar(&stateFile, "state-file", "", "Path to a file in which to persist the relayer's transaction state across restarts (optional)")
+	cmd.Flags().StringVar(&playerStateFile, "player-state-file", "", "Path to a file in which to persist players' intent nonces, rate limits, and daily spend across restarts (default: --state-file with \".players\" appended)")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/reminder"
+)
+
+// readReminderPlayers reads registered players from a file. Each line contains a player address,
+// optionally followed by a comma and their email address. Blank lines and lines starting with # are
+// ignored.
+func readReminderPlayers(path string) ([]reminder.Player, error) {
+	file, openErr := os.Open(path)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+
+	var players []reminder.Player
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, ",", 2)
+		address := strings.TrimSpace(fields[0])
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("%s:%d: invalid player address: %s", path, lineNumber, address)
+		}
+		player := reminder.Player{Address: common.HexToAddress(address)}
+		if len(fields) == 2 {
+			player.Email = strings.TrimSpace(fields[1])
+		}
+		players = append(players, player)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+func CreateStreakRemindersCommand() *cobra.Command {
+	var rpc, contractAddressRaw, playersRaw, playersFile, webhookURL, smtpAddr, smtpFrom, smtpUsername, smtpPasswordEnv string
+	var dailyWarnBefore, weeklyWarnBefore, pollInterval time.Duration
+	var dryRun bool
+	var contractAddress common.Address
+	var players []reminder.Player
+
+	cmd := &cobra.Command{
+		Use:   "streak-reminders",
+		Short: "Notify registered players before their streaks break",
+		Long: `Notify registered players before their streaks break.
+
+The daemon checks each registered player's LastStreakDay and LastStreakWeek every --poll-interval. When
+the UTC day (or week) in which a player must spin to keep their daily (or weekly) streak is about to end
+(--daily-warn-before, --weekly-warn-before), it sends them a warning. When a streak breaks, it reports
+the streak reward the player lost.
+
+Notifications are POSTed as JSON to --webhook and/or emailed through --smtp-addr to players who
+registered an email address. Players are given with --players (comma-separated addresses) or
+--players-file (one "address[,email]" per line). Use --dry-run to print notifications instead.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if playersRaw != "" {
+				for _, address := range strings.Split(playersRaw, ",") {
+					address = strings.TrimSpace(address)
+					if !common.IsHexAddress(address) {
+						return fmt.Errorf("invalid player address: %s", address)
+					}
+					players = append(players, reminder.Player{Address: common.HexToAddress(address)})
+				}
+			}
+			if playersFile != "" {
+				filePlayers, readErr := readReminderPlayers(playersFile)
+				if readErr != nil {
+					return readErr
+				}
+				players = append(players, filePlayers...)
+			}
+			if len(players) == 0 {
+				return fmt.Errorf("no players specified (use --players or --players-file)")
+			}
+
+			if webhookURL == "" && smtpAddr == "" && !dryRun {
+				return fmt.Errorf("no notification channel specified (use --webhook, --smtp-addr or --dry-run)")
+			}
+			if smtpAddr != "" && smtpFrom == "" {
+				return fmt.Errorf("--smtp-from must be specified with --smtp-addr")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			var notifiers reminder.MultiNotifier
+			if dryRun {
+				notifiers = append(notifiers, &reminder.WriterNotifier{Out: cmd.OutOrStdout()})
+			}
+			if webhookURL != "" {
+				notifiers = append(notifiers, reminder.NewWebhookNotifier(webhookURL))
+			}
+			if smtpAddr != "" {
+				smtpNotifier := &reminder.SMTPNotifier{Addr: smtpAddr, From: smtpFrom}
+				if smtpUsername != "" {
+					host, _, splitErr := net.SplitHostPort(smtpAddr)
+					if splitErr != nil {
+						return fmt.Errorf("invalid --smtp-addr: %v", splitErr)
+					}
+					password := ""
+					if smtpPasswordEnv != "" {
+						password = os.Getenv(smtpPasswordEnv)
+					}
+					smtpNotifier.Auth = smtp.PlainAuth("", smtpUsername, password, host)
+				}
+				notifiers = append(notifiers, smtpNotifier)
+			}
+
+			config := reminder.Config{
+				Contract:         contractAddress,
+				Players:          players,
+				DailyWarnBefore:  dailyWarnBefore,
+				WeeklyWarnBefore: weeklyWarnBefore,
+				PollInterval:     pollInterval,
+			}
+			r, reminderErr := reminder.New(client, notifiers, config, cmd.OutOrStdout())
+			if reminderErr != nil {
+				return reminderErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			cmd.Printf("Watching streaks of %d players on %s\n", len(players), contractAddress.Hex())
+			runErr := r.Run(ctx)
+
+			cmd.Println()
+			r.Stats().Report(cmd.OutOrStdout())
+
+			if errors.Is(runErr, context.Canceled) {
+				return nil
+			}
+			return runErr
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().StringVar(&playersRaw, "players", "", "Comma-separated addresses of the registered players")
+	cmd.Flags().StringVar(&playersFile, "players-file", "", "Path to a file of registered players (one \"address[,email]\" per line)")
+	cmd.Flags().StringVar(&webhookURL, "webhook", "", "URL to which notifications are POSTed as JSON")
+	cmd.Flags().StringVar(&smtpAddr, "smtp-addr", "", "Address (host:port) of the SMTP server through which to email notifications")
+	cmd.Flags().StringVar(&smtpFrom, "smtp-from", "", "Sender address for notification emails")
+	cmd.Flags().StringVar(&smtpUsername, "smtp-username", "", "Username for the SMTP server (optional)")
+	cmd.Flags().StringVar(&smtpPasswordEnv, "smtp-password-env", "", "Name of an environment variable containing the password for the SMTP server")
+	cmd.Flags().DurationVar(&dailyWarnBefore, "daily-warn-before", reminder.DefaultDailyWarnBefore, "How long before a daily streak breaks to warn the player")
+	cmd.Flags().DurationVar(&weeklyWarnBefore, "weekly-warn-before", reminder.DefaultWeeklyWarnBefore, "How long before a weekly streak breaks to warn the player")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", reminder.DefaultPollInterval, "Interval at which to check players' streaks")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print notifications instead of (or as well as) delivering them")
+
+	return cmd
+}
@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/G7DAO/seer/bindings/GnosisSafe"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/safetx"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// Base URL of the Safe transaction service used by CreateSafeProposal in the bindings.
+const DefaultSafeService = "https://safe-client.safe.global"
+
+func CreateSafeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "safe",
+		Short: "Build, sign, and execute Safe transactions offline",
+		Long: `Build, sign, and execute Safe transactions without the Safe transaction service.
+
+The workflow for a Safe with air-gapped owners is:
+  1. "build" writes an unsigned Safe transaction to a JSON file.
+  2. Each owner runs "sign" on the file (on their own machine). Owners can sign copies of the file in
+     parallel, and "merge" combines the signatures.
+  3. Once enough owners have signed, "exec" sends the Safe's execTransaction call (or prints its calldata).
+
+The signatures can also be posted to a Safe transaction service with "propose". "serve" runs a local
+stand-in for the transaction service. The Safe must be version 1.3.0 or later.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		CreateSafeBuildCommand(),
+		CreateSafeSignCommand(),
+		CreateSafeMergeCommand(),
+		CreateSafeExecCommand(),
+		CreateSafeProposeCommand(),
+		CreateSafeServeCommand(),
+	)
+
+	return cmd
+}
+
+func CreateSafeBuildCommand() *cobra.Command {
+	var rpc, safeAddressRaw, toRaw, valueRaw, dataRaw, dataFile, operationRaw, nonceRaw, chainIDRaw, description, outfile string
+	var timeout uint
+	var safeAddress, to common.Address
+	var value, nonce, chainID *big.Int
+	var data []byte
+	var operation DegenGambit.SafeOperationType
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Write an unsigned Safe transaction to a file",
+		Long: `Write an unsigned Safe transaction to a file.
+
+Without --rpc, the command does not access any chain, and --chain-id and --nonce must be specified.
+With --rpc, they default to the chain ID of the JSONRPC API and the Safe's current nonce.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if safeAddressRaw == "" {
+				return fmt.Errorf("--safe not specified")
+			} else if !common.IsHexAddress(safeAddressRaw) {
+				return fmt.Errorf("--safe is not a valid Ethereum address")
+			}
+			safeAddress = common.HexToAddress(safeAddressRaw)
+
+			if toRaw == "" {
+				return fmt.Errorf("--to not specified")
+			} else if !common.IsHexAddress(toRaw) {
+				return fmt.Errorf("--to is not a valid Ethereum address")
+			}
+			to = common.HexToAddress(toRaw)
+
+			var parseErr error
+			if value, parseErr = parseWei("value", valueRaw); parseErr != nil {
+				return parseErr
+			}
+			if nonce, parseErr = parseWei("nonce", nonceRaw); parseErr != nil {
+				return parseErr
+			}
+			if chainID, parseErr = parseWei("chain-id", chainIDRaw); parseErr != nil {
+				return parseErr
+			}
+			if rpc == "" && (nonce == nil || chainID == nil) {
+				return fmt.Errorf("--chain-id and --nonce must be specified when --rpc is not")
+			}
+
+			if dataRaw != "" && dataFile != "" {
+				return fmt.Errorf("only one of --data and --data-file may be specified")
+			}
+			if dataFile != "" {
+				contents, readErr := os.ReadFile(dataFile)
+				if readErr != nil {
+					return readErr
+				}
+				dataRaw = strings.TrimSpace(string(contents))
+			}
+			if dataRaw != "" {
+				if !strings.HasPrefix(dataRaw, "0x") {
+					dataRaw = "0x" + dataRaw
+				}
+				var decodeErr error
+				data, decodeErr = hexutil.Decode(dataRaw)
+				if decodeErr != nil {
+					return fmt.Errorf("--data is not valid hex: %v", decodeErr)
+				}
+			}
+
+			operation, parseErr = safetx.ParseOperation(operationRaw)
+			return parseErr
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if rpc != "" {
+				client, clientErr := DegenGambit.NewClient(rpc)
+				if clientErr != nil {
+					return clientErr
+				}
+
+				ctx, cancel := DegenGambit.NewChainContext(timeout)
+				defer cancel()
+
+				if chainID == nil {
+					var chainIDErr error
+					if chainID, chainIDErr = client.ChainID(ctx); chainIDErr != nil {
+						return chainIDErr
+					}
+				}
+				if nonce == nil {
+					safe, safeErr := GnosisSafe.NewGnosisSafe(safeAddress, client)
+					if safeErr != nil {
+						return safeErr
+					}
+					var nonceErr error
+					if nonce, nonceErr = safe.Nonce(&bind.CallOpts{Context: ctx}); nonceErr != nil {
+						return fmt.Errorf("failed to fetch nonce from Safe contract: %v", nonceErr)
+					}
+				}
+			}
+
+			tx, buildErr := safetx.New(chainID, safeAddress, to, value, data, operation, nonce)
+			if buildErr != nil {
+				return buildErr
+			}
+			tx.Description = description
+
+			if outfile == "" {
+				return tx.Write(cmd.OutOrStdout())
+			}
+			if err := tx.Save(outfile); err != nil {
+				return err
+			}
+			cmd.Printf("Wrote Safe transaction %s (nonce %s) to %s\n", tx.Transaction.SafeTxHash, nonce.String(), outfile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to fetch the chain ID and the Safe's nonce from (optional)")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&safeAddressRaw, "safe", "", "Address of the Safe")
+	cmd.Flags().StringVar(&toRaw, "to", "", "Address the Safe calls")
+	cmd.Flags().StringVar(&valueRaw, "value", "", "Value (in wei) the Safe sends with the call (default: 0)")
+	cmd.Flags().StringVar(&dataRaw, "data", "", "Hex-encoded calldata (e.g. as printed by the generated commands with --calldata)")
+	cmd.Flags().StringVar(&dataFile, "data-file", "", "Path to a file containing hex-encoded calldata")
+	cmd.Flags().StringVar(&operationRaw, "operation", "call", "Safe operation: 0 (call) or 1 (delegatecall)")
+	cmd.Flags().StringVar(&nonceRaw, "nonce", "", "Safe nonce for the transaction (default: the Safe's current nonce, requires --rpc)")
+	cmd.Flags().StringVar(&chainIDRaw, "chain-id", "", "ID of the chain the Safe is deployed on (default: the chain of --rpc)")
+	cmd.Flags().StringVar(&description, "description", "", "Description of the transaction, shown to the owners who sign it")
+	cmd.Flags().StringVarP(&outfile, "output", "o", "", "Path to write the Safe transaction to (default: stdout)")
+
+	return cmd
+}
+
+func CreateSafeSignCommand() *cobra.Command {
+	var infile, outfile string
+	var tx *safetx.Transaction
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign a Safe transaction as one of the Safe's owners",
+		Long: `Sign a Safe transaction as one of the Safe's owners.
+
+The command checks that the SafeTxHash in the file matches the transaction, prints the transaction, and
+adds the owner's signature of the SafeTxHash to the file (or to --output). It does not access any chain.
+External signers cannot sign Safe transactions; use a keystore file, private key, or mnemonic.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if infile == "" {
+				return fmt.Errorf("--file not specified")
+			}
+			var loadErr error
+			if tx, loadErr = safetx.Load(infile); loadErr != nil {
+				return loadErr
+			}
+			if outfile == "" {
+				outfile = infile
+			}
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			owner, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+			hashSigner, ok := owner.(signer.HashSigner)
+			if !ok {
+				return fmt.Errorf("the selected signer cannot sign Safe transactions")
+			}
+
+			describeSafeTransaction(cmd, tx)
+			if err := tx.Sign(hashSigner); err != nil {
+				return err
+			}
+			if err := tx.Save(outfile); err != nil {
+				return err
+			}
+			cmd.Printf("Signed as %s (%d signatures) and wrote %s\n", owner.Address().Hex(), len(tx.Signatures), outfile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&infile, "file", "f", "", "Path to the Safe transaction to sign")
+	cmd.Flags().StringVarP(&outfile, "output", "o", "", "Path to write the signed Safe transaction to (default: --file)")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func CreateSafeMergeCommand() *cobra.Command {
+	var outfile string
+	var transactions []*safetx.Transaction
+
+	cmd := &cobra.Command{
+		Use:   "merge <file> <file> [<file>...]",
+		Short: "Merge the signatures on copies of the same Safe transaction",
+		Args:  cobra.MinimumNArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if outfile == "" {
+				return fmt.Errorf("--output not specified")
+			}
+			for _, path := range args {
+				tx, loadErr := safetx.Load(path)
+				if loadErr != nil {
+					return loadErr
+				}
+				transactions = append(transactions, tx)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			merged := transactions[0]
+			if err := merged.Merge(transactions[1:]...); err != nil {
+				return err
+			}
+			if err := merged.Save(outfile); err != nil {
+				return err
+			}
+			cmd.Printf("Merged %d signatures for %s into %s\n", len(merged.Signatures), merged.Transaction.SafeTxHash, outfile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outfile, "output", "o", "", "Path to write the merged Safe transaction to")
+
+	return cmd
+}
+
+func CreateSafeExecCommand() *cobra.Command {
+	var rpc, infile string
+	var calldata bool
+	var timeout uint
+	var tx *safetx.Transaction
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "exec",
+		Short: "Execute a signed Safe transaction",
+		Long: `Execute a Safe transaction once enough owners have signed it.
+
+The command checks that every signature was made by an owner of the Safe, that the Safe's threshold is
+met, and that the Safe's nonce matches the transaction, and then sends execTransaction from the account
+selected with the signer flags (which need not be an owner). With --calldata, it instead prints the
+calldata of the execTransaction call without accessing any chain.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if infile == "" {
+				return fmt.Errorf("--file not specified")
+			}
+			var loadErr error
+			if tx, loadErr = safetx.Load(infile); loadErr != nil {
+				return loadErr
+			}
+			if len(tx.Signatures) == 0 {
+				return fmt.Errorf("the Safe transaction has no signatures")
+			}
+			if calldata {
+				return nil
+			}
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if calldata {
+				execCalldata, packErr := tx.ExecTransactionCalldata()
+				if packErr != nil {
+					return packErr
+				}
+				cmd.Printf("To: %s\n", tx.Safe.Hex())
+				cmd.Printf("Calldata: %s\n", hexutil.Encode(execCalldata))
+				return nil
+			}
+
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			safe, safeErr := GnosisSafe.NewGnosisSafe(tx.Safe, client)
+			if safeErr != nil {
+				return safeErr
+			}
+
+			checkCtx, cancelCheckCtx := DegenGambit.NewChainContext(timeout)
+			defer cancelCheckCtx()
+
+			chainID, chainIDErr := client.ChainID(checkCtx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+			if chainID.Cmp(tx.ChainID) != 0 {
+				return fmt.Errorf("the Safe transaction is for chain %s, but the JSONRPC API serves chain %s", tx.ChainID.String(), chainID.String())
+			}
+
+			callOpts := &bind.CallOpts{Context: checkCtx}
+			owners, ownersErr := safe.GetOwners(callOpts)
+			if ownersErr != nil {
+				return fmt.Errorf("failed to get owners of the Safe: %v", ownersErr)
+			}
+			threshold, thresholdErr := safe.GetThreshold(callOpts)
+			if thresholdErr != nil {
+				return fmt.Errorf("failed to get threshold of the Safe: %v", thresholdErr)
+			}
+			if err := tx.CheckOwners(owners, threshold); err != nil {
+				return err
+			}
+			safeNonce, nonceErr := safe.Nonce(callOpts)
+			if nonceErr != nil {
+				return fmt.Errorf("failed to fetch nonce from Safe contract: %v", nonceErr)
+			}
+			if safeNonce.Cmp(tx.Transaction.Nonce) != 0 {
+				return fmt.Errorf("the Safe's nonce is %s, but the transaction has nonce %s", safeNonce.String(), tx.Transaction.Nonce.String())
+			}
+
+			executor, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(executor, chainID), chainID, "")
+			if managerErr != nil {
+				return managerErr
+			}
+
+			to, value, data, operation, safeTxGas, baseGas, gasPrice, gasToken, refundReceiver, signatures := tx.ExecTransactionArgs()
+			execTx, execErr := manager.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return safe.ExecTransaction(opts, to, value, data, operation, safeTxGas, baseGas, gasPrice, gasToken, refundReceiver, signatures)
+			})
+			if execErr != nil {
+				if reason := gambit.RevertReason(execErr); reason != "" {
+					return fmt.Errorf("execTransaction reverted: %s", reason)
+				}
+				return fmt.Errorf("failed to send execTransaction: %v", execErr)
+			}
+			cmd.Printf("Executing Safe transaction %s: %s\n", tx.Transaction.SafeTxHash, execTx.Hash().Hex())
+
+			receipt, receiptErr := bind.WaitMined(ctx, client, execTx)
+			if receiptErr != nil {
+				return receiptErr
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return fmt.Errorf("execTransaction %s failed", execTx.Hash().Hex())
+			}
+
+			// The Safe does not revert if the call it makes fails; it emits ExecutionFailure instead.
+			for _, log := range receipt.Logs {
+				if _, err := safe.ParseExecutionFailure(*log); err == nil {
+					return fmt.Errorf("the Safe executed %s, but the call it made failed", tx.Transaction.SafeTxHash)
+				}
+			}
+			cmd.Printf("Executed Safe transaction %s in block %d\n", tx.Transaction.SafeTxHash, receipt.BlockNumber.Uint64())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVarP(&infile, "file", "f", "", "Path to the signed Safe transaction")
+	cmd.Flags().BoolVar(&calldata, "calldata", false, "Print the calldata of the execTransaction call instead of sending it")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func CreateSafeProposeCommand() *cobra.Command {
+	var infile, service string
+	var timeout uint
+	var tx *safetx.Transaction
+
+	cmd := &cobra.Command{
+		Use:   "propose",
+		Short: "Post the signatures on a Safe transaction to a Safe transaction service",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if infile == "" {
+				return fmt.Errorf("--file not specified")
+			}
+			var loadErr error
+			tx, loadErr = safetx.Load(infile)
+			return loadErr
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+			defer cancel()
+
+			proposeURL := safetx.ProposeURL(strings.TrimSuffix(service, "/"), tx.ChainID, tx.Safe)
+			if err := safetx.Propose(ctx, http.DefaultClient, proposeURL, tx); err != nil {
+				return err
+			}
+			cmd.Printf("Proposed %s with %d signatures to %s\n", tx.Transaction.SafeTxHash, len(tx.Signatures), proposeURL)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&infile, "file", "f", "", "Path to the signed Safe transaction")
+	cmd.Flags().StringVar(&service, "service", DefaultSafeService, "Base URL of the Safe transaction service")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for requests to the Safe transaction service")
+
+	return cmd
+}
+
+func CreateSafeServeCommand() *cobra.Command {
+	var listen string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run a local stand-in for the Safe transaction service",
+		Long: `Run a local stand-in for the Safe transaction service.
+
+The stand-in accepts proposals at the endpoint the bindings' --safe-api default and "propose" use
+(POST /v1/chains/{chainID}/transactions/{safe}/propose), checks their SafeTxHash and signature, and
+collects the signatures for each transaction. GET /v1/transactions/{safeTxHash} returns a collected
+transaction in the format of the files "sign", "merge", and "exec" use. Nothing is persisted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			server := &http.Server{Addr: listen, Handler: safetx.NewService().Handler()}
+			go func() {
+				<-ctx.Done()
+				server.Close()
+			}()
+
+			cmd.Printf("Serving a stand-in Safe transaction service at http://%s\n", listen)
+			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8734", "Address to listen on")
+
+	return cmd
+}
+
+// describeSafeTransaction prints the fields of a Safe transaction that an owner should check before
+// signing it.
+func describeSafeTransaction(cmd *cobra.Command, tx *safetx.Transaction) {
+	if tx.Description != "" {
+		cmd.Printf("Description: %s\n", tx.Description)
+	}
+	cmd.Printf("Safe: %s (chain %s)\n", tx.Safe.Hex(), tx.ChainID.String())
+	cmd.Printf("To: %s\n", tx.Transaction.To)
+	cmd.Printf("Value: %s\n", tx.Transaction.Value)
+	cmd.Printf("Data: 0x%s\n", tx.Transaction.Data)
+	cmd.Printf("Operation: %s\n", tx.Transaction.Operation.String())
+	cmd.Printf("Nonce: %s\n", tx.Transaction.Nonce.String())
+	cmd.Printf("SafeTxHash: %s\n", tx.Transaction.SafeTxHash)
+	for _, signature := range tx.Signatures {
+		cmd.Printf("Signed by: %s\n", signature.Owner.Hex())
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+var weekdaysByName = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseSchedule parses a play schedule: "daily", "every:<n>" (every n days starting today), or a
+// comma-separated list of UTC weekdays (e.g. "mon,wed,fri").
+func parseSchedule(schedule string, today uint64) (func(day uint64) bool, error) {
+	schedule = strings.ToLower(strings.TrimSpace(schedule))
+	switch {
+	case schedule == "daily":
+		return gambit.SpinEvery(today, 1), nil
+	case strings.HasPrefix(schedule, "every:"):
+		interval, parseErr := strconv.ParseUint(strings.TrimPrefix(schedule, "every:"), 10, 64)
+		if parseErr != nil || interval == 0 {
+			return nil, fmt.Errorf("invalid schedule interval: %s", schedule)
+		}
+		return gambit.SpinEvery(today, interval), nil
+	default:
+		var weekdays []time.Weekday
+		for _, name := range strings.Split(schedule, ",") {
+			name = strings.TrimSpace(name)
+			if len(name) < 3 {
+				return nil, fmt.Errorf("invalid weekday in schedule: %s", name)
+			}
+			weekday, ok := weekdaysByName[name[:3]]
+			if !ok {
+				return nil, fmt.Errorf("invalid weekday in schedule: %s", name)
+			}
+			weekdays = append(weekdays, weekday)
+		}
+		return gambit.SpinOnWeekdays(weekdays...), nil
+	}
+}
+
+func CreateStreaksCommand() *cobra.Command {
+	var rpc, contractAddressRaw, schedule, timezone string
+	var days int
+	var timeout uint
+	var contractAddress, player common.Address
+	var location *time.Location
+
+	cmd := &cobra.Command{
+		Use:   "streaks <address>",
+		Short: "Show when a player must spin to keep their streaks, and forecast streak rewards",
+		Long: `Show when a player must spin to keep their streaks, and forecast streak rewards.
+
+Streaks are tracked in UTC: a day runs from 00:00 to 24:00 UTC, and a week runs from Thursday 00:00 UTC
+to the next Thursday 00:00 UTC (the contract computes day = timestamp / 86400 and week = day / 7). Times
+are shown both in UTC and in --timezone.
+
+The calendar simulates the player spinning according to --schedule over the next --days days, and shows
+the DailyStreakReward and WeeklyStreakReward GAMBIT they would earn. Schedules:
+  daily            spin every day
+  every:<n>        spin every n days, starting today
+  mon,wed,fri,...  spin on the given (UTC) weekdays`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if !common.IsHexAddress(args[0]) {
+				return fmt.Errorf("player is not a valid Ethereum address")
+			}
+			player = common.HexToAddress(args[0])
+
+			if days <= 0 {
+				return fmt.Errorf("--days must be positive")
+			}
+
+			var locationErr error
+			location, locationErr = time.LoadLocation(timezone)
+			if locationErr != nil {
+				return fmt.Errorf("invalid --timezone: %v", locationErr)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, cancel := DegenGambit.NewChainContext(timeout)
+			defer cancel()
+
+			// Streaks are computed from block timestamps, so use the chain's clock rather than ours.
+			header, headerErr := client.HeaderByNumber(ctx, nil)
+			if headerErr != nil {
+				return headerErr
+			}
+			callOpts := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+
+			state, stateErr := gambit.ReadStreakState(callOpts, contract, player)
+			if stateErr != nil {
+				return stateErr
+			}
+			rewards, rewardsErr := gambit.ReadStreakRewards(callOpts, contract)
+			if rewardsErr != nil {
+				return rewardsErr
+			}
+
+			now := header.Time
+			today := gambit.Day(now)
+			spinOn, scheduleErr := parseSchedule(schedule, today)
+			if scheduleErr != nil {
+				return scheduleErr
+			}
+
+			formatTime := func(t time.Time) string {
+				return fmt.Sprintf("%s (%s)", t.UTC().Format("Mon 2006-01-02 15:04 MST"), t.In(location).Format("Mon 2006-01-02 15:04 MST"))
+			}
+
+			cmd.Printf("Player: %s\n", player.Hex())
+			cmd.Printf("Now: %s, day %d, week %d\n", formatTime(time.Unix(int64(now), 0)), today, gambit.Week(today))
+
+			describeWindow := func(name, period string, length uint64, last uint64, window gambit.StreakWindow) {
+				switch window.Status {
+				case gambit.StreakExtends:
+					cmd.Printf("%s streak: length %d. Spin before %s to extend it.\n", name, length, formatTime(window.End))
+				case gambit.StreakCurrent:
+					cmd.Printf("%s streak: length %d. Already spun this %s; spin next between %s and %s.\n", name, length, period, formatTime(window.Start), formatTime(window.End))
+				default:
+					if last == 0 {
+						cmd.Printf("%s streak: none. A spin now starts one.\n", name)
+					} else {
+						cmd.Printf("%s streak: broken (was %d). A spin now starts a new one.\n", name, length)
+					}
+				}
+			}
+			cmd.Println()
+			describeWindow("Daily", "day", state.CurrentDailyStreakLength, state.LastStreakDay, state.DailyWindow(now))
+			describeWindow("Weekly", "week", state.CurrentWeeklyStreakLength, state.LastStreakWeek, state.WeeklyWindow(now))
+
+			forecast := gambit.ForecastStreaks(state, rewards, today, days, spinOn)
+
+			cmd.Println()
+			cmd.Printf("Calendar (schedule: %s):\n", schedule)
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Day\tDate (UTC)\tWeek\tSpin\tDaily reward\tWeekly reward\tDaily streak\tWeekly streak")
+			for _, day := range forecast.Days {
+				fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\t%d\t%d\n", day.Day, gambit.DayStart(day.Day).Format("Mon 2006-01-02"), gambit.Week(day.Day), yesNo(day.Spin), yesNo(day.DailyReward), yesNo(day.WeeklyReward), day.DailyStreakLength, day.WeeklyStreakLength)
+			}
+			w.Flush()
+
+			cmd.Println()
+			cmd.Printf("Forecast: %d spins, %d daily rewards, %d weekly rewards, %s GAMBIT in total\n", forecast.Spins, forecast.DailyRewards, forecast.WeeklyRewards, gambit.FormatUnits(forecast.Gambit, gambit.Decimals))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&schedule, "schedule", "daily", "Play schedule to forecast: daily, every:<n>, or comma-separated weekdays (e.g. mon,wed,fri)")
+	cmd.Flags().IntVar(&days, "days", 14, "Number of days to show in the calendar")
+	cmd.Flags().StringVar(&timezone, "timezone", "Local", "Time zone (e.g. America/New_York) in which to show times alongside UTC")
+
+	return cmd
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "-"
+}
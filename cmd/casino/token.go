@@ -0,0 +1,610 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/atomicfile"
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// tokenInfo describes the GAMBIT token of a DegenGambit contract.
+type tokenInfo struct {
+	name     string
+	symbol   string
+	decimals int
+}
+
+func readTokenInfo(opts *bind.CallOpts, contract *DegenGambit.DegenGambit) (tokenInfo, error) {
+	name, nameErr := contract.Name(opts)
+	if nameErr != nil {
+		return tokenInfo{}, fmt.Errorf("failed to get name: %v", nameErr)
+	}
+	symbol, symbolErr := contract.Symbol(opts)
+	if symbolErr != nil {
+		return tokenInfo{}, fmt.Errorf("failed to get symbol: %v", symbolErr)
+	}
+	decimals, decimalsErr := contract.Decimals(opts)
+	if decimalsErr != nil {
+		return tokenInfo{}, fmt.Errorf("failed to get decimals: %v", decimalsErr)
+	}
+	return tokenInfo{name: name, symbol: symbol, decimals: int(decimals)}, nil
+}
+
+// format formats an amount of the token, e.g. "2.5 DG-1234".
+func (t tokenInfo) format(amount *big.Int) string {
+	return gambit.FormatUnits(amount, t.decimals) + " " + t.symbol
+}
+
+// parse parses a decimal amount of the token, optionally followed by its symbol or "GAMBIT" (e.g.
+// "2.5 GAMBIT").
+func (t tokenInfo) parse(value string) (*big.Int, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 2 && (fields[1] == t.symbol || strings.EqualFold(fields[1], "GAMBIT")) {
+		fields = fields[:1]
+	}
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("invalid amount: %q (expected e.g. \"2.5\" or \"2.5 %s\")", value, t.symbol)
+	}
+	return gambit.ParseUnits(fields[0], t.decimals)
+}
+
+// tokenContext holds the connection shared by the token subcommands.
+type tokenContext struct {
+	client   *ethclient.Client
+	contract *DegenGambit.DegenGambit
+	info     tokenInfo
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func newTokenContext(rpc string, contractAddress common.Address, timeout uint) (*tokenContext, error) {
+	client, clientErr := DegenGambit.NewClient(rpc)
+	if clientErr != nil {
+		return nil, clientErr
+	}
+	contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+
+	ctx, cancel := DegenGambit.NewChainContext(timeout)
+	info, infoErr := readTokenInfo(&bind.CallOpts{Context: ctx}, contract)
+	if infoErr != nil {
+		cancel()
+		return nil, infoErr
+	}
+	return &tokenContext{client: client, contract: contract, info: info, ctx: ctx, cancel: cancel}, nil
+}
+
+func (t *tokenContext) callOpts() *bind.CallOpts {
+	return &bind.CallOpts{Context: t.ctx}
+}
+
+// transact sends a single transaction from the given signer and waits for it to be mined.
+func (t *tokenContext) transact(cmd *cobra.Command, s signer.Signer, transact func(opts *bind.TransactOpts) (*types.Transaction, error)) error {
+	chainID, chainIDErr := t.client.ChainID(t.ctx)
+	if chainIDErr != nil {
+		return chainIDErr
+	}
+	opts := signer.NewTransactOpts(s, chainID)
+	opts.Context = t.ctx
+
+	tx, txErr := transact(opts)
+	if txErr != nil {
+		return txErr
+	}
+	cmd.Printf("Transaction: %s\n", tx.Hash().Hex())
+
+	receipt, receiptErr := bind.WaitMined(t.ctx, t.client, tx)
+	if receiptErr != nil {
+		return receiptErr
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return nil
+}
+
+func parseAddressArg(name, value string) (common.Address, error) {
+	if !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("%s is not a valid Ethereum address: %s", name, value)
+	}
+	return common.HexToAddress(value), nil
+}
+
+func CreateTokenCommand() *cobra.Command {
+	var rpc, contractAddressRaw string
+	var timeout uint
+	var contractAddress common.Address
+
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage GAMBIT tokens using decimal amounts",
+		Long: `Manage GAMBIT tokens using decimal amounts.
+
+GAMBIT is an ERC20 token with 18 decimals. Unlike the generated transfer, approve, allowance and balance-of
+commands, which work in wei, these commands accept and print decimal amounts (e.g. "2.5" or "2.5 GAMBIT")
+and show the token's symbol (DG-xxxx, derived from the contract address).`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.PersistentFlags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.PersistentFlags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+
+	connect := func() (*tokenContext, error) {
+		return newTokenContext(rpc, contractAddress, timeout)
+	}
+
+	cmd.AddCommand(
+		createTokenInfoCommand(connect),
+		createTokenBalanceCommand(connect),
+		createTokenAllowanceCommand(connect),
+		createTokenTransferCommand(connect),
+		createTokenApproveCommand(connect),
+		createTokenAirdropCommand(connect),
+	)
+
+	return cmd
+}
+
+func createTokenInfoCommand(connect func() (*tokenContext, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Show the token's name, symbol, decimals and total supply",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, connectErr := connect()
+			if connectErr != nil {
+				return connectErr
+			}
+			defer t.cancel()
+
+			totalSupply, totalSupplyErr := t.contract.TotalSupply(t.callOpts())
+			if totalSupplyErr != nil {
+				return totalSupplyErr
+			}
+
+			cmd.Printf("Name: %s\nSymbol: %s\nDecimals: %d\nTotal supply: %s\n", t.info.name, t.info.symbol, t.info.decimals, t.info.format(totalSupply))
+			return nil
+		},
+	}
+}
+
+func createTokenBalanceCommand(connect func() (*tokenContext, error)) *cobra.Command {
+	var accounts []common.Address
+
+	return &cobra.Command{
+		Use:   "balance <address> [<address>...]",
+		Short: "Show GAMBIT balances",
+		Args:  cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				account, parseErr := parseAddressArg("address", arg)
+				if parseErr != nil {
+					return parseErr
+				}
+				accounts = append(accounts, account)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, connectErr := connect()
+			if connectErr != nil {
+				return connectErr
+			}
+			defer t.cancel()
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, account := range accounts {
+				balance, balanceErr := t.contract.BalanceOf(t.callOpts(), account)
+				if balanceErr != nil {
+					return balanceErr
+				}
+				fmt.Fprintf(w, "%s\t%s\n", account.Hex(), t.info.format(balance))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func createTokenAllowanceCommand(connect func() (*tokenContext, error)) *cobra.Command {
+	var owner, spender common.Address
+
+	return &cobra.Command{
+		Use:   "allowance <owner> <spender>",
+		Short: "Show how much GAMBIT a spender may transfer on behalf of an owner",
+		Args:  cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			var parseErr error
+			if owner, parseErr = parseAddressArg("owner", args[0]); parseErr != nil {
+				return parseErr
+			}
+			spender, parseErr = parseAddressArg("spender", args[1])
+			return parseErr
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, connectErr := connect()
+			if connectErr != nil {
+				return connectErr
+			}
+			defer t.cancel()
+
+			allowance, allowanceErr := t.contract.Allowance(t.callOpts(), owner, spender)
+			if allowanceErr != nil {
+				return allowanceErr
+			}
+			cmd.Println(t.info.format(allowance))
+			return nil
+		},
+	}
+}
+
+// createTokenSendCommand creates the transfer and approve commands, which both take an address and an
+// amount (or --all).
+func createTokenSendCommand(connect func() (*tokenContext, error), use, short, allUsage string, send func(t *tokenContext, opts *bind.TransactOpts, to common.Address, amount *big.Int) (*types.Transaction, error), describe func(t *tokenContext, from, to common.Address, amount *big.Int) string) *cobra.Command {
+	var all bool
+	var to common.Address
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.RangeArgs(1, 2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			var parseErr error
+			if to, parseErr = parseAddressArg("address", args[0]); parseErr != nil {
+				return parseErr
+			}
+			if all == (len(args) == 2) {
+				return fmt.Errorf("specify either an amount or --all")
+			}
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			t, connectErr := connect()
+			if connectErr != nil {
+				return connectErr
+			}
+			defer t.cancel()
+
+			balance, balanceErr := t.contract.BalanceOf(t.callOpts(), s.Address())
+			if balanceErr != nil {
+				return balanceErr
+			}
+
+			amount := balance
+			if !all {
+				var parseErr error
+				if amount, parseErr = t.info.parse(args[1]); parseErr != nil {
+					return parseErr
+				}
+			}
+
+			cmd.Println(describe(t, s.Address(), to, amount))
+			return t.transact(cmd, s, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return send(t, opts, to, amount)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, allUsage)
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func createTokenTransferCommand(connect func() (*tokenContext, error)) *cobra.Command {
+	return createTokenSendCommand(
+		connect,
+		"transfer <to> [<amount>]",
+		"Transfer GAMBIT (e.g. \"transfer 0x... 2.5\")",
+		"Transfer the signer's entire balance",
+		func(t *tokenContext, opts *bind.TransactOpts, to common.Address, amount *big.Int) (*types.Transaction, error) {
+			return t.contract.Transfer(opts, to, amount)
+		},
+		func(t *tokenContext, from, to common.Address, amount *big.Int) string {
+			return fmt.Sprintf("Transferring %s from %s to %s", t.info.format(amount), from.Hex(), to.Hex())
+		},
+	)
+}
+
+func createTokenApproveCommand(connect func() (*tokenContext, error)) *cobra.Command {
+	return createTokenSendCommand(
+		connect,
+		"approve <spender> [<amount>]",
+		"Allow a spender to transfer GAMBIT on the signer's behalf (e.g. \"approve 0x... 2.5\")",
+		"Approve the signer's entire current balance",
+		func(t *tokenContext, opts *bind.TransactOpts, spender common.Address, amount *big.Int) (*types.Transaction, error) {
+			return t.contract.Approve(opts, spender, amount)
+		},
+		func(t *tokenContext, owner, spender common.Address, amount *big.Int) string {
+			return fmt.Sprintf("Approving %s to spend %s on behalf of %s", spender.Hex(), t.info.format(amount), owner.Hex())
+		},
+	)
+}
+
+// airdropJournalEntry is a transfer recorded in an airdrop journal.
+type airdropJournalEntry struct {
+	To     common.Address
+	Amount *big.Int
+}
+
+// readAirdropJournal reads the transfers recorded in an airdrop journal, keyed by CSV line. Each line
+// of the journal records a transfer which was submitted: "line,address,amount,transaction hash".
+func readAirdropJournal(path string) (map[int]airdropJournalEntry, error) {
+	done := make(map[int]airdropJournalEntry)
+	file, openErr := os.Open(path)
+	if errors.Is(openErr, os.ErrNotExist) {
+		return done, nil
+	} else if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := scanner.Text()
+		if entry == "" {
+			continue
+		}
+		fields := strings.Split(entry, ",")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid entry in airdrop journal %s: %s", path, entry)
+		}
+		line, lineErr := strconv.Atoi(fields[0])
+		amount, amountOk := new(big.Int).SetString(fields[2], 10)
+		if lineErr != nil || !common.IsHexAddress(fields[1]) || !amountOk {
+			return nil, fmt.Errorf("invalid entry in airdrop journal %s: %s", path, entry)
+		}
+		done[line] = airdropJournalEntry{To: common.HexToAddress(fields[1]), Amount: amount}
+	}
+	return done, scanner.Err()
+}
+
+// checkAirdropJournal checks that every transfer recorded in the journal is still on the same line of
+// the CSV file, with the same address and amount. If the file was edited after the airdrop started,
+// skipping rows by line number could skip transfers which were never sent, or send some twice.
+func checkAirdropJournal(transfers []gambit.Transfer, done map[int]airdropJournalEntry, format func(*big.Int) string) error {
+	rows := make(map[int]gambit.Transfer, len(transfers))
+	for _, transfer := range transfers {
+		rows[transfer.Line] = transfer
+	}
+
+	lines := make([]int, 0, len(done))
+	for line := range done {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+
+	var mismatches []error
+	for _, line := range lines {
+		entry := done[line]
+		transfer, ok := rows[line]
+		if !ok {
+			mismatches = append(mismatches, fmt.Errorf("line %d: the journal records a transfer of %s to %s, but the line has no transfer", line, format(entry.Amount), entry.To.Hex()))
+		} else if transfer.To != entry.To || transfer.Amount.Cmp(entry.Amount) != 0 {
+			mismatches = append(mismatches, fmt.Errorf("line %d: the journal records a transfer of %s to %s, but the line is a transfer of %s to %s", line, format(entry.Amount), entry.To.Hex(), format(transfer.Amount), transfer.To.Hex()))
+		}
+	}
+	return errors.Join(mismatches...)
+}
+
+func createTokenAirdropCommand(connect func() (*tokenContext, error)) *cobra.Command {
+	var file, journal string
+	var dryRun bool
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "airdrop",
+		Short: "Transfer GAMBIT to many recipients from a CSV file",
+		Long: `Transfer GAMBIT to many recipients from a CSV file.
+
+Each row of --file has the form "address,amount", where amount is a decimal number of tokens (e.g. 1 or
+"2.5 GAMBIT"). A header row and lines starting with # are ignored. The whole file is validated, and the
+total checked against the signer's balance, before any transfer is sent.
+
+Every submitted transfer is recorded in --journal (default: the CSV file's path with ".journal" appended).
+Rows recorded in the journal are skipped, so an interrupted airdrop can be resumed by running the command
+again. Transfers which revert are removed from the journal so that they are retried. If the journal
+records a different address or amount for a line than the file now has, the command refuses to run.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return fmt.Errorf("--file not specified")
+			}
+			if journal == "" {
+				journal = file + ".journal"
+			}
+			if dryRun {
+				return nil
+			}
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, connectErr := connect()
+			if connectErr != nil {
+				return connectErr
+			}
+			defer t.cancel()
+
+			csvFile, openErr := os.Open(file)
+			if openErr != nil {
+				return openErr
+			}
+			transfers, readErr := gambit.ReadTransfersCSV(csvFile, t.info.decimals)
+			csvFile.Close()
+			if readErr != nil {
+				return fmt.Errorf("invalid airdrop file %s:\n%v", file, readErr)
+			}
+
+			done, journalErr := readAirdropJournal(journal)
+			if journalErr != nil {
+				return journalErr
+			}
+			if err := checkAirdropJournal(transfers, done, t.info.format); err != nil {
+				return fmt.Errorf("journal %s does not match %s, so the airdrop cannot be resumed:\n%v", journal, file, err)
+			}
+
+			var pending []gambit.Transfer
+			total := big.NewInt(0)
+			recipients := make(map[common.Address]int)
+			for _, transfer := range transfers {
+				if previous, ok := recipients[transfer.To]; ok {
+					cmd.Printf("Warning: %s appears on lines %d and %d\n", transfer.To.Hex(), previous, transfer.Line)
+				}
+				recipients[transfer.To] = transfer.Line
+				if _, ok := done[transfer.Line]; ok {
+					continue
+				}
+				pending = append(pending, transfer)
+				total.Add(total, transfer.Amount)
+			}
+			cmd.Printf("%d transfers (%d already sent), %s to send\n", len(transfers), len(transfers)-len(pending), t.info.format(total))
+
+			if dryRun {
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				for _, transfer := range pending {
+					fmt.Fprintf(w, "%d\t%s\t%s\n", transfer.Line, transfer.To.Hex(), t.info.format(transfer.Amount))
+				}
+				return w.Flush()
+			}
+			if len(pending) == 0 {
+				return nil
+			}
+
+			s, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+			balance, balanceErr := t.contract.BalanceOf(t.callOpts(), s.Address())
+			if balanceErr != nil {
+				return balanceErr
+			}
+			if balance.Cmp(total) < 0 {
+				return fmt.Errorf("%s has %s but the airdrop needs %s", s.Address().Hex(), t.info.format(balance), t.info.format(total))
+			}
+
+			// The airdrop may take longer than --timeout, so it runs until interrupted instead.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			chainID, chainIDErr := t.client.ChainID(ctx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+			manager, managerErr := txmanager.New(ctx, t.client, signer.NewTransactOpts(s, chainID), chainID, "")
+			if managerErr != nil {
+				return managerErr
+			}
+
+			journalFile, journalOpenErr := os.OpenFile(journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if journalOpenErr != nil {
+				return journalOpenErr
+			}
+			defer journalFile.Close()
+
+			type submitted struct {
+				transfer gambit.Transfer
+				tx       *types.Transaction
+			}
+			var sent []submitted
+			var sendErr error
+			for _, transfer := range pending {
+				tx, txErr := manager.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+					return t.contract.Transfer(opts, transfer.To, transfer.Amount)
+				})
+				if txErr != nil {
+					sendErr = fmt.Errorf("failed to send transfer on line %d: %v", transfer.Line, txErr)
+					break
+				}
+				if _, err := fmt.Fprintf(journalFile, "%d,%s,%s,%s\n", transfer.Line, transfer.To.Hex(), transfer.Amount.String(), tx.Hash().Hex()); err != nil {
+					return err
+				}
+				sent = append(sent, submitted{transfer: transfer, tx: tx})
+				cmd.Printf("Line %d: %s to %s: %s\n", transfer.Line, t.info.format(transfer.Amount), transfer.To.Hex(), tx.Hash().Hex())
+			}
+
+			var reverted []int
+			for _, item := range sent {
+				receipt, receiptErr := bind.WaitMined(ctx, t.client, item.tx)
+				if receiptErr != nil {
+					return fmt.Errorf("failed to wait for transfer on line %d: %v", item.transfer.Line, receiptErr)
+				}
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					reverted = append(reverted, item.transfer.Line)
+				}
+			}
+
+			if len(reverted) > 0 {
+				cmd.Printf("Transfers on lines %v reverted and will be retried on the next run\n", reverted)
+				if err := removeFromAirdropJournal(journal, reverted); err != nil {
+					return err
+				}
+			}
+			cmd.Printf("Sent %d of %d transfers\n", len(sent)-len(reverted), len(pending))
+			return sendErr
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Path to a CSV file of address,amount rows")
+	cmd.Flags().StringVar(&journal, "journal", "", "Path to the journal of submitted transfers (default: --file with \".journal\" appended)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the file and print the transfers which would be sent")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// removeFromAirdropJournal removes the entries for the given CSV lines from an airdrop journal.
+func removeFromAirdropJournal(path string, lines []int) error {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return readErr
+	}
+	remove := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		remove[strconv.Itoa(line)] = true
+	}
+
+	var kept []string
+	for _, entry := range strings.Split(strings.TrimRight(string(contents), "\n"), "\n") {
+		line, _, _ := strings.Cut(entry, ",")
+		if entry != "" && !remove[line] {
+			kept = append(kept, entry+"\n")
+		}
+	}
+	return atomicfile.WriteFile(path, []byte(strings.Join(kept, "")))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+func TestAirdropJournal(t *testing.T) {
+	alice := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	bob := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	format := func(amount *big.Int) string { return amount.String() }
+
+	journal := filepath.Join(t.TempDir(), "airdrop.csv.journal")
+	entries := "2," + alice.Hex() + ",100,0x01\n3," + bob.Hex() + ",200,0x02\n"
+	if err := os.WriteFile(journal, []byte(entries), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+	done, readErr := readAirdropJournal(journal)
+	if readErr != nil {
+		t.Fatalf("failed to read journal: %v", readErr)
+	}
+
+	transfers := []gambit.Transfer{
+		{Line: 2, To: alice, Amount: big.NewInt(100)},
+		{Line: 3, To: bob, Amount: big.NewInt(200)},
+		{Line: 4, To: bob, Amount: big.NewInt(300)},
+	}
+	if err := checkAirdropJournal(transfers, done, format); err != nil {
+		t.Fatalf("expected the journal to match: %v", err)
+	}
+
+	// A row was inserted above the transfers which were already sent, shifting them down a line.
+	shifted := []gambit.Transfer{
+		{Line: 2, To: bob, Amount: big.NewInt(50)},
+		{Line: 3, To: alice, Amount: big.NewInt(100)},
+		{Line: 4, To: bob, Amount: big.NewInt(200)},
+	}
+	err := checkAirdropJournal(shifted, done, format)
+	if err == nil || !strings.Contains(err.Error(), "line 2:") || !strings.Contains(err.Error(), "line 3:") {
+		t.Fatalf("expected both shifted lines to be reported, got: %v", err)
+	}
+
+	changedAmount := []gambit.Transfer{
+		{Line: 2, To: alice, Amount: big.NewInt(100)},
+		{Line: 3, To: bob, Amount: big.NewInt(250)},
+	}
+	if err := checkAirdropJournal(changedAmount, done, format); err == nil || !strings.Contains(err.Error(), "line 3:") {
+		t.Fatalf("expected the changed amount to be reported, got: %v", err)
+	}
+
+	removed := []gambit.Transfer{{Line: 2, To: alice, Amount: big.NewInt(100)}}
+	if err := checkAirdropJournal(removed, done, format); err == nil || !strings.Contains(err.Error(), "line 3: the journal records a transfer of 200") {
+		t.Fatalf("expected the removed row to be reported, got: %v", err)
+	}
+
+	// Reverted transfers are removed from the journal so that they are retried.
+	if err := removeFromAirdropJournal(journal, []int{3}); err != nil {
+		t.Fatalf("failed to remove from journal: %v", err)
+	}
+	remaining, remainingErr := readAirdropJournal(journal)
+	if remainingErr != nil {
+		t.Fatalf("failed to read journal: %v", remainingErr)
+	}
+	if len(remaining) != 1 || remaining[2].To != alice {
+		t.Fatalf("expected only line 2 to remain in the journal, got %+v", remaining)
+	}
+}
+
+func TestReadAirdropJournalRejectsMalformedEntries(t *testing.T) {
+	journal := filepath.Join(t.TempDir(), "airdrop.csv.journal")
+	if err := os.WriteFile(journal, []byte("2,0x00000000000000000000000000000000000000aa,1.5,0x01\n"), 0644); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+	if _, err := readAirdropJournal(journal); err == nil {
+		t.Fatalf("expected a malformed journal entry to be rejected")
+	}
+}
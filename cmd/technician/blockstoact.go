@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/BlockInspector"
+	"github.com/PermissionlessGames/degen-casino/inspector"
+)
+
+func CreateRecommendBlocksToActCommand() *cobra.Command {
+	var rpc, contractAddressRaw string
+	var seconds, numBlocks, idleGap, maxDepth uint64
+	var percentile float64
+	var sampleDuration, sampleInterval time.Duration
+	var timeout uint
+	var contractAddress common.Address
+
+	cmd := &cobra.Command{
+		Use:   "recommend-blocks-to-act",
+		Short: "Recommend a BlocksToAct which gives players a given time to act",
+		Long: `Recommend a BlocksToAct which gives players a given time (--seconds) to act on a spin.
+
+DegenGambit measures the deadline for accepting a spin in blocks (arbBlockNumber), but players think in
+seconds. This command measures block production on the chain:
+  - from the timestamps of the last --blocks headers: blocks per second, the spread of the intervals
+    between blocks, and idle gaps of at least --idle-gap seconds,
+  - live, by sampling blockNumbers on the BlockInspector contract every --sample-interval for
+    --sample-duration: how fast arbBlockNumber and block.number advance, and how long arbBlockNumber
+    stalls.
+
+The recommendation is the number of blocks produced in a --seconds window at the --percentile of all
+windows in the sampled headers, so that players get at least --seconds to act in that fraction of
+cases (fewer blocks means more time). It is capped by the arbBlockHash availability window, as probed
+by probe-window, since spins cannot be accepted once their block hash is unavailable.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if seconds == 0 {
+				return fmt.Errorf("--seconds must be positive")
+			}
+			if numBlocks < 2 {
+				return fmt.Errorf("--blocks must be at least 2")
+			}
+			if percentile <= 0 || percentile > 1 {
+				return fmt.Errorf("--percentile must be greater than 0 and at most 1")
+			}
+			if sampleDuration > 0 && sampleInterval <= 0 {
+				return fmt.Errorf("--sample-interval must be positive")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := BlockInspector.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := BlockInspector.NewBlockInspector(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second+sampleDuration)
+			defer cancel()
+
+			head, headErr := client.BlockNumber(ctx)
+			if headErr != nil {
+				return headErr
+			}
+			from := uint64(0)
+			if head+1 > numBlocks {
+				from = head + 1 - numBlocks
+			}
+
+			blocks, blocksErr := inspector.ReadBlockTimes(ctx, client.Client(), from, head)
+			if blocksErr != nil {
+				return blocksErr
+			}
+			cadence := inspector.MeasureCadence(blocks, idleGap)
+			cmd.Printf("Headers of blocks %d-%d (%d seconds):\n", from, head, cadence.Span)
+			cmd.Printf("  Blocks per second: %.3f\n", cadence.BlocksPerSecond)
+			cmd.Printf("  Seconds between blocks: mean %.3f, standard deviation %.3f\n", cadence.MeanInterval, cadence.IntervalStdDev)
+			cmd.Printf("  Idle gaps of at least %d seconds: %d (longest: %d seconds)\n", idleGap, cadence.IdleGaps, cadence.LongestGap)
+
+			window, probeErr := inspector.ProbeHashWindow(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(head)}, contract, maxDepth)
+			if probeErr != nil {
+				return probeErr
+			}
+			if !window.ArbSys {
+				return fmt.Errorf("ArbSys is not available on this chain, so DegenGambit cannot be used here")
+			}
+
+			if sampleDuration > 0 {
+				samples, sampleErr := inspector.SampleBlockNumbers(ctx, contract, sampleDuration, sampleInterval)
+				if sampleErr != nil {
+					return sampleErr
+				}
+				live := inspector.MeasureLive(samples)
+				cmd.Printf("Live sample of blockNumbers over %s (%d readings):\n", live.Duration.Round(time.Second), len(samples))
+				cmd.Printf("  arbBlockNumber advanced %d blocks (%.3f per second), longest stall %s\n", live.ArbBlocks, live.ArbBlocksPerSecond(), live.LongestStall.Round(time.Millisecond))
+				cmd.Printf("  block.number advanced %d blocks\n", live.ParentBlocks)
+				cmd.Printf("  arbBlockNumber - block.number: between %d and %d\n", live.MinDivergence, live.MaxDivergence)
+			}
+
+			hashWindow := describeDepth(window.ArbBlockHashDepth, window.ArbBlockHashUnbounded)
+			cmd.Printf("arbBlockHash: %s\n\n", hashWindow)
+
+			windowBlocks, windowErr := inspector.BlocksInWindow(blocks, seconds, percentile)
+			if windowErr != nil {
+				return fmt.Errorf("%v: increase --blocks or decrease --seconds", windowErr)
+			}
+			recommended := uint64(windowBlocks)
+			if recommended == 0 {
+				recommended = 1
+			}
+
+			cmd.Printf("At the %g percentile, %d blocks are produced in %d seconds.\n", 100*percentile, windowBlocks, seconds)
+			if window.ArbBlockHashDepth > 0 && recommended > window.ArbBlockHashDepth {
+				cmd.Printf("Recommended BlocksToAct: %d (capped by the arbBlockHash window; players may get less than %d seconds when blocks are produced quickly)\n", window.ArbBlockHashDepth, seconds)
+				return nil
+			}
+			cmd.Printf("Recommended BlocksToAct: %d\n", recommended)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the BlockInspector contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API (the live sample's duration is added to it)")
+	cmd.Flags().Uint64Var(&seconds, "seconds", 60, "Number of seconds players should have to act on a spin")
+	cmd.Flags().Uint64Var(&numBlocks, "blocks", 10000, "Number of recent block headers to measure")
+	cmd.Flags().Float64Var(&percentile, "percentile", 0.99, "Fraction of windows in which players should get at least --seconds to act")
+	cmd.Flags().Uint64Var(&idleGap, "idle-gap", 10, "Minimum gap (in seconds) between blocks to report as idle")
+	cmd.Flags().DurationVar(&sampleDuration, "sample-duration", 30*time.Second, "How long to sample blockNumbers live (0 to skip)")
+	cmd.Flags().DurationVar(&sampleInterval, "sample-interval", time.Second, "Interval at which to sample blockNumbers")
+	cmd.Flags().Uint64Var(&maxDepth, "max-depth", inspector.DefaultMaxDepth, "Maximum number of blocks back to search for available block hashes")
+
+	return cmd
+}
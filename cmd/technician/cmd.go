@@ -7,6 +7,7 @@ import (
 
 	"github.com/PermissionlessGames/degen-casino/bindings/BlockInspector"
 	"github.com/PermissionlessGames/degen-casino/bindings/DevDegenGambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
 	"github.com/PermissionlessGames/degen-casino/version"
 )
 
@@ -26,11 +27,52 @@ func CreateRootCommand() *cobra.Command {
 
 	blockInspectorCmd := BlockInspector.CreateBlockInspectorCommand()
 	blockInspectorCmd.Use = "block-inspector"
+	signer.WrapTransactionCommands(blockInspectorCmd)
+
+	blockInspectorToolsGroup := &cobra.Group{
+		ID: "tools", Title: "Degen Casino tools",
+	}
+	blockInspectorCmd.AddGroup(blockInspectorToolsGroup)
+
+	probeWindowCmd := CreateProbeWindowCommand()
+	probeWindowCmd.GroupID = blockInspectorToolsGroup.ID
+	blockInspectorCmd.AddCommand(probeWindowCmd)
+
+	recommendBlocksToActCmd := CreateRecommendBlocksToActCommand()
+	recommendBlocksToActCmd.GroupID = blockInspectorToolsGroup.ID
+	blockInspectorCmd.AddCommand(recommendBlocksToActCmd)
 
 	devGambitCmd := DevDegenGambit.CreateDevDegenGambitCommand()
 	devGambitCmd.Use = "dev-gambit"
+	signer.WrapTransactionCommands(devGambitCmd)
+
+	devGambitToolsGroup := &cobra.Group{
+		ID: "tools", Title: "Degen Casino tools",
+	}
+	devGambitCmd.AddGroup(devGambitToolsGroup)
+
+	scenarioCmd := CreateScenarioCommand()
+	scenarioCmd.GroupID = devGambitToolsGroup.ID
+	devGambitCmd.AddCommand(scenarioCmd)
+
+	forceCmd := CreateForceCommand()
+	forceCmd.GroupID = devGambitToolsGroup.ID
+	devGambitCmd.AddCommand(forceCmd)
+
+	snapshotCmd := CreateSnapshotCommand()
+	snapshotCmd.GroupID = devGambitToolsGroup.ID
+	devGambitCmd.AddCommand(snapshotCmd)
+
+	restoreCmd := CreateRestoreCommand()
+	restoreCmd.GroupID = devGambitToolsGroup.ID
+	devGambitCmd.AddCommand(restoreCmd)
+
+	loadtestCmd := CreateLoadtestCommand()
+	potReportCmd := CreatePotReportCommand()
+	entropyCmd := CreateEntropyCommand()
+	verifyBytecodeCmd := CreateVerifyBytecodeCommand()
 
-	rootCmd.AddCommand(blockInspectorCmd, devGambitCmd)
+	rootCmd.AddCommand(blockInspectorCmd, devGambitCmd, loadtestCmd, potReportCmd, entropyCmd, verifyBytecodeCmd)
 
 	// By default, cobra Command objects write to stderr. We have to forcibly set them to output to
 	// stdout.
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+func CreateEntropyCommand() *cobra.Command {
+	var symbolsRaw, blockHashRaw, seed string
+	var prize int
+	var boosted, noPrize, search bool
+	var maxAttempts uint64
+	var match gambit.OutcomeMatcher
+	var outcome [3]uint64
+	var blockHash common.Hash
+
+	cmd := &cobra.Command{
+		Use:   "entropy",
+		Short: "Generate spin entropies with a given outcome, without a chain",
+		Long: `Generate spin entropies with a given outcome, without a chain.
+
+The outcome is given as three symbols (--symbols, by name or number), as a prize (--prize), or as
+--no-prize. Use --boosted for the improved reels used by boosted spins. The reels are the ones the
+DegenGambit constructor initializes.
+
+By default, the command prints the entropy DevDegenGambit's generateEntropyFor*ReelOutcome methods
+would return for --symbols. With --search, it instead searches the hash outputs keccak256(seed || i)
+for an entropy with the outcome. With --block-hash, it searches for an account whose spin in the block
+with that hash (using block hash entropy, as in production) has the outcome, and prints the account's
+private key. Searches are deterministic for a given --seed.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			selected := 0
+			if symbolsRaw != "" {
+				var parseErr error
+				outcome, parseErr = parseSymbols("--symbols", symbolsRaw)
+				if parseErr != nil {
+					return parseErr
+				}
+				match = gambit.MatchSymbols(outcome[0], outcome[1], outcome[2])
+				selected++
+			}
+			if cmd.Flags().Changed("prize") {
+				if prize < 0 || prize >= gambit.NumPrizes {
+					return fmt.Errorf("--prize must be between 0 and %d", gambit.NumPrizes-1)
+				}
+				match = gambit.MatchPrize(prize)
+				selected++
+			}
+			if noPrize {
+				match = gambit.MatchNoPrize
+				selected++
+			}
+			if selected != 1 {
+				return fmt.Errorf("exactly one of --symbols, --prize, and --no-prize must be specified")
+			}
+
+			if blockHashRaw != "" {
+				hashBytes, decodeErr := hexutil.Decode(blockHashRaw)
+				if decodeErr != nil || len(hashBytes) != common.HashLength {
+					return fmt.Errorf("--block-hash is not a valid 32-byte hex string")
+				}
+				blockHash = common.BytesToHash(hashBytes)
+			}
+			if symbolsRaw == "" && !search && blockHashRaw == "" {
+				return fmt.Errorf("--prize and --no-prize require --search or --block-hash")
+			}
+			if search && blockHashRaw != "" {
+				return fmt.Errorf("only one of --search and --block-hash may be specified")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reels := gambit.DefaultReels(boosted)
+
+			switch {
+			case blockHashRaw != "":
+				result, searchErr := gambit.SearchPlayer(reels, blockHash, match, []byte(seed), maxAttempts)
+				if searchErr != nil {
+					return fmt.Errorf("%v in %d attempts", searchErr, result.Attempts)
+				}
+				cmd.Printf("Player: %s\n", result.Player.Hex())
+				cmd.Printf("Private key: %s\n", hexutil.Encode(crypto.FromECDSA(result.PrivateKey)))
+				cmd.Printf("Entropy: %s\n", result.Entropy.String())
+				cmd.Printf("Outcome: %s\n", describeOutcome(result.Left, result.Center, result.Right))
+				cmd.Printf("Attempts: %d\n", result.Attempts)
+
+			case search:
+				result, searchErr := gambit.SearchEntropy(reels, match, []byte(seed), maxAttempts)
+				if searchErr != nil {
+					return fmt.Errorf("%v in %d attempts", searchErr, result.Attempts)
+				}
+				cmd.Printf("Entropy: %s\n", result.Entropy.String())
+				cmd.Printf("Outcome: %s\n", describeOutcome(result.Left, result.Center, result.Right))
+				cmd.Printf("Attempts: %d\n", result.Attempts)
+
+			default:
+				entropy, entropyErr := reels.EntropyForOutcome(outcome[0], outcome[1], outcome[2])
+				if entropyErr != nil {
+					return entropyErr
+				}
+				cmd.Printf("Entropy: %s\n", entropy.String())
+				cmd.Printf("Outcome: %s\n", describeOutcome(outcome[0], outcome[1], outcome[2]))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&symbolsRaw, "symbols", "", "Comma-separated symbols (names or numbers) for the left, center, and right reels")
+	cmd.Flags().IntVar(&prize, "prize", 0, "Index (in prizes()) of the prize the outcome should win")
+	cmd.Flags().BoolVar(&noPrize, "no-prize", false, "Look for an outcome which does not win a prize")
+	cmd.Flags().BoolVar(&boosted, "boosted", false, "Use the improved reels used by boosted spins")
+	cmd.Flags().BoolVar(&search, "search", false, "Search hash outputs for an entropy with the outcome")
+	cmd.Flags().StringVar(&blockHashRaw, "block-hash", "", "Search for a player whose spin in the block with this hash has the outcome")
+	cmd.Flags().StringVar(&seed, "seed", "degen-casino", "Seed for the search")
+	cmd.Flags().Uint64Var(&maxAttempts, "max-attempts", 10000000, "Maximum number of candidates to try when searching")
+
+	return cmd
+}
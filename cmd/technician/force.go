@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DevDegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// describeOutcome describes a reel outcome by its symbol names and the prize it wins.
+func describeOutcome(left, center, right uint64) string {
+	description := fmt.Sprintf("%s | %s | %s", gambit.SymbolName(left), gambit.SymbolName(center), gambit.SymbolName(right))
+	if prizeIndex, won := gambit.PrizeIndex(left, center, right); won {
+		return fmt.Sprintf("%s (prize %d: %s)", description, prizeIndex, gambit.PrizeDescriptions[prizeIndex])
+	}
+	return fmt.Sprintf("%s (no prize)", description)
+}
+
+// parseSymbols parses a comma-separated list of the symbols on the left, center, and right reels.
+func parseSymbols(flag, value string) ([3]uint64, error) {
+	var outcome [3]uint64
+	symbols := strings.Split(value, ",")
+	if len(symbols) != 3 {
+		return outcome, fmt.Errorf("%s must list 3 symbols (got %d)", flag, len(symbols))
+	}
+	for i, symbol := range symbols {
+		parsed, parseErr := gambit.ParseSymbol(symbol)
+		if parseErr != nil {
+			return outcome, fmt.Errorf("invalid %s: %v", flag, parseErr)
+		}
+		outcome[i] = parsed
+	}
+	return outcome, nil
+}
+
+func CreateForceCommand() *cobra.Command {
+	var rpc, contractAddressRaw, playerRaw, symbolsRaw string
+	var prize int
+	var boosted bool
+	var timeout uint
+	var contractAddress, player common.Address
+	var outcome [3]uint64
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "force",
+		Short: "Force the outcome of a player's next spin on a DevDegenGambit contract",
+		Long: `Force the outcome of a player's next spin on a DevDegenGambit contract.
+
+The outcome is given either as three symbols (--symbols, by name or number, e.g. "Red 7,Red 7,Red 7") or
+as a prize (--prize, the index of the prize in prizes()), in which case a triple which wins that prize
+is chosen. The command switches the contract to player-set entropy (setEntropySource(false)) if
+necessary, sets the player's entropy with setEntropyFromOutcomes, and verifies the outcome the
+contract now reports for the player.
+
+Use --boosted if the player's next spin will be boosted, since boosted spins use the improved reels.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if playerRaw == "" {
+				return fmt.Errorf("--player not specified")
+			} else if !common.IsHexAddress(playerRaw) {
+				return fmt.Errorf("--player is not a valid Ethereum address")
+			}
+			player = common.HexToAddress(playerRaw)
+
+			prizeSet := cmd.Flags().Changed("prize")
+			if (symbolsRaw == "") == !prizeSet {
+				return fmt.Errorf("exactly one of --symbols and --prize must be specified")
+			}
+			if prizeSet {
+				if prize < 0 || prize >= gambit.NumPrizes {
+					return fmt.Errorf("--prize must be between 0 and %d", gambit.NumPrizes-1)
+				}
+				outcome = gambit.ExampleOutcomes[prize]
+			} else {
+				var parseErr error
+				outcome, parseErr = parseSymbols("--symbols", symbolsRaw)
+				if parseErr != nil {
+					return parseErr
+				}
+			}
+
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DevDegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DevDegenGambit.NewDevDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			forceSigner, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			chainIDCtx, cancelChainIDCtx := DevDegenGambit.NewChainContext(timeout)
+			defer cancelChainIDCtx()
+			chainID, chainIDErr := client.ChainID(chainIDCtx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+
+			manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(forceSigner, chainID), chainID, "")
+			if managerErr != nil {
+				return managerErr
+			}
+
+			transact := func(description string, transactFunc txmanager.TransactFunc) error {
+				tx, txErr := manager.Transact(ctx, transactFunc)
+				if txErr != nil {
+					if reason := gambit.RevertReason(txErr); reason != "" {
+						return fmt.Errorf("%s reverted: %s", description, reason)
+					}
+					return fmt.Errorf("failed to submit %s: %v", description, txErr)
+				}
+				receipt, receiptErr := bind.WaitMined(ctx, client, tx)
+				if receiptErr != nil {
+					return receiptErr
+				}
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					return fmt.Errorf("%s failed: %s", description, tx.Hash().Hex())
+				}
+				cmd.Printf("%s: %s\n", description, tx.Hash().Hex())
+				return nil
+			}
+
+			callOpts := &bind.CallOpts{Context: ctx}
+
+			entropyIsHash, entropyIsHashErr := contract.EntropyIsHash(callOpts)
+			if entropyIsHashErr != nil {
+				return fmt.Errorf("failed to get EntropyIsHash: %v", entropyIsHashErr)
+			}
+			if entropyIsHash {
+				if err := transact("setEntropySource(false)", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+					return contract.SetEntropySource(opts, false)
+				}); err != nil {
+					return err
+				}
+			}
+
+			left, center, right := new(big.Int).SetUint64(outcome[0]), new(big.Int).SetUint64(outcome[1]), new(big.Int).SetUint64(outcome[2])
+			if err := transact("setEntropyFromOutcomes", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetEntropyFromOutcomes(opts, left, center, right, player, boosted)
+			}); err != nil {
+				return err
+			}
+
+			// The entropy is checked with outcome rather than inspectOutcome, which reverts unless the player
+			// has a spin whose deadline has not passed.
+			entropy, entropyErr := contract.EntropyForPlayer(callOpts, player)
+			if entropyErr != nil {
+				return fmt.Errorf("failed to get EntropyForPlayer: %v", entropyErr)
+			}
+			sampled, outcomeErr := contract.Outcome(callOpts, entropy, boosted)
+			if outcomeErr != nil {
+				return fmt.Errorf("failed to compute outcome: %v", outcomeErr)
+			}
+			got := [3]uint64{sampled.Left.Uint64(), sampled.Center.Uint64(), sampled.Right.Uint64()}
+
+			if got != outcome {
+				return fmt.Errorf("contract reports %s, expected %s", describeOutcome(got[0], got[1], got[2]), describeOutcome(outcome[0], outcome[1], outcome[2]))
+			}
+
+			reels := "unmodified"
+			if boosted {
+				reels = "improved"
+			}
+			cmd.Printf("Next spin of %s (%s reels): %s\n", player.Hex(), reels, describeOutcome(outcome[0], outcome[1], outcome[2]))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DevDegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&playerRaw, "player", "", "Address of the player whose next spin to force")
+	cmd.Flags().StringVar(&symbolsRaw, "symbols", "", "Comma-separated symbols (names or numbers) for the left, center, and right reels")
+	cmd.Flags().IntVar(&prize, "prize", 0, "Index (in prizes()) of the prize the spin should win")
+	cmd.Flags().BoolVar(&boosted, "boosted", false, "Force the outcome on the improved reels used by boosted spins")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
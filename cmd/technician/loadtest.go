@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/loadtest"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+func CreateLoadtestCommand() *cobra.Command {
+	var rpc, contractAddressRaw, playersMnemonicFile, playersMnemonicEnv, playersDerivationPath, fundRaw string
+	var numPlayers, cycles int
+	var playersStartIndex uint32
+	var duration, thinkTime time.Duration
+	var boostRate, respinRate float64
+	var timeout uint
+	var contractAddress common.Address
+	var fundAmount *big.Int
+	funderFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "loadtest",
+		Short: "Run a fleet of players against a DegenGambit contract",
+		Long: `Run a fleet of players against a DegenGambit contract.
+
+Player accounts are derived from a BIP-39 mnemonic (--players-mnemonic-file or --players-mnemonic-env).
+If no mnemonic is provided, a new one is generated and printed so that funds sent to the players can be
+recovered. If --fund is specified, each player's native token balance is topped up to that amount from
+the funder account (selected with the usual signer flags, e.g. --keyfile) before the load test starts.
+
+Each player then repeatedly spins, optionally respins (--respin-rate), and accepts, waiting --think-time
+between actions. At the end, the command reports transaction latencies, revert reasons (e.g. WaitForTick,
+DeadlineExceeded), and throughput.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if numPlayers <= 0 {
+				return fmt.Errorf("--players must be positive")
+			}
+
+			if playersMnemonicFile != "" && playersMnemonicEnv != "" {
+				return fmt.Errorf("only one of --players-mnemonic-file and --players-mnemonic-env may be specified")
+			}
+
+			if fundRaw != "" {
+				fundAmount = new(big.Int)
+				_, ok := fundAmount.SetString(fundRaw, 0)
+				if !ok {
+					return fmt.Errorf("--fund is not a valid big integer")
+				}
+			}
+
+			return funderFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			var mnemonic string
+			switch {
+			case playersMnemonicFile != "":
+				contents, readErr := os.ReadFile(playersMnemonicFile)
+				if readErr != nil {
+					return readErr
+				}
+				mnemonic = string(contents)
+			case playersMnemonicEnv != "":
+				mnemonic = os.Getenv(playersMnemonicEnv)
+				if mnemonic == "" {
+					return fmt.Errorf("environment variable %s is not set", playersMnemonicEnv)
+				}
+			default:
+				entropy, entropyErr := bip39.NewEntropy(128)
+				if entropyErr != nil {
+					return entropyErr
+				}
+				generatedMnemonic, mnemonicErr := bip39.NewMnemonic(entropy)
+				if mnemonicErr != nil {
+					return mnemonicErr
+				}
+				mnemonic = generatedMnemonic
+				cmd.Printf("Generated player mnemonic (keep it to recover player funds): %s\n", mnemonic)
+			}
+
+			players := make([]signer.Signer, numPlayers)
+			playerAddresses := make([]common.Address, numPlayers)
+			for i := 0; i < numPlayers; i++ {
+				player, playerErr := signer.FromMnemonic(mnemonic, "", playersDerivationPath, playersStartIndex+uint32(i))
+				if playerErr != nil {
+					return playerErr
+				}
+				players[i] = player
+				playerAddresses[i] = player.Address()
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			if fundAmount != nil {
+				funder, funderErr := funderFlags.Signer()
+				if funderErr != nil {
+					return funderErr
+				}
+
+				chainIDCtx, cancelChainIDCtx := DegenGambit.NewChainContext(timeout)
+				defer cancelChainIDCtx()
+				chainID, chainIDErr := client.ChainID(chainIDCtx)
+				if chainIDErr != nil {
+					return chainIDErr
+				}
+
+				funderManager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(funder, chainID), chainID, "")
+				if managerErr != nil {
+					return managerErr
+				}
+
+				cmd.Printf("Funding %d players from %s...\n", numPlayers, funder.Address().Hex())
+				if err := loadtest.Fund(ctx, client, funderManager, playerAddresses, fundAmount); err != nil {
+					return err
+				}
+			}
+
+			config := loadtest.Config{
+				Contract:   contractAddress,
+				Players:    players,
+				Cycles:     cycles,
+				Duration:   duration,
+				ThinkTime:  thinkTime,
+				BoostRate:  boostRate,
+				RespinRate: respinRate,
+			}
+
+			cmd.Printf("Running load test with %d players...\n", numPlayers)
+			stats, runErr := loadtest.Run(ctx, client, config)
+			if runErr != nil {
+				return runErr
+			}
+
+			stats.Report(cmd.OutOrStdout())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract to load test")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().IntVar(&numPlayers, "players", 10, "Number of players to run")
+	cmd.Flags().StringVar(&playersMnemonicFile, "players-mnemonic-file", "", "Path to a file containing the BIP-39 mnemonic to derive player accounts from")
+	cmd.Flags().StringVar(&playersMnemonicEnv, "players-mnemonic-env", "", "Name of an environment variable containing the BIP-39 mnemonic to derive player accounts from")
+	cmd.Flags().StringVar(&playersDerivationPath, "players-derivation-path", signer.DefaultDerivationPath, "Base derivation path for player accounts")
+	cmd.Flags().Uint32Var(&playersStartIndex, "players-start-index", 0, "Index of the first player account to derive")
+	cmd.Flags().StringVar(&fundRaw, "fund", "", "Native token balance (in wei) to top each player up to before the load test (optional)")
+	cmd.Flags().IntVar(&cycles, "cycles", 0, "Number of spin/accept cycles per player (0 means play until --duration elapses)")
+	cmd.Flags().DurationVar(&duration, "duration", 5*time.Minute, "Maximum duration of the load test (0 means no limit)")
+	cmd.Flags().DurationVar(&thinkTime, "think-time", time.Second, "Amount of time each player waits between actions")
+	cmd.Flags().Float64Var(&boostRate, "boost-rate", 0, "Fraction of spins (between 0 and 1) that players boost if they hold GAMBIT")
+	cmd.Flags().Float64Var(&respinRate, "respin-rate", 0.25, "Fraction of spins (between 0 and 1) that players respin before accepting")
+	funderFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// formatWei formats an amount of wei, as a float64, in native token units.
+func formatWei(amount float64) string {
+	return fmt.Sprintf("%.6f", amount/math.Pow10(gambit.Decimals))
+}
+
+// formatOdds formats a probability as a percentage and as "1 in N".
+func formatOdds(p float64) string {
+	if p <= 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.6f%% (1 in %.0f)", 100*p, 1/p)
+}
+
+func CreatePotReportCommand() *cobra.Command {
+	var rpc, contractAddressRaw, balanceRaw, thresholdRaw string
+	var spins, trials int
+	var respinFraction, boostFraction float64
+	var seed int64
+	var timeout uint
+	var contractAddress common.Address
+	var balanceOverride, threshold *big.Int
+
+	cmd := &cobra.Command{
+		Use:   "pot-report",
+		Short: "Report on the solvency of a DegenGambit pot and how often its payout caps bind",
+		Long: `Report on the solvency of a DegenGambit pot and how often its payout caps bind.
+
+The report combines the contract's balance, CostToSpin, CostToRespin and the exact prize probabilities
+(computed from the reels' cumulative mass functions) to show:
+  - the expected change in the pot per spin at the current balance, and the balance at which the
+    expected change is zero,
+  - the balances below which the minor prizes (50*CostToSpin and 100*CostToSpin) are limited to
+    balance>>6 and balance>>4,
+  - from a Monte Carlo simulation of --spins spins (--trials times), the probability that the pot falls
+    below --threshold, the distribution of the final pot, and how often each capped prize was limited.
+
+The model assumes each spin is accepted before the next one is made, that --respin-fraction of spins
+are respins paying CostToRespin, and that --boost-fraction of spins are boosted.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if balanceRaw != "" {
+				balanceOverride = new(big.Int)
+				if _, ok := balanceOverride.SetString(balanceRaw, 0); !ok {
+					return fmt.Errorf("--balance is not a valid big integer")
+				}
+			}
+			if thresholdRaw != "" {
+				threshold = new(big.Int)
+				if _, ok := threshold.SetString(thresholdRaw, 0); !ok {
+					return fmt.Errorf("--threshold is not a valid big integer")
+				}
+			}
+
+			if spins <= 0 {
+				return fmt.Errorf("--spins must be positive")
+			}
+			if trials <= 0 {
+				return fmt.Errorf("--trials must be positive")
+			}
+			if respinFraction < 0 || respinFraction > 1 {
+				return fmt.Errorf("--respin-fraction must be between 0 and 1")
+			}
+			if boostFraction < 0 || boostFraction > 1 {
+				return fmt.Errorf("--boost-fraction must be between 0 and 1")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, cancel := DegenGambit.NewChainContext(timeout)
+			defer cancel()
+			callOpts := &bind.CallOpts{Context: ctx}
+
+			balance := balanceOverride
+			if balance == nil {
+				var balanceErr error
+				balance, balanceErr = client.BalanceAt(ctx, contractAddress, nil)
+				if balanceErr != nil {
+					return balanceErr
+				}
+			}
+
+			costToSpin, costToSpinErr := contract.CostToSpin(callOpts)
+			if costToSpinErr != nil {
+				return costToSpinErr
+			}
+			costToRespin, costToRespinErr := contract.CostToRespin(callOpts)
+			if costToRespinErr != nil {
+				return costToRespinErr
+			}
+
+			unmodifiedReels, unmodifiedErr := gambit.ReadReels(callOpts, contract, false)
+			if unmodifiedErr != nil {
+				return unmodifiedErr
+			}
+			improvedReels, improvedErr := gambit.ReadReels(callOpts, contract, true)
+			if improvedErr != nil {
+				return improvedErr
+			}
+			unboostedOdds := gambit.ComputeOdds(unmodifiedReels)
+			boostedOdds := gambit.ComputeOdds(improvedReels)
+
+			model := gambit.NewPotModel(costToSpin, costToRespin, unboostedOdds, boostedOdds)
+			model.RespinFraction = respinFraction
+			model.BoostFraction = boostFraction
+
+			pot, _ := new(big.Float).SetInt(balance).Float64()
+			thresholdValue := pot / 2
+			if threshold != nil {
+				thresholdValue, _ = new(big.Float).SetInt(threshold).Float64()
+			}
+
+			cmd.Printf("Pot: %s\n", gambit.FormatUnits(balance, gambit.Decimals))
+			cmd.Printf("CostToSpin: %s, CostToRespin: %s\n", gambit.FormatUnits(costToSpin, gambit.Decimals), gambit.FormatUnits(costToRespin, gambit.Decimals))
+			cmd.Println()
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Prize\tDescription\tOdds (unboosted)\tOdds (boosted)\tPays now")
+			unboosted, unboostedNone := unboostedOdds.Float()
+			boosted, boostedNone := boostedOdds.Float()
+			for i := 0; i < gambit.NumPrizes; i++ {
+				pays := "GAMBIT"
+				if amount, limited := model.PrizeAmount(i, pot); i >= 2 {
+					pays = formatWei(amount)
+					if limited {
+						pays += " (limited by balance)"
+					}
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i, gambit.PrizeDescriptions[i], formatOdds(unboosted[i]), formatOdds(boosted[i]), pays)
+			}
+			fmt.Fprintf(w, "-\tNo prize\t%s\t%s\t-\n", formatOdds(unboostedNone), formatOdds(boostedNone))
+			if flushErr := w.Flush(); flushErr != nil {
+				return flushErr
+			}
+			cmd.Println()
+
+			cmd.Printf("Expected pot drift per spin: %s (paid in %s, paid out %s)\n", formatWei(model.ExpectedDrift(pot)), formatWei(model.ExpectedCost()), formatWei(model.ExpectedPayout(pot)))
+			if equilibrium := model.EquilibriumBalance(); math.IsInf(equilibrium, 1) {
+				cmd.Println("Equilibrium pot: none (the pot is expected to grow without bound)")
+			} else {
+				cmd.Printf("Equilibrium pot: %s\n", formatWei(equilibrium))
+			}
+			for _, prizeIndex := range []int{2, 3} {
+				cmd.Printf("Prize %d is limited by the balance while the pot is below %s\n", prizeIndex, formatWei(model.LimitBalance(prizeIndex)))
+			}
+			cmd.Println()
+
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+			rng := rand.New(rand.NewSource(seed))
+			simulation := model.Simulate(pot, spins, trials, thresholdValue, rng)
+			cmd.Printf("Simulation of %d spins (%d trials):\n", spins, trials)
+			cmd.Printf("  Probability that the pot falls below %s: %.4f%%\n", formatWei(thresholdValue), 100*simulation.BelowThreshold)
+			cmd.Printf("  Final pot: mean %s, 5th percentile %s, median %s, 95th percentile %s\n", formatWei(simulation.MeanFinal), formatWei(simulation.P5Final), formatWei(simulation.P50Final), formatWei(simulation.P95Final))
+			for _, prizeIndex := range []int{2, 3} {
+				limitedFraction := 0.0
+				if simulation.Wins[prizeIndex] > 0 {
+					limitedFraction = float64(simulation.Limited[prizeIndex]) / float64(simulation.Wins[prizeIndex])
+				}
+				cmd.Printf("  Prize %d: won %d times, limited by the balance in %.2f%% of wins\n", prizeIndex, simulation.Wins[prizeIndex], 100*limitedFraction)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&balanceRaw, "balance", "", "Pot (in wei) to start from instead of the contract's current balance")
+	cmd.Flags().StringVar(&thresholdRaw, "threshold", "", "Pot (in wei) whose crossing is reported on (default: half the starting pot)")
+	cmd.Flags().IntVar(&spins, "spins", 1000, "Number of spins to simulate")
+	cmd.Flags().IntVar(&trials, "trials", 10000, "Number of simulations to run")
+	cmd.Flags().Float64Var(&respinFraction, "respin-fraction", 0, "Fraction of spins which are respins (paying CostToRespin)")
+	cmd.Flags().Float64Var(&boostFraction, "boost-fraction", 0, "Fraction of spins which are boosted")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Seed for the simulation's random number generator (default: random)")
+
+	return cmd
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/BlockInspector"
+	"github.com/PermissionlessGames/degen-casino/inspector"
+)
+
+// describeDepth describes a probed hash availability depth.
+func describeDepth(depth uint64, unbounded bool) string {
+	if depth == 0 {
+		return "not available"
+	}
+	if unbounded {
+		return fmt.Sprintf("available for at least the last %d blocks (search limit reached)", depth)
+	}
+	return fmt.Sprintf("available for the last %d blocks", depth)
+}
+
+func CreateProbeWindowCommand() *cobra.Command {
+	var rpc, contractAddressRaw string
+	var atBlock, maxDepth uint64
+	var timeout uint
+	var contractAddress common.Address
+
+	cmd := &cobra.Command{
+		Use:   "probe-window",
+		Short: "Measure how far back block hashes are available on a chain",
+		Long: `Measure how far back block hashes are available on a chain, using a deployed BlockInspector contract.
+
+DegenGambit derives the entropy of a spin from ArbSys.arbBlockHash(LastSpinBlock), so a spin can only be
+accepted while that hash is available. This command binary-searches for the oldest block whose hash is
+still returned by arbBlockHash (and by the blockhash opcode, via hash), and reports how block.number and
+arbBlockNumber (from blockNumbers) diverge. BlocksToAct must not exceed the arbBlockHash window.
+
+All calls are made against the state at a single block (--block, default: the latest block).`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			if maxDepth == 0 {
+				return fmt.Errorf("--max-depth must be positive")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := BlockInspector.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := BlockInspector.NewBlockInspector(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, cancel := BlockInspector.NewChainContext(timeout)
+			defer cancel()
+
+			if atBlock == 0 {
+				head, headErr := client.BlockNumber(ctx)
+				if headErr != nil {
+					return headErr
+				}
+				atBlock = head
+			}
+
+			callOpts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(atBlock)}
+			window, probeErr := inspector.ProbeHashWindow(callOpts, contract, maxDepth)
+			if probeErr != nil {
+				return probeErr
+			}
+
+			cmd.Printf("Probed at block %d (%d calls)\n\n", atBlock, window.Probes)
+
+			if !window.ArbSys {
+				cmd.Println("ArbSys is not available on this chain: blockNumbers and arbBlockHash revert, so DegenGambit cannot be used here.")
+				cmd.Printf("block.number: %d\n", window.At.BlockNumber)
+				cmd.Printf("blockhash: %s\n", describeDepth(window.BlockhashDepth, window.BlockhashUnbounded))
+				return nil
+			}
+
+			cmd.Printf("block.number:   %d\n", window.At.BlockNumber)
+			cmd.Printf("arbBlockNumber: %d\n", window.At.ArbBlockNumber)
+			if divergence := window.At.Divergence(); divergence != 0 {
+				cmd.Printf("arbBlockNumber - block.number = %d. block.number follows the parent chain, so DegenGambit measures its deadlines in arbBlockNumber.\n", divergence)
+			} else {
+				cmd.Println("block.number and arbBlockNumber agree.")
+			}
+			cmd.Println()
+
+			cmd.Printf("arbBlockHash: %s\n", describeDepth(window.ArbBlockHashDepth, window.ArbBlockHashUnbounded))
+			cmd.Printf("blockhash:    %s\n", describeDepth(window.BlockhashDepth, window.BlockhashUnbounded))
+			cmd.Println()
+
+			if window.ArbBlockHashDepth == 0 {
+				cmd.Println("arbBlockHash returned no hashes, so no spin could be accepted on this chain.")
+			} else {
+				cmd.Printf("BlocksToAct must be at most %d so that spins can be accepted until their deadline.\n", window.ArbBlockHashDepth)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the BlockInspector contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().Uint64Var(&atBlock, "block", 0, "Block at which to probe (default: the latest block)")
+	cmd.Flags().Uint64Var(&maxDepth, "max-depth", inspector.DefaultMaxDepth, "Maximum number of blocks back to search")
+
+	return cmd
+}
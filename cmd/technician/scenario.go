@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DevDegenGambit"
+	"github.com/PermissionlessGames/degen-casino/scenario"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+func CreateScenarioCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scenario",
+		Short: "Run scripted scenarios against a DevDegenGambit contract",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(CreateScenarioRunCommand())
+
+	return cmd
+}
+
+func CreateScenarioRunCommand() *cobra.Command {
+	var rpc, contractAddressRaw string
+	var timeout uint
+	var contractAddress common.Address
+	var scenarios []*scenario.Scenario
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "run <file.yaml> [<file.yaml>...]",
+		Short: "Run scenarios described by YAML files against a DevDegenGambit contract",
+		Long: `Run scenarios described by YAML files against a DevDegenGambit contract.
+
+A scenario is a sequence of steps. Each step performs at most one action and then checks its
+expectations:
+
+  name: Minor pair with a major in the center pays prize 3
+  steps:
+    - fund: 1000000000000000000
+    - set-outcome: {reels: [2, 17, 2]}
+    - spin
+    - accept:
+      expect: {prize: 3, prize-amount: 1000000000000000}
+
+Actions: set-entropy, set-outcome, set-entropy-source, set-last-spin-block, set-last-spin-boosted,
+set-daily-streak, set-weekly-streak, mint-gambit, set-cost-to-spin, set-cost-to-respin,
+set-blocks-to-act, fund, spin, and accept. A step may set expect-revert to the custom error (e.g.
+WaitForTick) its action should revert with.
+
+Expectations: prize, no-prize, prize-amount, and reels (about the most recent accept), and has-prize,
+daily-streak-length, weekly-streak-length, gambit-balance, spin-cost (about a player), and pot.
+
+Transactions are sent from the account selected with the usual signer flags (e.g. --keyfile), which is
+the "self" player of the scenarios. The command reports PASS or FAIL for every step, and exits with an
+error if any scenario failed.`,
+		Args: cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			for _, path := range args {
+				loaded, loadErr := scenario.Load(path)
+				if loadErr != nil {
+					return fmt.Errorf("invalid scenario %s: %v", path, loadErr)
+				}
+				if loaded.Name == "" {
+					loaded.Name = path
+				}
+				scenarios = append(scenarios, loaded)
+			}
+
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DevDegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			runSigner, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			chainIDCtx, cancelChainIDCtx := DevDegenGambit.NewChainContext(timeout)
+			defer cancelChainIDCtx()
+			chainID, chainIDErr := client.ChainID(chainIDCtx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(runSigner, chainID), chainID, "")
+			if managerErr != nil {
+				return managerErr
+			}
+
+			runner, runnerErr := scenario.NewRunner(client, manager, contractAddress, cmd.OutOrStdout())
+			if runnerErr != nil {
+				return runnerErr
+			}
+
+			failed := 0
+			for i, s := range scenarios {
+				if i > 0 {
+					cmd.Println()
+				}
+				cmd.Printf("Scenario: %s\n", s.Name)
+				result, runErr := runner.Run(ctx, s)
+				if runErr != nil {
+					return runErr
+				}
+				if result.Passed() {
+					cmd.Printf("PASSED (%d steps)\n", len(result.Steps))
+				} else {
+					failed++
+					cmd.Printf("FAILED (%d of %d steps did not pass)\n", result.Failed(), len(result.Steps))
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d scenarios failed", failed, len(scenarios))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DevDegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DevDegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/snapshot"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+func CreateSnapshotCommand() *cobra.Command {
+	var rpc, contractAddressRaw, playersRaw, playersFile, outfile string
+	var atBlock uint64
+	var timeout uint
+	var contractAddress common.Address
+	var players []common.Address
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save the state of a DevDegenGambit contract to JSON",
+		Long: `Save the state of a DevDegenGambit contract to JSON.
+
+The snapshot holds the contract's configuration (BlocksToAct, CostToSpin, CostToRespin, EntropyIsHash)
+and, for each of the given players, LastSpinBlock, LastSpinBoosted, EntropyForPlayer, the streak fields,
+and their GAMBIT balance. All values are read from a single block (--block, default: the latest block).
+Use "restore" to bring the contract back to the state in a snapshot.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+
+			var playerAddresses []string
+			if playersRaw != "" {
+				playerAddresses = append(playerAddresses, strings.Split(playersRaw, ",")...)
+			}
+			if playersFile != "" {
+				contents, readErr := os.ReadFile(playersFile)
+				if readErr != nil {
+					return readErr
+				}
+				playerAddresses = append(playerAddresses, strings.Fields(string(contents))...)
+			}
+			for _, playerAddress := range playerAddresses {
+				playerAddress = strings.TrimSpace(playerAddress)
+				if playerAddress == "" {
+					continue
+				}
+				if !common.IsHexAddress(playerAddress) {
+					return fmt.Errorf("invalid player address: %s", playerAddress)
+				}
+				players = append(players, common.HexToAddress(playerAddress))
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DevDegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DevDegenGambit.NewDevDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, cancel := DevDegenGambit.NewChainContext(timeout)
+			defer cancel()
+
+			if atBlock == 0 {
+				head, headErr := client.BlockNumber(ctx)
+				if headErr != nil {
+					return headErr
+				}
+				atBlock = head
+			}
+
+			callOpts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(atBlock)}
+			taken, takeErr := snapshot.Take(callOpts, contractAddress, contract, players)
+			if takeErr != nil {
+				return takeErr
+			}
+
+			if outfile == "" {
+				return taken.Write(cmd.OutOrStdout())
+			}
+
+			file, createErr := os.Create(outfile)
+			if createErr != nil {
+				return createErr
+			}
+			defer file.Close()
+			if err := taken.Write(file); err != nil {
+				return err
+			}
+			cmd.Printf("Saved the state of %s (and %d players) at block %d to %s\n", contractAddress.Hex(), len(players), atBlock, outfile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DevDegenGambit contract")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVar(&playersRaw, "players", "", "Comma-separated addresses of the players whose state to save")
+	cmd.Flags().StringVar(&playersFile, "players-file", "", "Path to a file containing the addresses of the players whose state to save (one per line)")
+	cmd.Flags().Uint64Var(&atBlock, "block", 0, "Block at which to read the state (default: the latest block)")
+	cmd.Flags().StringVarP(&outfile, "output", "o", "", "Path to the file to write the snapshot to (default: stdout)")
+
+	return cmd
+}
+
+func CreateRestoreCommand() *cobra.Command {
+	var rpc, contractAddressRaw, infile string
+	var dryRun bool
+	var timeout uint
+	var saved *snapshot.Snapshot
+	signerFlags := &signer.Flags{}
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore the state of a DevDegenGambit contract from a snapshot",
+		Long: `Restore the state of a DevDegenGambit contract from a snapshot taken with "snapshot".
+
+The command compares the snapshot with the current state of the contract and calls the dev setters
+(setBlocksToAct, setCostToSpin, setLastSpinBlock, setDailyStreak, ...) for every value which differs.
+GAMBIT balances which are lower than in the snapshot are topped up with mintGambit; balances which are
+higher cannot be lowered, and are reported instead. LastSpinBlock is restored as an absolute block
+number, so a spin which was pending when the snapshot was taken may have expired after restoring.
+
+The snapshot is restored to the contract it was taken from unless --contract is specified. Use
+--dry-run to list the setter calls without sending them.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if infile == "" {
+				return fmt.Errorf("--file not specified")
+			}
+			var loadErr error
+			saved, loadErr = snapshot.Load(infile)
+			if loadErr != nil {
+				return loadErr
+			}
+
+			if contractAddressRaw != "" {
+				if !common.IsHexAddress(contractAddressRaw) {
+					return fmt.Errorf("--contract is not a valid Ethereum address")
+				}
+				saved.Contract = common.HexToAddress(contractAddressRaw)
+			}
+
+			return signerFlags.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DevDegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			contract, contractErr := DevDegenGambit.NewDevDegenGambit(saved.Contract, client)
+			if contractErr != nil {
+				return contractErr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			changes, warnings, planErr := snapshot.Plan(&bind.CallOpts{Context: ctx}, contract, saved)
+			if planErr != nil {
+				return planErr
+			}
+			for _, warning := range warnings {
+				cmd.Printf("Warning: %s\n", warning)
+			}
+			if len(changes) == 0 {
+				cmd.Printf("%s already matches the snapshot\n", saved.Contract.Hex())
+				return nil
+			}
+
+			if dryRun {
+				for _, change := range changes {
+					cmd.Println(change.Description)
+				}
+				return nil
+			}
+
+			restoreSigner, signerErr := signerFlags.Signer()
+			if signerErr != nil {
+				return signerErr
+			}
+
+			chainIDCtx, cancelChainIDCtx := DevDegenGambit.NewChainContext(timeout)
+			defer cancelChainIDCtx()
+			chainID, chainIDErr := client.ChainID(chainIDCtx)
+			if chainIDErr != nil {
+				return chainIDErr
+			}
+
+			manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(restoreSigner, chainID), chainID, "")
+			if managerErr != nil {
+				return managerErr
+			}
+
+			// The setters are submitted back to back and only then waited on, since they are independent.
+			transactions := make([]*types.Transaction, len(changes))
+			for i, change := range changes {
+				tx, txErr := manager.Transact(ctx, change.Transact)
+				if txErr != nil {
+					if reason := gambit.RevertReason(txErr); reason != "" {
+						return fmt.Errorf("%s reverted: %s", change.Description, reason)
+					}
+					return fmt.Errorf("failed to submit %s: %v", change.Description, txErr)
+				}
+				transactions[i] = tx
+			}
+
+			failed := 0
+			for i, tx := range transactions {
+				receipt, receiptErr := bind.WaitMined(ctx, client, tx)
+				if receiptErr != nil {
+					return receiptErr
+				}
+				status := "ok"
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					status = "FAILED"
+					failed++
+				}
+				cmd.Printf("%s: %s (%s)\n", changes[i].Description, tx.Hash().Hex(), status)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d setter calls failed", failed, len(changes))
+			}
+			cmd.Printf("Restored %s to the snapshot taken at block %d\n", saved.Contract.Hex(), saved.BlockNumber)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the DevDegenGambit contract to restore (default: the contract the snapshot was taken from)")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+	cmd.Flags().StringVarP(&infile, "file", "f", "", "Path to the snapshot to restore")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the setter calls needed to restore the snapshot without sending them")
+	signerFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
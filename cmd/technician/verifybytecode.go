@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/bytecode"
+)
+
+func CreateVerifyBytecodeCommand() *cobra.Command {
+	var rpc, contractAddressRaw string
+	var atBlock uint64
+	var timeout uint
+	var contractAddress common.Address
+
+	cmd := &cobra.Command{
+		Use:   "verify-bytecode",
+		Short: "Check that a contract runs the DegenGambit or DevDegenGambit code built into this tool",
+		Long: `Check that a contract runs the DegenGambit or DevDegenGambit code built into this tool.
+
+The command fetches the runtime bytecode at --contract and compares it to the runtime bytecode of the
+forge artifacts which the bindings were generated from. Bytes which hold immutable variables are
+ignored.
+
+The CBOR metadata at the end of the bytecode, which holds a hash of the contract's source and compiler
+settings, is compared separately: a contract whose code matches but whose metadata does not was built
+from different source (for example, with different comments) which compiles to the same code.
+
+The command fails unless the code matches one of the contracts.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+			contractAddress = common.HexToAddress(contractAddressRaw)
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, clientErr := DegenGambit.NewClient(rpc)
+			if clientErr != nil {
+				return clientErr
+			}
+
+			ctx, cancel := DegenGambit.NewChainContext(timeout)
+			defer cancel()
+
+			var blockNumber *big.Int
+			if atBlock != 0 {
+				blockNumber = new(big.Int).SetUint64(atBlock)
+			}
+
+			deployed, codeErr := client.CodeAt(ctx, contractAddress, blockNumber)
+			if codeErr != nil {
+				return fmt.Errorf("failed to get code at %s: %v", contractAddress.Hex(), codeErr)
+			}
+			if len(deployed) == 0 {
+				return fmt.Errorf("%s: %v", contractAddress.Hex(), bytecode.ErrNoCode)
+			}
+
+			cmd.Printf("Contract: %s\n", contractAddress.Hex())
+			cmd.Printf("Code: %d bytes, keccak256 %s\n", len(deployed), crypto.Keccak256Hash(deployed).Hex())
+
+			match, comparisons, identifyErr := bytecode.Identify(deployed)
+			if identifyErr != nil {
+				return identifyErr
+			}
+
+			for _, comparison := range comparisons {
+				status := "code matches, metadata matches"
+				if !comparison.CodeMatches {
+					status = fmt.Sprintf("differs at byte %d", comparison.FirstDifference)
+				} else if !comparison.MetadataMatches {
+					status = "code matches, metadata differs"
+				}
+				if immutables := comparison.Reference.ImmutableBytes(); immutables > 0 {
+					status = fmt.Sprintf("%s (%d immutable bytes ignored)", status, immutables)
+				}
+				cmd.Printf("%s: %s\n", comparison.Reference.Artifact.Name, status)
+			}
+
+			if match == nil {
+				return fmt.Errorf("%s does not run any version of DegenGambit built into this tool", contractAddress.Hex())
+			}
+
+			contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, client)
+			if contractErr != nil {
+				return contractErr
+			}
+			contractVersion, versionErr := contract.Version(&bind.CallOpts{Context: ctx, BlockNumber: blockNumber})
+			if versionErr != nil {
+				return fmt.Errorf("failed to get version: %v", versionErr)
+			}
+
+			cmd.Printf("%s runs %s (version %q)\n", contractAddress.Hex(), match.Reference.Artifact.Name, contractVersion)
+			if !match.MetadataMatches {
+				cmd.Println("Warning: the metadata hash differs, so the contract was not built from exactly the same source")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rpc, "rpc", "", "URL of the JSONRPC API to use")
+	cmd.Flags().StringVar(&contractAddressRaw, "contract", "", "Address of the contract to verify")
+	cmd.Flags().Uint64Var(&atBlock, "block", 0, "Block at which to read the code (default: the latest block)")
+	cmd.Flags().UintVar(&timeout, "timeout", 60, "Timeout (in seconds) for interactions with the JSONRPC API")
+
+	return cmd
+}
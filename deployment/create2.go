@@ -0,0 +1,97 @@
+package deployment
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/BlockInspector"
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+)
+
+// DeterministicDeployer is the address of the deterministic-deployment-proxy
+// (https://github.com/Arachnid/deterministic-deployment-proxy), which is deployed at the same address
+// on every chain it exists on. Calling it with salt || initCode deploys initCode with CREATE2, so a
+// contract's address depends only on the salt and its creation bytecode (including constructor
+// arguments), not on the deployer's account or nonce.
+var DeterministicDeployer = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+var ErrNoDeterministicDeployer error = errors.New("the deterministic-deployment-proxy is not deployed on this chain")
+
+// ParseSalt parses a CREATE2 salt. A hex string (0x-prefixed, at most 32 bytes) is used as is, left
+// padded with zeros. Any other string is hashed with keccak256, so that labels like "degen-gambit-v1"
+// can be used as salts.
+func ParseSalt(value string) (common.Hash, error) {
+	if value == "" {
+		return common.Hash{}, fmt.Errorf("salt is empty")
+	}
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		decoded, decodeErr := hexutil.Decode(value)
+		if decodeErr != nil {
+			return common.Hash{}, fmt.Errorf("invalid salt %s: %v", value, decodeErr)
+		}
+		if len(decoded) > common.HashLength {
+			return common.Hash{}, fmt.Errorf("invalid salt %s: longer than 32 bytes", value)
+		}
+		return common.BytesToHash(decoded), nil
+	}
+	return crypto.Keccak256Hash([]byte(value)), nil
+}
+
+// GambitInitCode returns the creation bytecode of DegenGambit with the given constructor arguments.
+func GambitInitCode(blocksToAct, costToSpin, costToRespin *big.Int) ([]byte, error) {
+	contractABI, abiErr := DegenGambit.DegenGambitMetaData.GetAbi()
+	if abiErr != nil {
+		return nil, abiErr
+	}
+	arguments, packErr := contractABI.Pack("", blocksToAct, costToSpin, costToRespin)
+	if packErr != nil {
+		return nil, fmt.Errorf("failed to pack constructor arguments: %v", packErr)
+	}
+	return append(common.FromHex(DegenGambit.DegenGambitMetaData.Bin), arguments...), nil
+}
+
+// BlockInspectorInitCode returns the creation bytecode of BlockInspector.
+func BlockInspectorInitCode() []byte {
+	return common.FromHex(BlockInspector.BlockInspectorMetaData.Bin)
+}
+
+// PredictAddress returns the address at which the deterministic-deployment-proxy deploys initCode with
+// the given salt.
+func PredictAddress(salt common.Hash, initCode []byte) common.Address {
+	return crypto.CreateAddress2(DeterministicDeployer, salt, crypto.Keccak256(initCode))
+}
+
+// Prediction holds the addresses at which a manifest with a salt deploys its contracts.
+type Prediction struct {
+	Salt        common.Hash    `json:"salt"`
+	DegenGambit common.Address `json:"degenGambit"`
+	// Zero if the manifest does not deploy a BlockInspector.
+	BlockInspector common.Address `json:"blockInspector,omitempty"`
+}
+
+// Predict returns the addresses at which the manifest's contracts will be deployed, without accessing
+// any chain. The manifest must have been validated and must have a salt.
+func Predict(manifest *Manifest) (Prediction, error) {
+	salt, hasSalt := manifest.SaltHash()
+	if !hasSalt {
+		return Prediction{}, fmt.Errorf("manifest has no salt, so its addresses depend on the deployer's nonce")
+	}
+
+	blocksToAct, costToSpin, costToRespin := manifest.Parameters()
+	gambitInitCode, initCodeErr := GambitInitCode(blocksToAct, costToSpin, costToRespin)
+	if initCodeErr != nil {
+		return Prediction{}, initCodeErr
+	}
+
+	prediction := Prediction{Salt: salt, DegenGambit: PredictAddress(salt, gambitInitCode)}
+	if manifest.BlockInspector {
+		prediction.BlockInspector = PredictAddress(salt, BlockInspectorInitCode())
+	}
+	return prediction, nil
+}
@@ -0,0 +1,368 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+	"github.com/PermissionlessGames/degen-casino/version"
+)
+
+// Interval at which the deployer checks whether a transaction has enough confirmations.
+const ConfirmationPollInterval = time.Second
+
+// ContractDeployment records the deployment of a single contract.
+type ContractDeployment struct {
+	Address         common.Address `json:"address"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	BlockNumber     uint64         `json:"blockNumber"`
+	GasUsed         uint64         `json:"gasUsed"`
+	// Whether the contract was found at its CREATE2 address, and so was not deployed again. Only the
+	// address is recorded in this case.
+	AlreadyDeployed bool `json:"alreadyDeployed,omitempty"`
+}
+
+// GambitDeployment records the deployment of a DegenGambit contract, along with the configuration
+// read back from the deployed contract.
+type GambitDeployment struct {
+	ContractDeployment
+	BlocksToAct  *big.Int `json:"blocksToAct"`
+	CostToSpin   *big.Int `json:"costToSpin"`
+	CostToRespin *big.Int `json:"costToRespin"`
+	Version      string   `json:"version"`
+}
+
+// Funding records the transfer which funded the pot after deployment.
+type Funding struct {
+	TransactionHash common.Hash `json:"transactionHash"`
+	BlockNumber     uint64      `json:"blockNumber"`
+	Value           *big.Int    `json:"value"`
+	// Native token balance of the DegenGambit contract after it was funded.
+	Pot *big.Int `json:"pot"`
+}
+
+// Artifact records a deployment. If a deployment fails part of the way through, the artifact records
+// the steps which completed, so that contracts which were deployed are not lost.
+type Artifact struct {
+	Name          string         `json:"name,omitempty"`
+	ChainID       uint64         `json:"chainId"`
+	Deployer      common.Address `json:"deployer"`
+	CasinoVersion string         `json:"casinoVersion"`
+	DeployedAt    time.Time      `json:"deployedAt"`
+	// Set if the contracts were deployed with CREATE2 through the deterministic-deployment-proxy.
+	DeterministicDeployer *common.Address     `json:"deterministicDeployer,omitempty"`
+	Salt                  *common.Hash        `json:"salt,omitempty"`
+	DegenGambit           *GambitDeployment   `json:"degenGambit,omitempty"`
+	BlockInspector        *ContractDeployment `json:"blockInspector,omitempty"`
+	Funding               *Funding            `json:"funding,omitempty"`
+}
+
+// Write writes the artifact to the given writer as indented JSON.
+func (a *Artifact) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(a)
+}
+
+// Save writes the artifact to the given file as indented JSON, creating its directory if necessary.
+func (a *Artifact) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	file, createErr := os.Create(path)
+	if createErr != nil {
+		return createErr
+	}
+	defer file.Close()
+	return a.Write(file)
+}
+
+// Deployer carries out the steps of a manifest.
+type Deployer struct {
+	// Whether to fund the pot of a DegenGambit contract which was already deployed at its predicted
+	// CREATE2 address. By default, only contracts deployed by this run are funded, so that running a
+	// manifest again does not fund the same pot twice.
+	FundExisting bool
+
+	client   *ethclient.Client
+	manager  *txmanager.Manager
+	manifest *Manifest
+	out      io.Writer
+}
+
+// NewDeployer creates a deployer which sends transactions through the given transaction manager.
+// Progress messages are written to out.
+func NewDeployer(client *ethclient.Client, manager *txmanager.Manager, manifest *Manifest, out io.Writer) *Deployer {
+	return &Deployer{client: client, manager: manager, manifest: manifest, out: out}
+}
+
+// Deploy deploys DegenGambit (and BlockInspector, if the manifest asks for it), checks the
+// configuration of the deployed DegenGambit contract against the manifest, and funds its pot (unless
+// the contract was already deployed and FundExisting is not set). The returned artifact is non-nil even
+// if a step fails, and records every step which completed.
+func (d *Deployer) Deploy(ctx context.Context) (*Artifact, error) {
+	chainID, chainIDErr := d.client.ChainID(ctx)
+	if chainIDErr != nil {
+		return nil, chainIDErr
+	}
+	if d.manifest.ChainID != 0 && chainID.Uint64() != d.manifest.ChainID {
+		return nil, fmt.Errorf("manifest is for chain %d, but the JSONRPC API serves chain %d", d.manifest.ChainID, chainID.Uint64())
+	}
+
+	artifact := &Artifact{
+		Name:          d.manifest.Name,
+		ChainID:       chainID.Uint64(),
+		Deployer:      d.manager.Address(),
+		CasinoVersion: version.DegenCasinoVersion,
+		DeployedAt:    time.Now().UTC(),
+	}
+
+	if salt, hasSalt := d.manifest.SaltHash(); hasSalt {
+		deployer := DeterministicDeployer
+		artifact.DeterministicDeployer = &deployer
+		artifact.Salt = &salt
+	}
+
+	blocksToAct, costToSpin, costToRespin := d.manifest.Parameters()
+	gambitInitCode, initCodeErr := GambitInitCode(blocksToAct, costToSpin, costToRespin)
+	if initCodeErr != nil {
+		return artifact, initCodeErr
+	}
+	gambitDeployment, gambitErr := d.deploy(ctx, "DegenGambit", gambitInitCode)
+	if gambitErr != nil {
+		return artifact, gambitErr
+	}
+	artifact.DegenGambit = &GambitDeployment{ContractDeployment: gambitDeployment}
+
+	if err := d.verifyGambit(ctx, artifact.DegenGambit); err != nil {
+		return artifact, err
+	}
+
+	if d.manifest.BlockInspector {
+		inspectorDeployment, inspectorErr := d.deploy(ctx, "BlockInspector", BlockInspectorInitCode())
+		if inspectorErr != nil {
+			return artifact, inspectorErr
+		}
+		artifact.BlockInspector = &inspectorDeployment
+	}
+
+	if amount := d.manifest.FundAmount(); amount != nil && artifact.DegenGambit.AlreadyDeployed && !d.FundExisting {
+		fmt.Fprintf(d.out, "Not funding the pot of DegenGambit at %s, which was already deployed\n", artifact.DegenGambit.Address.Hex())
+	} else if amount != nil {
+		funding, fundErr := d.fund(ctx, artifact.DegenGambit.Address, amount)
+		if fundErr != nil {
+			return artifact, fundErr
+		}
+		artifact.Funding = funding
+	}
+
+	return artifact, nil
+}
+
+// deploy deploys a contract from its creation bytecode and waits for the deployment to be confirmed.
+// If the manifest has a salt, the contract is deployed through the deterministic-deployment-proxy, and
+// if a contract is already deployed at the predicted address, it is recorded instead of redeployed.
+func (d *Deployer) deploy(ctx context.Context, name string, initCode []byte) (ContractDeployment, error) {
+	var transact txmanager.TransactFunc
+	var predicted common.Address
+	salt, hasSalt := d.manifest.SaltHash()
+	if hasSalt {
+		proxyCode, proxyCodeErr := d.client.CodeAt(ctx, DeterministicDeployer, nil)
+		if proxyCodeErr != nil {
+			return ContractDeployment{}, fmt.Errorf("failed to get code at %s: %v", DeterministicDeployer.Hex(), proxyCodeErr)
+		}
+		if len(proxyCode) == 0 {
+			return ContractDeployment{}, ErrNoDeterministicDeployer
+		}
+
+		predicted = PredictAddress(salt, initCode)
+		existingCode, existingCodeErr := d.client.CodeAt(ctx, predicted, nil)
+		if existingCodeErr != nil {
+			return ContractDeployment{}, fmt.Errorf("failed to get code at %s: %v", predicted.Hex(), existingCodeErr)
+		}
+		if len(existingCode) > 0 {
+			fmt.Fprintf(d.out, "%s is already deployed at %s\n", name, predicted.Hex())
+			return ContractDeployment{Address: predicted, AlreadyDeployed: true}, nil
+		}
+
+		proxy := bind.NewBoundContract(DeterministicDeployer, abi.ABI{}, d.client, d.client, d.client)
+		transact = func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return proxy.RawTransact(opts, append(salt.Bytes(), initCode...))
+		}
+	} else {
+		transact = func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			_, tx, _, err := bind.DeployContract(opts, abi.ABI{}, initCode, d.client)
+			return tx, err
+		}
+	}
+
+	tx, txErr := d.manager.Transact(ctx, transact)
+	if txErr != nil {
+		if reason := gambit.RevertReason(txErr); reason != "" {
+			return ContractDeployment{}, fmt.Errorf("deployment of %s reverted: %s", name, reason)
+		}
+		return ContractDeployment{}, fmt.Errorf("failed to deploy %s: %v", name, txErr)
+	}
+	fmt.Fprintf(d.out, "Deploying %s: %s\n", name, tx.Hash().Hex())
+
+	receipt, receiptErr := d.confirm(ctx, tx)
+	if receiptErr != nil {
+		return ContractDeployment{}, fmt.Errorf("failed to deploy %s: %v", name, receiptErr)
+	}
+
+	deployment := ContractDeployment{
+		Address:         receipt.ContractAddress,
+		TransactionHash: tx.Hash(),
+		BlockNumber:     receipt.BlockNumber.Uint64(),
+		GasUsed:         receipt.GasUsed,
+	}
+	if hasSalt {
+		// The contract is created by the proxy, so the receipt has no contract address. Check that the
+		// contract exists at the predicted address instead.
+		deployment.Address = predicted
+		code, codeErr := d.client.CodeAt(ctx, predicted, receipt.BlockNumber)
+		if codeErr != nil {
+			return deployment, fmt.Errorf("failed to get code at %s: %v", predicted.Hex(), codeErr)
+		}
+		if len(code) == 0 {
+			return deployment, fmt.Errorf("failed to deploy %s: no code at %s after transaction %s", name, predicted.Hex(), tx.Hash().Hex())
+		}
+	}
+	fmt.Fprintf(d.out, "Deployed %s at %s (block %d)\n", name, deployment.Address.Hex(), deployment.BlockNumber)
+	return deployment, nil
+}
+
+// verifyGambit reads the configuration of the deployed DegenGambit contract into the deployment
+// record and checks it against the manifest.
+func (d *Deployer) verifyGambit(ctx context.Context, deployment *GambitDeployment) error {
+	contract, contractErr := DegenGambit.NewDegenGambit(deployment.Address, d.client)
+	if contractErr != nil {
+		return contractErr
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	var err error
+	if deployment.BlocksToAct, err = contract.BlocksToAct(callOpts); err != nil {
+		return fmt.Errorf("failed to get BlocksToAct: %v", err)
+	}
+	if deployment.CostToSpin, err = contract.CostToSpin(callOpts); err != nil {
+		return fmt.Errorf("failed to get CostToSpin: %v", err)
+	}
+	if deployment.CostToRespin, err = contract.CostToRespin(callOpts); err != nil {
+		return fmt.Errorf("failed to get CostToRespin: %v", err)
+	}
+	if deployment.Version, err = contract.Version(callOpts); err != nil {
+		return fmt.Errorf("failed to get version: %v", err)
+	}
+
+	blocksToAct, costToSpin, costToRespin := d.manifest.Parameters()
+	checks := []struct {
+		name          string
+		got, expected *big.Int
+	}{
+		{"BlocksToAct", deployment.BlocksToAct, blocksToAct},
+		{"CostToSpin", deployment.CostToSpin, costToSpin},
+		{"CostToRespin", deployment.CostToRespin, costToRespin},
+	}
+	for _, check := range checks {
+		if check.got.Cmp(check.expected) != 0 {
+			return fmt.Errorf("deployed contract has %s = %s, expected %s", check.name, check.got.String(), check.expected.String())
+		}
+	}
+	if d.manifest.DegenGambit.Version != "" && deployment.Version != d.manifest.DegenGambit.Version {
+		return fmt.Errorf("deployed contract reports version %q, expected %q", deployment.Version, d.manifest.DegenGambit.Version)
+	}
+
+	fmt.Fprintf(d.out, "Verified DegenGambit: BlocksToAct = %s, CostToSpin = %s, CostToRespin = %s, version = %s\n", deployment.BlocksToAct.String(), deployment.CostToSpin.String(), deployment.CostToRespin.String(), deployment.Version)
+	return nil
+}
+
+// fund sends the given amount to the pot of the DegenGambit contract.
+func (d *Deployer) fund(ctx context.Context, contractAddress common.Address, amount *big.Int) (*Funding, error) {
+	contract, contractErr := DegenGambit.NewDegenGambit(contractAddress, d.client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+
+	tx, txErr := d.manager.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		opts.Value = amount
+		return contract.Receive(opts)
+	})
+	if txErr != nil {
+		if reason := gambit.RevertReason(txErr); reason != "" {
+			return nil, fmt.Errorf("funding the pot reverted: %s", reason)
+		}
+		return nil, fmt.Errorf("failed to fund the pot: %v", txErr)
+	}
+	fmt.Fprintf(d.out, "Funding the pot with %s wei: %s\n", amount.String(), tx.Hash().Hex())
+
+	receipt, receiptErr := d.confirm(ctx, tx)
+	if receiptErr != nil {
+		return nil, fmt.Errorf("failed to fund the pot: %v", receiptErr)
+	}
+
+	pot, potErr := d.client.BalanceAt(ctx, contractAddress, nil)
+	if potErr != nil {
+		return nil, fmt.Errorf("failed to get balance of %s: %v", contractAddress.Hex(), potErr)
+	}
+
+	return &Funding{
+		TransactionHash: tx.Hash(),
+		BlockNumber:     receipt.BlockNumber.Uint64(),
+		Value:           amount,
+		Pot:             pot,
+	}, nil
+}
+
+// confirm waits for a transaction to be mined successfully and to reach the number of confirmations
+// required by the manifest.
+func (d *Deployer) confirm(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, waitErr := bind.WaitMined(ctx, d.client, tx)
+	if waitErr != nil {
+		return nil, waitErr
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction %s failed", tx.Hash().Hex())
+	}
+
+	target := receipt.BlockNumber.Uint64() + d.manifest.Confirmations - 1
+	ticker := time.NewTicker(ConfirmationPollInterval)
+	defer ticker.Stop()
+	for {
+		head, headErr := d.client.BlockNumber(ctx)
+		if headErr != nil {
+			return nil, headErr
+		}
+		if head >= target {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	// The transaction may have been reorganized out of the chain while waiting for confirmations.
+	confirmed, receiptErr := d.client.TransactionReceipt(ctx, tx.Hash())
+	if receiptErr != nil {
+		return nil, fmt.Errorf("transaction %s is no longer in the chain: %v", tx.Hash().Hex(), receiptErr)
+	}
+	return confirmed, nil
+}
@@ -0,0 +1,158 @@
+// Package deployment deploys the Degen Casino contracts from a declarative manifest, checks that the
+// deployed contracts were configured as intended, and records the result in a deployment artifact.
+//
+// A manifest looks like this:
+//
+//	name: game7-testnet
+//	rpc: https://testnet-rpc.game7.io
+//	chain-id: 13746
+//	degen-gambit:
+//	  blocks-to-act: 50
+//	  cost-to-spin: 10
+//	  cost-to-respin: 7
+//	block-inspector: true
+//	fund: 1000000000000000000
+//	confirmations: 2
+//	artifact: deployments/game7-testnet.json
+//
+// If the manifest has a salt, the contracts are deployed with CREATE2 through the
+// deterministic-deployment-proxy, so that the same manifest deploys them to the same addresses on every
+// chain. Use Predict to compute those addresses without accessing a chain.
+package deployment
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes a deployment.
+type Manifest struct {
+	// Label for the deployment, recorded in the artifact.
+	Name string `yaml:"name"`
+	// URL of the JSONRPC API to deploy through. It may be overridden on the command line.
+	RPC string `yaml:"rpc"`
+	// If set, the deployment is aborted unless the JSONRPC API serves this chain.
+	ChainID uint64 `yaml:"chain-id"`
+	// Constructor parameters of the DegenGambit contract.
+	DegenGambit GambitParameters `yaml:"degen-gambit"`
+	// Whether to deploy a BlockInspector contract alongside DegenGambit.
+	BlockInspector bool `yaml:"block-inspector"`
+	// Amount of native tokens (in wei) to send to the DegenGambit contract's pot after deployment.
+	Fund string `yaml:"fund"`
+	// Number of blocks (including the block a transaction was mined in) to wait for before a
+	// transaction is considered confirmed. Defaults to 1.
+	Confirmations uint64 `yaml:"confirmations"`
+	// Path of the deployment artifact. It may be overridden on the command line.
+	Artifact string `yaml:"artifact"`
+	// If set, the contracts are deployed through the deterministic-deployment-proxy with this salt
+	// (see ParseSalt). It may be overridden on the command line.
+	Salt string `yaml:"salt"`
+
+	blocksToAct, costToSpin, costToRespin, fund *big.Int
+	salt                                        *common.Hash
+}
+
+// GambitParameters are the constructor parameters of a DegenGambit contract. Costs are in wei.
+type GambitParameters struct {
+	BlocksToAct  string `yaml:"blocks-to-act"`
+	CostToSpin   string `yaml:"cost-to-spin"`
+	CostToRespin string `yaml:"cost-to-respin"`
+	// If set, the deployment fails unless version() on the deployed contract returns this value.
+	Version string `yaml:"version"`
+}
+
+// LoadManifest reads a manifest from a YAML file and validates it.
+func LoadManifest(path string) (*Manifest, error) {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest from %s: %v", path, err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", path, err)
+	}
+	return &manifest, nil
+}
+
+// Validate checks that the manifest is complete and parses its amounts.
+func (m *Manifest) Validate() error {
+	var err error
+	if m.blocksToAct, err = parseInteger("degen-gambit.blocks-to-act", m.DegenGambit.BlocksToAct, true); err != nil {
+		return err
+	}
+	if m.costToSpin, err = parseInteger("degen-gambit.cost-to-spin", m.DegenGambit.CostToSpin, true); err != nil {
+		return err
+	}
+	if m.costToRespin, err = parseInteger("degen-gambit.cost-to-respin", m.DegenGambit.CostToRespin, true); err != nil {
+		return err
+	}
+	if m.fund, err = parseInteger("fund", m.Fund, false); err != nil {
+		return err
+	}
+	if m.Salt != "" {
+		salt, saltErr := ParseSalt(m.Salt)
+		if saltErr != nil {
+			return saltErr
+		}
+		m.salt = &salt
+	} else {
+		m.salt = nil
+	}
+	if m.blocksToAct.Sign() == 0 {
+		return fmt.Errorf("degen-gambit.blocks-to-act must be positive")
+	}
+	if m.Confirmations == 0 {
+		m.Confirmations = 1
+	}
+	return nil
+}
+
+// Parameters returns the parsed constructor parameters of the DegenGambit contract. The manifest must
+// have been validated.
+func (m *Manifest) Parameters() (blocksToAct, costToSpin, costToRespin *big.Int) {
+	return m.blocksToAct, m.costToSpin, m.costToRespin
+}
+
+// FundAmount returns the amount to send to the pot after deployment, or nil if the pot should not be
+// funded. The manifest must have been validated.
+func (m *Manifest) FundAmount() *big.Int {
+	if m.fund == nil || m.fund.Sign() == 0 {
+		return nil
+	}
+	return m.fund
+}
+
+// SaltHash returns the parsed salt, and whether the manifest has one. The manifest must have been
+// validated.
+func (m *Manifest) SaltHash() (common.Hash, bool) {
+	if m.salt == nil {
+		return common.Hash{}, false
+	}
+	return *m.salt, true
+}
+
+// parseInteger parses a non-negative integer field of the manifest.
+func parseInteger(field, value string, required bool) (*big.Int, error) {
+	if value == "" {
+		if required {
+			return nil, fmt.Errorf("%s not specified", field)
+		}
+		return nil, nil
+	}
+	parsed, ok := new(big.Int).SetString(value, 0)
+	if !ok || parsed.Sign() < 0 {
+		return nil, fmt.Errorf("%s is not a non-negative integer: %s", field, value)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,395 @@
+// Package funder keeps the pot (the native token balance) of a DegenGambit contract funded.
+//
+// A Policy decides how much to send to the contract given its balance and current jackpot. Sends are
+// plain value transfers, which the contract accepts through its receive function. The Funder daemon
+// applies a policy whenever a prize is awarded, subject to a daily spending limit.
+package funder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/atomicfile"
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// Default interval at which the funder checks the chain for new awards.
+const DefaultPollInterval = 5 * time.Second
+
+// Maximum number of blocks scanned for Award events in a single log query.
+const MaxBlocksPerQuery = 10000
+
+// Kinds of funding policy.
+const (
+	// Send Policy.Amount once.
+	PolicyAmount = "amount"
+	// Top the contract's balance up to Policy.Amount.
+	PolicyTarget = "target"
+	// Keep the jackpot (prizes()[6]) at or above Policy.Amount.
+	PolicyJackpotFloor = "jackpot-floor"
+)
+
+// ErrDailyLimitReached is returned by Funder.Check when a top-up is needed but the daily spending
+// limit has been used up.
+var ErrDailyLimitReached error = errors.New("daily spending limit reached")
+
+// Policy decides how much to send to the contract.
+type Policy struct {
+	Kind   string
+	Amount *big.Int
+}
+
+// Validate checks that the policy is well formed.
+func (p Policy) Validate() error {
+	switch p.Kind {
+	case PolicyAmount, PolicyTarget, PolicyJackpotFloor:
+	default:
+		return fmt.Errorf("unknown funding policy: %s", p.Kind)
+	}
+	if p.Amount == nil || p.Amount.Sign() <= 0 {
+		return fmt.Errorf("the amount for the %s policy must be positive", p.Kind)
+	}
+	return nil
+}
+
+// Shortfall returns the native tokens which the policy requires to be sent to a contract with the
+// given balance and jackpot (prizes()[6]). It returns zero if nothing needs to be sent.
+//
+// For PolicyJackpotFloor, the jackpot is half of the contract's balance (as computed by payout and
+// prizes), so the balance must be raised to twice the floor.
+func (p Policy) Shortfall(balance, jackpot *big.Int) *big.Int {
+	shortfall := big.NewInt(0)
+	switch p.Kind {
+	case PolicyAmount:
+		shortfall.Set(p.Amount)
+	case PolicyTarget:
+		shortfall.Sub(p.Amount, balance)
+	case PolicyJackpotFloor:
+		if jackpot.Cmp(p.Amount) < 0 {
+			shortfall.Lsh(p.Amount, 1)
+			shortfall.Sub(shortfall, balance)
+		}
+	}
+	if shortfall.Sign() < 0 {
+		shortfall.SetInt64(0)
+	}
+	return shortfall
+}
+
+// Pot describes the state of a contract's pot.
+type Pot struct {
+	Balance *big.Int
+	Jackpot *big.Int
+}
+
+// ReadPot reads the contract's balance and jackpot.
+func ReadPot(ctx context.Context, client *ethclient.Client, contract *DegenGambit.DegenGambit, address common.Address) (Pot, error) {
+	balance, balanceErr := client.BalanceAt(ctx, address, nil)
+	if balanceErr != nil {
+		return Pot{}, fmt.Errorf("failed to get balance of %s: %v", address.Hex(), balanceErr)
+	}
+	prizes, prizesErr := contract.Prizes(&bind.CallOpts{Context: ctx})
+	if prizesErr != nil {
+		return Pot{}, fmt.Errorf("failed to get prizes: %v", prizesErr)
+	}
+	if len(prizes.PrizesAmount) <= gambit.JackpotPrizeIndex {
+		return Pot{}, fmt.Errorf("prizes returned %d prizes, expected at least %d", len(prizes.PrizesAmount), gambit.JackpotPrizeIndex+1)
+	}
+	return Pot{Balance: balance, Jackpot: prizes.PrizesAmount[gambit.JackpotPrizeIndex]}, nil
+}
+
+// Send sends value to the contract as a plain transfer, which the contract accepts through its receive
+// function.
+func Send(opts *bind.TransactOpts, contract *DegenGambit.DegenGambit, value *big.Int) (*types.Transaction, error) {
+	sendOpts := *opts
+	sendOpts.Value = new(big.Int).Set(value)
+	return contract.Receive(&sendOpts)
+}
+
+// Config describes how a funder keeps a contract funded.
+type Config struct {
+	// Address of the DegenGambit contract.
+	Contract common.Address
+	// Policy to maintain. PolicyAmount is not supported by the daemon.
+	Policy Policy
+	// Maximum native tokens to send per UTC day. Nil means no limit.
+	DailyLimit *big.Int
+	// Shortfalls smaller than this are ignored, to avoid paying for gas to send dust. Nil means 0.
+	MinAmount *big.Int
+	// Interval at which the funder checks for new awards.
+	PollInterval time.Duration
+	// Path to a file in which the funder persists how much it has spent today, so that restarting it
+	// does not reset the daily limit. Optional.
+	SpendFile string
+}
+
+// Stats summarizes the work the funder has done.
+type Stats struct {
+	// Number of top-ups sent, and the native tokens sent with them.
+	TopUps int
+	Sent   *big.Int
+	// Number of awards observed.
+	Awards int
+	// Number of checks in which a top-up was needed but was cut down or skipped because of the daily
+	// spending limit.
+	Limited int
+}
+
+// spend records how much the funder has sent on a UTC day.
+type spend struct {
+	Day   uint64   `json:"day"`
+	Spent *big.Int `json:"spent"`
+}
+
+// Funder tops up a contract's pot whenever prizes are awarded.
+type Funder struct {
+	client   *ethclient.Client
+	contract *DegenGambit.DegenGambit
+	manager  *txmanager.Manager
+	config   Config
+	out      io.Writer
+
+	mu      sync.Mutex
+	stats   Stats
+	spend   spend
+	scanned uint64
+	// Whether the pot needs to be checked: set at startup, when prizes are awarded, when the daily limit
+	// resets, and while a top-up is in flight.
+	dirty bool
+}
+
+// New creates a funder which sends its top-ups through the given transaction manager. Progress
+// messages are written to out.
+func New(client *ethclient.Client, manager *txmanager.Manager, config Config, out io.Writer) (*Funder, error) {
+	if err := config.Policy.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Policy.Kind == PolicyAmount {
+		return nil, fmt.Errorf("the %s policy cannot be maintained by the funder daemon", PolicyAmount)
+	}
+
+	contract, contractErr := DegenGambit.NewDegenGambit(config.Contract, client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+
+	f := &Funder{
+		client:   client,
+		contract: contract,
+		manager:  manager,
+		config:   config,
+		out:      out,
+		stats:    Stats{Sent: big.NewInt(0)},
+		spend:    spend{Spent: big.NewInt(0)},
+		dirty:    true,
+	}
+
+	if config.SpendFile != "" {
+		contents, readErr := os.ReadFile(config.SpendFile)
+		if readErr == nil {
+			var saved spend
+			if err := json.Unmarshal(contents, &saved); err != nil {
+				return nil, fmt.Errorf("failed to parse spend file %s: %v", config.SpendFile, err)
+			}
+			if saved.Spent != nil {
+				f.spend = saved
+			}
+		} else if !errors.Is(readErr, os.ErrNotExist) {
+			return nil, readErr
+		}
+	}
+
+	return f, nil
+}
+
+// Stats returns a copy of the funder's statistics.
+func (f *Funder) Stats() Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats := f.stats
+	stats.Sent = new(big.Int).Set(f.stats.Sent)
+	return stats
+}
+
+// Run watches for awards and tops up the pot until the context is cancelled.
+func (f *Funder) Run(ctx context.Context) error {
+	head, headErr := f.client.BlockNumber(ctx)
+	if headErr != nil {
+		return headErr
+	}
+	f.scanned = head
+
+	ticker := time.NewTicker(f.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Tick(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Fprintf(f.out, "Error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick performs a single round of the funder's work: it picks up new awards and, if the pot may have
+// changed, checks it and sends a top-up if the policy requires one.
+func (f *Funder) Tick(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	head, headErr := f.client.BlockNumber(ctx)
+	if headErr != nil {
+		return headErr
+	}
+	if err := f.scanAwards(ctx, head); err != nil {
+		return err
+	}
+
+	if err := f.manager.Rebroadcast(ctx); err != nil {
+		return err
+	}
+	if len(f.manager.Pending()) > 0 {
+		// Wait for the previous top-up to be mined, so that it is reflected in the balance.
+		f.dirty = true
+		return nil
+	}
+
+	if today := gambit.Day(uint64(time.Now().Unix())); today != f.spend.Day {
+		if f.spend.Spent.Sign() > 0 {
+			f.dirty = true
+		}
+		f.spend = spend{Day: today, Spent: big.NewInt(0)}
+	}
+
+	if !f.dirty {
+		return nil
+	}
+	if _, err := f.check(ctx); err != nil && !errors.Is(err, ErrDailyLimitReached) {
+		return err
+	}
+	f.dirty = false
+	return nil
+}
+
+// Check checks the pot and sends a top-up if the policy requires one. It returns the top-up
+// transaction, or nil if no top-up was needed.
+func (f *Funder) Check(ctx context.Context) (*types.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.check(ctx)
+}
+
+func (f *Funder) check(ctx context.Context) (*types.Transaction, error) {
+	pot, potErr := ReadPot(ctx, f.client, f.contract, f.config.Contract)
+	if potErr != nil {
+		return nil, potErr
+	}
+
+	amount := f.config.Policy.Shortfall(pot.Balance, pot.Jackpot)
+	if amount.Sign() == 0 || (f.config.MinAmount != nil && amount.Cmp(f.config.MinAmount) < 0) {
+		return nil, nil
+	}
+
+	if f.config.DailyLimit != nil {
+		remaining := new(big.Int).Sub(f.config.DailyLimit, f.spend.Spent)
+		if remaining.Cmp(amount) < 0 {
+			f.stats.Limited++
+			if remaining.Sign() <= 0 || (f.config.MinAmount != nil && remaining.Cmp(f.config.MinAmount) < 0) {
+				fmt.Fprintf(f.out, "Pot needs %s but the daily limit has been reached\n", amount.String())
+				return nil, ErrDailyLimitReached
+			}
+			fmt.Fprintf(f.out, "Pot needs %s but only %s remains of the daily limit\n", amount.String(), remaining.String())
+			amount = remaining
+		}
+	}
+
+	tx, txErr := f.manager.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return Send(opts, f.contract, amount)
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("failed to send top-up of %s: %v", amount.String(), txErr)
+	}
+
+	f.stats.TopUps++
+	f.stats.Sent.Add(f.stats.Sent, amount)
+	f.spend.Spent.Add(f.spend.Spent, amount)
+	if err := f.saveSpend(); err != nil {
+		return tx, err
+	}
+
+	fmt.Fprintf(f.out, "Sent top-up of %s (balance: %s, jackpot: %s): %s\n", amount.String(), pot.Balance.String(), pot.Jackpot.String(), tx.Hash().Hex())
+	return tx, nil
+}
+
+// scanAwards marks the pot as needing a check if prizes were awarded since the last scan.
+func (f *Funder) scanAwards(ctx context.Context, head uint64) error {
+	for f.scanned < head {
+		start := f.scanned + 1
+		end := head
+		if end-start+1 > MaxBlocksPerQuery {
+			end = start + MaxBlocksPerQuery - 1
+		}
+
+		iterator, filterErr := f.contract.FilterAward(&bind.FilterOpts{Start: start, End: &end, Context: ctx}, nil)
+		if filterErr != nil {
+			return fmt.Errorf("failed to get Award events from blocks %d-%d: %v", start, end, filterErr)
+		}
+		for iterator.Next() {
+			if iterator.Event.Value.Sign() > 0 {
+				f.stats.Awards++
+				f.dirty = true
+			}
+		}
+		iterErr := iterator.Error()
+		iterator.Close()
+		if iterErr != nil {
+			return iterErr
+		}
+
+		f.scanned = end
+	}
+	return nil
+}
+
+// saveSpend persists today's spending to the spend file, if there is one. The file is replaced
+// atomically.
+func (f *Funder) saveSpend() error {
+	if f.config.SpendFile == "" {
+		return nil
+	}
+	contents, marshalErr := json.Marshal(f.spend)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	return atomicfile.WriteFile(f.config.SpendFile, contents)
+}
+
+// Report writes a human readable summary of the funder's statistics to w.
+func (s Stats) Report(w io.Writer) {
+	fmt.Fprintf(w, "Awards observed: %d\nTop-ups: %d\nSent (wei): %s\nChecks limited by the daily limit: %d\n", s.Awards, s.TopUps, s.Sent.String(), s.Limited)
+}
@@ -0,0 +1,78 @@
+package gambit
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Transfer is a single row of a batch transfer (e.g. an airdrop of boost tokens).
+type Transfer struct {
+	// Line of the CSV file on which the transfer was specified.
+	Line   int
+	To     common.Address
+	Amount *big.Int
+}
+
+// ReadTransfersCSV reads batch transfers from CSV rows of the form "address,amount", where amounts are
+// decimal numbers of tokens with the given number of decimals (e.g. "2.5"). Amounts may be followed by
+// a unit (e.g. "2.5 GAMBIT"), which is ignored. Blank lines, lines starting with #, and a header row
+// (a first row in which neither the address nor the amount is valid) are skipped. All rows are
+// validated before any are returned, and every invalid row is reported.
+func ReadTransfersCSV(r io.Reader, decimals int) ([]Transfer, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var transfers []Transfer
+	var rowErrs []error
+	for first := true; ; first = false {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return nil, readErr
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(record) != 2 {
+			rowErrs = append(rowErrs, fmt.Errorf("line %d: expected 2 fields (address,amount), got %d", line, len(record)))
+			continue
+		}
+
+		address := strings.TrimSpace(record[0])
+		amountRaw, _, _ := strings.Cut(strings.TrimSpace(record[1]), " ")
+		amount, amountErr := ParseUnits(amountRaw, decimals)
+		if !common.IsHexAddress(address) {
+			// A header row has a label in both columns. A first row with an amount is a transfer whose
+			// address is malformed.
+			if first && !strings.HasPrefix(address, "0x") && amountErr != nil {
+				continue
+			}
+			rowErrs = append(rowErrs, fmt.Errorf("line %d: invalid address: %s", line, address))
+			continue
+		}
+
+		if amountErr != nil {
+			rowErrs = append(rowErrs, fmt.Errorf("line %d: %v", line, amountErr))
+			continue
+		}
+		if amount.Sign() == 0 {
+			rowErrs = append(rowErrs, fmt.Errorf("line %d: amount must be positive", line))
+			continue
+		}
+
+		transfers = append(transfers, Transfer{Line: line, To: common.HexToAddress(address), Amount: amount})
+	}
+
+	if len(rowErrs) > 0 {
+		return nil, errors.Join(rowErrs...)
+	}
+	return transfers, nil
+}
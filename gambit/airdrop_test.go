@@ -0,0 +1,78 @@
+package gambit
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestReadTransfersCSV(t *testing.T) {
+	input := `address,amount
+# Early players
+0x00000000000000000000000000000000000000aa,1.5
+
+0x00000000000000000000000000000000000000bb, 2 GAMBIT
+`
+	transfers, err := ReadTransfersCSV(strings.NewReader(input), 18)
+	if err != nil {
+		t.Fatalf("failed to read transfers: %v", err)
+	}
+	expected := []Transfer{
+		{Line: 3, To: common.HexToAddress("0x00000000000000000000000000000000000000aa"), Amount: new(big.Int).Mul(big.NewInt(15), big.NewInt(1e17))},
+		{Line: 5, To: common.HexToAddress("0x00000000000000000000000000000000000000bb"), Amount: new(big.Int).Mul(big.NewInt(2), big.NewInt(1e18))},
+	}
+	if len(transfers) != len(expected) {
+		t.Fatalf("expected %d transfers, got %d", len(expected), len(transfers))
+	}
+	for i, transfer := range transfers {
+		if transfer.Line != expected[i].Line || transfer.To != expected[i].To || transfer.Amount.Cmp(expected[i].Amount) != 0 {
+			t.Fatalf("expected transfer %+v, got %+v", expected[i], transfer)
+		}
+	}
+}
+
+func TestReadTransfersCSVReportsInvalidRows(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			// Without the 0x prefix, the first row looks like a header, but it has an amount.
+			name:     "malformed address in the first row",
+			input:    "00000000000000000000000000000000000000a,1\n",
+			expected: []string{"line 1: invalid address"},
+		},
+		{
+			name:     "truncated address in the first row",
+			input:    "0x00000000000000000000000000000000000000a,1\n",
+			expected: []string{"line 1: invalid address"},
+		},
+		{
+			name:     "second header row",
+			input:    "address,amount\naddress,amount\n",
+			expected: []string{"line 2: invalid address"},
+		},
+		{
+			name:     "every invalid row is reported",
+			input:    "address,amount\n0x00000000000000000000000000000000000000aa,0\n0x00000000000000000000000000000000000000bb\n0x00000000000000000000000000000000000000cc,lots\n",
+			expected: []string{"line 2: amount must be positive", "line 3: expected 2 fields", "line 4:"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			transfers, err := ReadTransfersCSV(strings.NewReader(c.input), 18)
+			if err == nil {
+				t.Fatalf("expected an error, got transfers %+v", transfers)
+			}
+			for _, message := range c.expected {
+				if !strings.Contains(err.Error(), message) {
+					t.Fatalf("expected the error to contain %q, got: %v", message, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package gambit
+
+import (
+	"math"
+	"math/big"
+)
+
+// BoostCost is the GAMBIT (in wei) burned from the sender of a boosted spin: 10**decimals().
+var BoostCost = new(big.Int).Exp(big.NewInt(10), big.NewInt(Decimals), nil)
+
+// SpinEV is the expected outcome of a single spin.
+type SpinEV struct {
+	// Probability of winning any prize, and of winning a native token prize.
+	WinProbability       float64
+	NativeWinProbability float64
+	// Expected native tokens and GAMBIT won, in wei.
+	Native float64
+	Gambit float64
+}
+
+// SpinEV returns the expected outcome of a spin made when the pot holds balance, given the current
+// GAMBIT prizes (MajorGambitPrize and MinorGambitPrize, in wei). The spin pays CostToSpin into the pot
+// before it is accepted.
+func (m *PotModel) SpinEV(balance float64, boosted bool, majorGambitPrize, minorGambitPrize float64) SpinEV {
+	odds := m.Unboosted
+	if boosted {
+		odds = m.Boosted
+	}
+
+	ev := SpinEV{}
+	for i, p := range odds {
+		ev.WinProbability += p
+		switch i {
+		case 0:
+			ev.Gambit += p * majorGambitPrize
+		case 1:
+			ev.Gambit += p * minorGambitPrize
+		default:
+			amount, _ := m.PrizeAmount(i, balance+m.CostToSpin)
+			ev.NativeWinProbability += p
+			ev.Native += p * amount
+		}
+	}
+	return ev
+}
+
+// BoostComparison compares boosted and unboosted spins made when the pot holds Balance.
+type BoostComparison struct {
+	Balance   float64
+	Unboosted SpinEV
+	Boosted   SpinEV
+}
+
+// CompareBoost compares boosted and unboosted spins made when the pot holds balance.
+func (m *PotModel) CompareBoost(balance, majorGambitPrize, minorGambitPrize float64) BoostComparison {
+	return BoostComparison{
+		Balance:   balance,
+		Unboosted: m.SpinEV(balance, false, majorGambitPrize, minorGambitPrize),
+		Boosted:   m.SpinEV(balance, true, majorGambitPrize, minorGambitPrize),
+	}
+}
+
+// NativeGain returns the expected additional native tokens (in wei) won by boosting a spin.
+func (c BoostComparison) NativeGain() float64 {
+	return c.Boosted.Native - c.Unboosted.Native
+}
+
+// GambitCost returns the expected net GAMBIT (in wei) spent by boosting a spin: the burned token, less
+// the additional GAMBIT prizes that the improved reels are expected to win.
+func (c BoostComparison) GambitCost() float64 {
+	cost, _ := new(big.Float).SetInt(BoostCost).Float64()
+	return cost - (c.Boosted.Gambit - c.Unboosted.Gambit)
+}
+
+// Worthwhile reports whether boosting pays for itself when a GAMBIT is worth gambitPrice native
+// tokens.
+func (c BoostComparison) Worthwhile(gambitPrice float64) bool {
+	return c.NativeGain() >= c.GambitCost()*gambitPrice
+}
+
+// BoostBreakEven returns the smallest pot at which boosting a spin pays for itself when a GAMBIT is
+// worth gambitPrice native tokens. It returns +Inf if boosting never pays for itself. The search
+// assumes that the improved reels are at least as likely as the unmodified reels to win each native
+// prize, so that the gain from boosting grows with the pot.
+func (m *PotModel) BoostBreakEven(gambitPrice, majorGambitPrize, minorGambitPrize float64) float64 {
+	worthwhile := func(balance float64) bool {
+		return m.CompareBoost(balance, majorGambitPrize, minorGambitPrize).Worthwhile(gambitPrice)
+	}
+	if worthwhile(0) {
+		return 0
+	}
+
+	high := math.Max(m.CostToSpin, 1)
+	for !worthwhile(high) {
+		high *= 2
+		if high > 1e60 {
+			return math.Inf(1)
+		}
+	}
+	low := 0.0
+	for i := 0; i < 200 && high-low > 1; i++ {
+		mid := (low + high) / 2
+		if worthwhile(mid) {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return high
+}
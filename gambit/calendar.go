@@ -0,0 +1,217 @@
+package gambit
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+)
+
+// StreakState is a player's streak state, as stored by the contract.
+type StreakState struct {
+	LastStreakDay             uint64
+	CurrentDailyStreakLength  uint64
+	LastStreakWeek            uint64
+	CurrentWeeklyStreakLength uint64
+}
+
+// StreakRewards are the GAMBIT rewards (in wei) minted for extending streaks.
+type StreakRewards struct {
+	Daily  *big.Int
+	Weekly *big.Int
+}
+
+// ReadStreakState reads a player's streak state from the contract.
+func ReadStreakState(opts *bind.CallOpts, contract *DegenGambit.DegenGambit, player common.Address) (StreakState, error) {
+	var state StreakState
+
+	lastStreakDay, lastStreakDayErr := contract.LastStreakDay(opts, player)
+	if lastStreakDayErr != nil {
+		return state, fmt.Errorf("failed to get LastStreakDay: %v", lastStreakDayErr)
+	}
+	dailyStreakLength, dailyStreakLengthErr := contract.CurrentDailyStreakLength(opts, player)
+	if dailyStreakLengthErr != nil {
+		return state, fmt.Errorf("failed to get CurrentDailyStreakLength: %v", dailyStreakLengthErr)
+	}
+	lastStreakWeek, lastStreakWeekErr := contract.LastStreakWeek(opts, player)
+	if lastStreakWeekErr != nil {
+		return state, fmt.Errorf("failed to get LastStreakWeek: %v", lastStreakWeekErr)
+	}
+	weeklyStreakLength, weeklyStreakLengthErr := contract.CurrentWeeklyStreakLength(opts, player)
+	if weeklyStreakLengthErr != nil {
+		return state, fmt.Errorf("failed to get CurrentWeeklyStreakLength: %v", weeklyStreakLengthErr)
+	}
+
+	state.LastStreakDay = lastStreakDay.Uint64()
+	state.CurrentDailyStreakLength = dailyStreakLength.Uint64()
+	state.LastStreakWeek = lastStreakWeek.Uint64()
+	state.CurrentWeeklyStreakLength = weeklyStreakLength.Uint64()
+	return state, nil
+}
+
+// ReadStreakRewards reads DailyStreakReward and WeeklyStreakReward from the contract.
+func ReadStreakRewards(opts *bind.CallOpts, contract *DegenGambit.DegenGambit) (StreakRewards, error) {
+	daily, dailyErr := contract.DailyStreakReward(opts)
+	if dailyErr != nil {
+		return StreakRewards{}, fmt.Errorf("failed to get DailyStreakReward: %v", dailyErr)
+	}
+	weekly, weeklyErr := contract.WeeklyStreakReward(opts)
+	if weeklyErr != nil {
+		return StreakRewards{}, fmt.Errorf("failed to get WeeklyStreakReward: %v", weeklyErr)
+	}
+	return StreakRewards{Daily: daily, Weekly: weekly}, nil
+}
+
+// Spin applies the contract's _streaks logic to the state for a spin made on the given day. It returns
+// whether the spin extended the daily and weekly streaks (and so earned their rewards).
+func (s *StreakState) Spin(day uint64) (daily, weekly bool) {
+	if s.LastStreakDay+1 < day {
+		s.CurrentDailyStreakLength = 0
+	}
+	if s.LastStreakDay+1 == day {
+		s.CurrentDailyStreakLength++
+		daily = true
+	}
+	s.LastStreakDay = day
+
+	week := Week(day)
+	if s.LastStreakWeek+1 < week {
+		s.CurrentWeeklyStreakLength = 0
+	}
+	if s.LastStreakWeek+1 == week {
+		s.CurrentWeeklyStreakLength++
+		weekly = true
+	}
+	s.LastStreakWeek = week
+
+	return daily, weekly
+}
+
+// DayStart returns the time (in UTC) at which the given streak day starts.
+func DayStart(day uint64) time.Time {
+	return time.Unix(int64(day*SecondsPerDay), 0).UTC()
+}
+
+// WeekStart returns the time (in UTC) at which the given streak week starts.
+func WeekStart(week uint64) time.Time {
+	return DayStart(week * DaysPerWeek)
+}
+
+// StreakWindow is the period in which a player must spin to extend a streak.
+type StreakWindow struct {
+	// Day or week in which the player must spin.
+	Period uint64
+	// Start (inclusive) and end (exclusive) of the window, in UTC.
+	Start time.Time
+	End   time.Time
+	// What a spin made now (i.e. at the time the window was computed) does to the streak.
+	Status StreakStatus
+}
+
+// DailyWindow returns the window in which the player must next spin to extend their daily streak,
+// given the current Unix timestamp. If the streak is broken, the window is the current day, in which a
+// spin starts a new streak.
+func (s StreakState) DailyWindow(now uint64) StreakWindow {
+	today := Day(now)
+	status := StreakStatusAt(s.LastStreakDay, today)
+	period := today
+	if status == StreakCurrent {
+		period = s.LastStreakDay + 1
+	}
+	return StreakWindow{Period: period, Start: DayStart(period), End: DayStart(period + 1), Status: status}
+}
+
+// WeeklyWindow returns the window in which the player must next spin to extend their weekly streak,
+// given the current Unix timestamp.
+func (s StreakState) WeeklyWindow(now uint64) StreakWindow {
+	thisWeek := Week(Day(now))
+	status := StreakStatusAt(s.LastStreakWeek, thisWeek)
+	period := thisWeek
+	if status == StreakCurrent {
+		period = s.LastStreakWeek + 1
+	}
+	return StreakWindow{Period: period, Start: WeekStart(period), End: WeekStart(period + 1), Status: status}
+}
+
+// ForecastDay is a single day in a streak forecast.
+type ForecastDay struct {
+	Day  uint64
+	Spin bool
+	// Whether the day's spin extended the daily and weekly streaks.
+	DailyReward  bool
+	WeeklyReward bool
+	// Streak lengths at the end of the day.
+	DailyStreakLength  uint64
+	WeeklyStreakLength uint64
+}
+
+// Forecast is the outcome of playing according to a schedule.
+type Forecast struct {
+	Days          []ForecastDay
+	Spins         int
+	DailyRewards  int
+	WeeklyRewards int
+	// Total GAMBIT (in wei) earned from streak rewards.
+	Gambit *big.Int
+	// Streak state after the last day of the forecast.
+	Final StreakState
+}
+
+// ForecastStreaks simulates a player with the given streak state who spins on the days (starting
+// from startDay, for the given number of days) for which spinOn returns true, and totals the streak
+// rewards they earn.
+func ForecastStreaks(state StreakState, rewards StreakRewards, startDay uint64, days int, spinOn func(day uint64) bool) Forecast {
+	forecast := Forecast{Gambit: big.NewInt(0)}
+
+	for i := 0; i < days; i++ {
+		day := startDay + uint64(i)
+		forecastDay := ForecastDay{Day: day}
+
+		if spinOn(day) {
+			forecastDay.Spin = true
+			forecastDay.DailyReward, forecastDay.WeeklyReward = state.Spin(day)
+			forecast.Spins++
+			if forecastDay.DailyReward {
+				forecast.DailyRewards++
+				forecast.Gambit.Add(forecast.Gambit, rewards.Daily)
+			}
+			if forecastDay.WeeklyReward {
+				forecast.WeeklyRewards++
+				forecast.Gambit.Add(forecast.Gambit, rewards.Weekly)
+			}
+		}
+
+		forecastDay.DailyStreakLength = EffectiveStreakLength(state.CurrentDailyStreakLength, state.LastStreakDay, day)
+		forecastDay.WeeklyStreakLength = EffectiveStreakLength(state.CurrentWeeklyStreakLength, state.LastStreakWeek, Week(day))
+		forecast.Days = append(forecast.Days, forecastDay)
+	}
+
+	forecast.Final = state
+	return forecast
+}
+
+// SpinOnWeekdays returns a schedule (for use with ForecastStreaks) which spins on the given UTC
+// weekdays.
+func SpinOnWeekdays(weekdays ...time.Weekday) func(day uint64) bool {
+	return func(day uint64) bool {
+		weekday := DayStart(day).Weekday()
+		for _, w := range weekdays {
+			if w == weekday {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SpinEvery returns a schedule (for use with ForecastStreaks) which spins on startDay and every
+// interval days after it.
+func SpinEvery(startDay, interval uint64) func(day uint64) bool {
+	return func(day uint64) bool {
+		return day >= startDay && (day-startDay)%interval == 0
+	}
+}
@@ -0,0 +1,217 @@
+package gambit
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+// Thursday 9 May 2024, the first day of streak week 2836.
+const (
+	testThursday uint64 = 19852
+	testWeek     uint64 = 2836
+)
+
+func TestWeekStartsOnThursday(t *testing.T) {
+	if Week(testThursday) != testWeek || Week(testThursday-1) != testWeek-1 || Week(testThursday+6) != testWeek {
+		t.Fatalf("expected week %d to run from day %d to day %d", testWeek, testThursday, testThursday+6)
+	}
+	if start := WeekStart(testWeek); start.Weekday() != time.Thursday || !start.Equal(time.Date(2024, 5, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected week %d to start at 00:00 UTC on Thursday 9 May 2024, got %v", testWeek, start)
+	}
+}
+
+func TestStreakStateSpin(t *testing.T) {
+	cases := []struct {
+		name           string
+		state          StreakState
+		day            uint64
+		daily, weekly  bool
+		expectedDaily  uint64
+		expectedWeekly uint64
+	}{
+		{
+			name:  "same day",
+			state: StreakState{LastStreakDay: testThursday + 1, CurrentDailyStreakLength: 3, LastStreakWeek: testWeek, CurrentWeeklyStreakLength: 2},
+			day:   testThursday + 1, expectedDaily: 3, expectedWeekly: 2,
+		},
+		{
+			name:  "next day in the same week",
+			state: StreakState{LastStreakDay: testThursday + 1, CurrentDailyStreakLength: 3, LastStreakWeek: testWeek, CurrentWeeklyStreakLength: 2},
+			day:   testThursday + 2, daily: true, expectedDaily: 4, expectedWeekly: 2,
+		},
+		{
+			name:  "Wednesday to Thursday extends both streaks",
+			state: StreakState{LastStreakDay: testThursday - 1, CurrentDailyStreakLength: 3, LastStreakWeek: testWeek - 1, CurrentWeeklyStreakLength: 2},
+			day:   testThursday, daily: true, weekly: true, expectedDaily: 4, expectedWeekly: 3,
+		},
+		{
+			name:  "gap of a day breaks the daily streak",
+			state: StreakState{LastStreakDay: testThursday + 1, CurrentDailyStreakLength: 3, LastStreakWeek: testWeek, CurrentWeeklyStreakLength: 2},
+			day:   testThursday + 3, expectedDaily: 0, expectedWeekly: 2,
+		},
+		{
+			name:  "gap across the week boundary keeps the weekly streak",
+			state: StreakState{LastStreakDay: testThursday - 3, CurrentDailyStreakLength: 5, LastStreakWeek: testWeek - 1, CurrentWeeklyStreakLength: 2},
+			day:   testThursday + 6, weekly: true, expectedDaily: 0, expectedWeekly: 3,
+		},
+		{
+			name:  "missed week breaks the weekly streak",
+			state: StreakState{LastStreakDay: testThursday - 1, CurrentDailyStreakLength: 5, LastStreakWeek: testWeek - 1, CurrentWeeklyStreakLength: 2},
+			day:   testThursday + 7, expectedDaily: 0, expectedWeekly: 0,
+		},
+		{
+			name:  "first spin",
+			state: StreakState{},
+			day:   testThursday, expectedDaily: 0, expectedWeekly: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			state := c.state
+			daily, weekly := state.Spin(c.day)
+			if daily != c.daily || weekly != c.weekly {
+				t.Fatalf("expected rewards (daily %v, weekly %v), got (daily %v, weekly %v)", c.daily, c.weekly, daily, weekly)
+			}
+			if state.CurrentDailyStreakLength != c.expectedDaily || state.CurrentWeeklyStreakLength != c.expectedWeekly {
+				t.Fatalf("expected lengths (daily %d, weekly %d), got (daily %d, weekly %d)", c.expectedDaily, c.expectedWeekly, state.CurrentDailyStreakLength, state.CurrentWeeklyStreakLength)
+			}
+			if state.LastStreakDay != c.day || state.LastStreakWeek != Week(c.day) {
+				t.Fatalf("expected the spin to be recorded on day %d, week %d, got %+v", c.day, Week(c.day), state)
+			}
+		})
+	}
+}
+
+func TestDailyWindow(t *testing.T) {
+	// Noon on Saturday, two days into the week.
+	now := testThursday*SecondsPerDay + 2*SecondsPerDay + 12*3600
+	today := Day(now)
+
+	cases := []struct {
+		name     string
+		lastDay  uint64
+		status   StreakStatus
+		expected uint64
+	}{
+		{name: "spun today", lastDay: today, status: StreakCurrent, expected: today + 1},
+		{name: "spun yesterday", lastDay: today - 1, status: StreakExtends, expected: today},
+		{name: "gap", lastDay: today - 2, status: StreakBroken, expected: today},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			window := StreakState{LastStreakDay: c.lastDay}.DailyWindow(now)
+			if window.Status != c.status || window.Period != c.expected {
+				t.Fatalf("expected status %s in day %d, got status %s in day %d", c.status, c.expected, window.Status, window.Period)
+			}
+			if !window.Start.Equal(DayStart(c.expected)) || window.End.Sub(window.Start) != 24*time.Hour {
+				t.Fatalf("expected the window to be day %d, got %v to %v", c.expected, window.Start, window.End)
+			}
+		})
+	}
+}
+
+func TestWeeklyWindow(t *testing.T) {
+	lastMinuteOfWednesday := testThursday*SecondsPerDay - 60
+	thursdayMidnight := testThursday * SecondsPerDay
+
+	cases := []struct {
+		name     string
+		now      uint64
+		lastWeek uint64
+		status   StreakStatus
+		expected uint64
+	}{
+		{name: "spun this week", now: lastMinuteOfWednesday, lastWeek: testWeek - 1, status: StreakCurrent, expected: testWeek},
+		{name: "spun last week, before the boundary", now: lastMinuteOfWednesday, lastWeek: testWeek - 2, status: StreakExtends, expected: testWeek - 1},
+		{name: "spun last week, after the boundary", now: thursdayMidnight, lastWeek: testWeek - 1, status: StreakExtends, expected: testWeek},
+		{name: "missed a week at the boundary", now: thursdayMidnight, lastWeek: testWeek - 2, status: StreakBroken, expected: testWeek},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			window := StreakState{LastStreakWeek: c.lastWeek}.WeeklyWindow(c.now)
+			if window.Status != c.status || window.Period != c.expected {
+				t.Fatalf("expected status %s in week %d, got status %s in week %d", c.status, c.expected, window.Status, window.Period)
+			}
+			if window.Start.Weekday() != time.Thursday || !window.Start.Equal(WeekStart(c.expected)) || window.End.Sub(window.Start) != 7*24*time.Hour {
+				t.Fatalf("expected the window to be week %d, from Thursday to Thursday, got %v to %v", c.expected, window.Start, window.End)
+			}
+		})
+	}
+}
+
+func TestForecastStreaks(t *testing.T) {
+	rewards := StreakRewards{Daily: big.NewInt(10), Weekly: big.NewInt(100)}
+	// A player who spun on Wednesday, continuing a 2 day and 1 week streak.
+	state := StreakState{LastStreakDay: testThursday - 1, CurrentDailyStreakLength: 2, LastStreakWeek: testWeek - 1, CurrentWeeklyStreakLength: 1}
+
+	cases := []struct {
+		name          string
+		days          int
+		spinOn        func(day uint64) bool
+		spins         int
+		dailyRewards  int
+		weeklyRewards int
+		final         StreakState
+		// Daily streak length at the end of each day.
+		lengths []uint64
+	}{
+		{
+			name:   "every day across the week boundary",
+			days:   8,
+			spinOn: func(day uint64) bool { return true },
+			spins:  8, dailyRewards: 8, weeklyRewards: 2,
+			final:   StreakState{LastStreakDay: testThursday + 7, CurrentDailyStreakLength: 10, LastStreakWeek: testWeek + 1, CurrentWeeklyStreakLength: 3},
+			lengths: []uint64{3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			name:   "every other day",
+			days:   5,
+			spinOn: SpinEvery(testThursday, 2),
+			spins:  3, dailyRewards: 1, weeklyRewards: 1,
+			final:   StreakState{LastStreakDay: testThursday + 4, CurrentDailyStreakLength: 0, LastStreakWeek: testWeek, CurrentWeeklyStreakLength: 2},
+			lengths: []uint64{3, 3, 0, 0, 0},
+		},
+		{
+			name:   "Thursdays only",
+			days:   15,
+			spinOn: SpinOnWeekdays(time.Thursday),
+			spins:  3, dailyRewards: 1, weeklyRewards: 3,
+			final:   StreakState{LastStreakDay: testThursday + 14, CurrentDailyStreakLength: 0, LastStreakWeek: testWeek + 2, CurrentWeeklyStreakLength: 4},
+			lengths: []uint64{3, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:   "no spins",
+			days:   9,
+			spinOn: func(day uint64) bool { return false },
+			final:  state,
+			// The streak is broken once a day passes without a spin, even though the stored length is kept.
+			lengths: []uint64{2, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			forecast := ForecastStreaks(state, rewards, testThursday, c.days, c.spinOn)
+			if forecast.Spins != c.spins || forecast.DailyRewards != c.dailyRewards || forecast.WeeklyRewards != c.weeklyRewards {
+				t.Fatalf("expected %d spins, %d daily and %d weekly rewards, got %d, %d and %d", c.spins, c.dailyRewards, c.weeklyRewards, forecast.Spins, forecast.DailyRewards, forecast.WeeklyRewards)
+			}
+			expectedGambit := int64(10*c.dailyRewards + 100*c.weeklyRewards)
+			if forecast.Gambit.Cmp(big.NewInt(expectedGambit)) != 0 {
+				t.Fatalf("expected %d GAMBIT wei, got %s", expectedGambit, forecast.Gambit.String())
+			}
+			if forecast.Final != c.final {
+				t.Fatalf("expected final state %+v, got %+v", c.final, forecast.Final)
+			}
+			if len(forecast.Days) != c.days {
+				t.Fatalf("expected %d days, got %d", c.days, len(forecast.Days))
+			}
+			for i, day := range forecast.Days {
+				if day.Day != testThursday+uint64(i) || day.DailyStreakLength != c.lengths[i] {
+					t.Fatalf("expected day %d to end with a daily streak of %d, got %+v", testThursday+uint64(i), c.lengths[i], day)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package gambit
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrEntropyNotFound is returned by SearchEntropy and SearchPlayer when none of the candidates they
+// tried has a matching outcome.
+var ErrEntropyNotFound error = errors.New("no entropy with a matching outcome found")
+
+// Entropy mirrors the contract's _entropy method. It returns the entropy for a player's spin, given the
+// hash of the block in which they spun: uint256(keccak256(abi.encode(blockHash, player))).
+func Entropy(blockHash common.Hash, player common.Address) *big.Int {
+	encoded := make([]byte, 64)
+	copy(encoded[:32], blockHash.Bytes())
+	copy(encoded[44:], player.Bytes())
+	return new(big.Int).SetBytes(crypto.Keccak256(encoded))
+}
+
+// OutcomeMatcher reports whether a reel outcome is one that a search is looking for.
+type OutcomeMatcher func(left, center, right uint64) bool
+
+// MatchSymbols matches exactly the given outcome.
+func MatchSymbols(left, center, right uint64) OutcomeMatcher {
+	return func(l, c, r uint64) bool {
+		return l == left && c == center && r == right
+	}
+}
+
+// MatchPrize matches the outcomes which win the prize with the given index (in prizes()).
+func MatchPrize(prizeIndex int) OutcomeMatcher {
+	return func(left, center, right uint64) bool {
+		index, won := PrizeIndex(left, center, right)
+		return won && index == prizeIndex
+	}
+}
+
+// MatchNoPrize matches the outcomes which do not win a prize.
+func MatchNoPrize(left, center, right uint64) bool {
+	_, won := PrizeIndex(left, center, right)
+	return !won
+}
+
+// EntropyMatch is an entropy found by a search, and the outcome it produces.
+type EntropyMatch struct {
+	// Number of candidates tried, including the match.
+	Attempts            uint64
+	Entropy             *big.Int
+	Left, Center, Right uint64
+}
+
+// PlayerMatch is an account found by SearchPlayer.
+type PlayerMatch struct {
+	EntropyMatch
+	Player     common.Address
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// SearchEntropy searches the hash outputs keccak256(seed || i), for i = 0, 1, ... (with i encoded as
+// 8 big-endian bytes), for a 256-bit entropy whose outcome on the given reels matches. Unlike the
+// entropies returned by EntropyForOutcome, the matches look like the entropies of real spins, and the
+// same seed always produces the same match.
+func SearchEntropy(reels Reels, match OutcomeMatcher, seed []byte, maxAttempts uint64) (EntropyMatch, error) {
+	for attempt := uint64(0); attempt < maxAttempts; attempt++ {
+		entropy := new(big.Int).SetBytes(seededHash(seed, attempt))
+		if result, ok := matchEntropy(reels, match, entropy, attempt); ok {
+			return result, nil
+		}
+	}
+	return EntropyMatch{Attempts: maxAttempts}, ErrEntropyNotFound
+}
+
+// SearchPlayer searches for an account whose spin, if made in the block with the given hash, would
+// have a matching outcome on the given reels (using the entropy the contract derives from block
+// hashes). The private key of the i-th candidate account is keccak256(seed || i), so the account can
+// be used to sign transactions in fixtures.
+func SearchPlayer(reels Reels, blockHash common.Hash, match OutcomeMatcher, seed []byte, maxAttempts uint64) (PlayerMatch, error) {
+	for attempt := uint64(0); attempt < maxAttempts; attempt++ {
+		privateKey, keyErr := crypto.ToECDSA(seededHash(seed, attempt))
+		if keyErr != nil {
+			// The hash is not a valid secp256k1 private key. This is astronomically unlikely.
+			continue
+		}
+		player := crypto.PubkeyToAddress(privateKey.PublicKey)
+		if result, ok := matchEntropy(reels, match, Entropy(blockHash, player), attempt); ok {
+			return PlayerMatch{EntropyMatch: result, Player: player, PrivateKey: privateKey}, nil
+		}
+	}
+	return PlayerMatch{EntropyMatch: EntropyMatch{Attempts: maxAttempts}}, ErrEntropyNotFound
+}
+
+func matchEntropy(reels Reels, match OutcomeMatcher, entropy *big.Int, attempt uint64) (EntropyMatch, bool) {
+	left, center, right := reels.Outcome(entropy)
+	if !match(left, center, right) {
+		return EntropyMatch{}, false
+	}
+	return EntropyMatch{Attempts: attempt + 1, Entropy: entropy, Left: left, Center: center, Right: right}, true
+}
+
+// seededHash returns keccak256(seed || counter), with the counter encoded as 8 big-endian bytes.
+func seededHash(seed []byte, counter uint64) []byte {
+	var encodedCounter [8]byte
+	binary.BigEndian.PutUint64(encodedCounter[:], counter)
+	return crypto.Keccak256(seed, encodedCounter[:])
+}
@@ -0,0 +1,89 @@
+// Package gambit contains helpers for working with Degen's Gambit contracts from Go which are not
+// covered by the generated bindings.
+package gambit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+)
+
+// Custom errors raised by the DegenGambit contract.
+const (
+	ErrorDeadlineExceeded    = "DeadlineExceeded"
+	ErrorWaitForTick         = "WaitForTick"
+	ErrorInsufficientValue   = "InsufficientValue"
+	ErrorOutcomeOutOfBounds  = "OutcomeOutOfBounds"
+	ErrorFailedPrizeTransfer = "FailedPrizeTransfer"
+)
+
+// RevertReason extracts the reason that a contract call or gas estimate reverted from the error
+// returned by the JSONRPC API. Custom errors defined on the DegenGambit contract (e.g. WaitForTick,
+// DeadlineExceeded) are returned by name. Reverts with a string reason return that reason. If the
+// error is not a revert, RevertReason returns an empty string.
+func RevertReason(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if revertData, ok := revertDataFromErrorData(dataErr.ErrorData()); ok {
+			if reason := DecodeRevertData(revertData); reason != "" {
+				return reason
+			}
+		}
+	}
+
+	message := err.Error()
+	if strings.Contains(message, "execution reverted") {
+		if _, reason, found := strings.Cut(message, "execution reverted: "); found {
+			return reason
+		}
+		return "execution reverted"
+	}
+
+	return ""
+}
+
+// DecodeRevertData decodes the return data of a reverted call into the name of the DegenGambit custom
+// error or the revert string that it represents.
+func DecodeRevertData(revertData []byte) string {
+	if len(revertData) < 4 {
+		return ""
+	}
+
+	if reason, unpackErr := abi.UnpackRevert(revertData); unpackErr == nil {
+		return reason
+	}
+
+	contractABI, abiErr := DegenGambit.DegenGambitMetaData.GetAbi()
+	if abiErr != nil {
+		return ""
+	}
+	for name, abiError := range contractABI.Errors {
+		if bytes.Equal(abiError.ID[:4], revertData[:4]) {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("unknown error (selector: %s)", hexutil.Encode(revertData[:4]))
+}
+
+func revertDataFromErrorData(errorData interface{}) ([]byte, bool) {
+	switch data := errorData.(type) {
+	case string:
+		decoded, err := hexutil.Decode(data)
+		return decoded, err == nil
+	case []byte:
+		return data, true
+	}
+	return nil, false
+}
@@ -0,0 +1,127 @@
+package gambit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+)
+
+// Number of prizes returned by prizes().
+const NumPrizes = 7
+
+// ReelSamples is the number of equally likely samples each reel draws its symbol from: the reels
+// sample disjoint 30-bit slices of the spin's entropy, and are therefore independent.
+const ReelSamples = 1 << 30
+
+// ReelCMF is the cumulative mass function of a reel, as stored in the contract's *Reel arrays. A reel
+// shows symbol i if its sample is less than ReelCMF[i] (and not less than ReelCMF[i-1]). The last
+// symbol is shown for all remaining samples.
+type ReelCMF [NumSymbols]uint64
+
+// Probability returns the exact probability that the reel shows the given symbol.
+func (cmf ReelCMF) Probability(symbol uint64) *big.Rat {
+	var lower, upper uint64
+	if symbol > 0 {
+		lower = cmf[symbol-1]
+	}
+	if symbol == NumSymbols-1 {
+		upper = ReelSamples
+	} else {
+		upper = cmf[symbol]
+	}
+	if upper < lower {
+		return new(big.Rat)
+	}
+	return new(big.Rat).SetFrac(new(big.Int).SetUint64(upper-lower), big.NewInt(ReelSamples))
+}
+
+// Reels holds the CMFs of the three reels used for a spin.
+type Reels struct {
+	Left, Center, Right ReelCMF
+}
+
+// ReadReels reads the CMFs of the unmodified reels (or, if boosted is true, the improved reels) from the
+// contract.
+func ReadReels(opts *bind.CallOpts, contract *DegenGambit.DegenGambit, boosted bool) (Reels, error) {
+	var reels Reels
+	getters := []struct {
+		cmf    *ReelCMF
+		name   string
+		getter func(*bind.CallOpts, *big.Int) (*big.Int, error)
+	}{
+		{&reels.Left, "UnmodifiedLeftReel", contract.UnmodifiedLeftReel},
+		{&reels.Center, "UnmodifiedCenterReel", contract.UnmodifiedCenterReel},
+		{&reels.Right, "UnmodifiedRightReel", contract.UnmodifiedRightReel},
+	}
+	if boosted {
+		getters[0].name, getters[0].getter = "ImprovedLeftReel", contract.ImprovedLeftReel
+		getters[1].name, getters[1].getter = "ImprovedCenterReel", contract.ImprovedCenterReel
+		getters[2].name, getters[2].getter = "ImprovedRightReel", contract.ImprovedRightReel
+	}
+
+	for _, g := range getters {
+		for i := 0; i < NumSymbols; i++ {
+			value, err := g.getter(opts, big.NewInt(int64(i)))
+			if err != nil {
+				return reels, fmt.Errorf("failed to read %s[%d]: %v", g.name, i, err)
+			}
+			if !value.IsUint64() {
+				return reels, fmt.Errorf("%s[%d] is out of range: %s", g.name, i, value.String())
+			}
+			g.cmf[i] = value.Uint64()
+		}
+	}
+	return reels, nil
+}
+
+// Odds are the exact probabilities of the outcomes of a spin.
+type Odds struct {
+	// Probability of winning each prize, indexed as in prizes().
+	Prizes [NumPrizes]*big.Rat
+	// Probability of not winning a prize.
+	NoPrize *big.Rat
+}
+
+// ComputeOdds computes the exact probability of each prize by enumerating every combination of
+// symbols on the given reels.
+func ComputeOdds(reels Reels) Odds {
+	odds := Odds{NoPrize: new(big.Rat)}
+	for i := range odds.Prizes {
+		odds.Prizes[i] = new(big.Rat)
+	}
+
+	var left, center, right [NumSymbols]*big.Rat
+	for symbol := uint64(0); symbol < NumSymbols; symbol++ {
+		left[symbol] = reels.Left.Probability(symbol)
+		center[symbol] = reels.Center.Probability(symbol)
+		right[symbol] = reels.Right.Probability(symbol)
+	}
+
+	p := new(big.Rat)
+	for l := uint64(0); l < NumSymbols; l++ {
+		for c := uint64(0); c < NumSymbols; c++ {
+			for r := uint64(0); r < NumSymbols; r++ {
+				p.Mul(left[l], center[c])
+				p.Mul(p, right[r])
+				if prizeIndex, won := PrizeIndex(l, c, r); won {
+					odds.Prizes[prizeIndex].Add(odds.Prizes[prizeIndex], p)
+				} else {
+					odds.NoPrize.Add(odds.NoPrize, p)
+				}
+			}
+		}
+	}
+	return odds
+}
+
+// Float returns the probabilities of the prizes and of not winning a prize as float64s.
+func (odds Odds) Float() (prizes [NumPrizes]float64, noPrize float64) {
+	for i, p := range odds.Prizes {
+		prizes[i], _ = p.Float64()
+	}
+	noPrize, _ = odds.NoPrize.Float64()
+	return prizes, noPrize
+}
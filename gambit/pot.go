@@ -0,0 +1,230 @@
+package gambit
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+)
+
+// Multiples of CostToSpin paid by the minor native prizes (prizes 2 and 3), and the shifts by which
+// the contract limits them to a fraction of its balance (balance>>6 and balance>>4).
+const (
+	MinorTripleCostMultiple = 50
+	MinorTripleBalanceShift = 6
+	MinorMajorCostMultiple  = 100
+	MinorMajorBalanceShift  = 4
+)
+
+// PotModel models the native token balance (the pot) of a DegenGambit contract, one spin at a time.
+// Each spin pays the cost of spinning into the pot and is then accepted, paying out its prize (as
+// computed by the contract's payout method) from the pot. Amounts are in wei, as float64s.
+type PotModel struct {
+	CostToSpin   float64
+	CostToRespin float64
+	// Fraction of spins which are respins (and so cost CostToRespin), and fraction of spins which are
+	// boosted (and so use the improved reels).
+	RespinFraction float64
+	BoostFraction  float64
+	// Prize probabilities for unboosted and boosted spins, indexed as in prizes().
+	Unboosted [NumPrizes]float64
+	Boosted   [NumPrizes]float64
+}
+
+// NewPotModel creates a model of a pot for a contract with the given costs and odds. The fractions of
+// respins and boosted spins must be set separately.
+func NewPotModel(costToSpin, costToRespin *big.Int, unboosted, boosted Odds) *PotModel {
+	m := &PotModel{}
+	m.CostToSpin, _ = new(big.Float).SetInt(costToSpin).Float64()
+	m.CostToRespin, _ = new(big.Float).SetInt(costToRespin).Float64()
+	m.Unboosted, _ = unboosted.Float()
+	m.Boosted, _ = boosted.Float()
+	return m
+}
+
+// PrizeAmount returns the native tokens paid by the given prize when the pot holds balance (after the
+// spin's cost has been paid in), mirroring the contract's payout method. limited is true if the prize
+// is a minor native prize whose multiple of CostToSpin was cut down to a fraction of the balance. GAMBIT
+// prizes pay no native tokens.
+func (m *PotModel) PrizeAmount(prizeIndex int, balance float64) (amount float64, limited bool) {
+	switch prizeIndex {
+	case 2:
+		return capAmount(MinorTripleCostMultiple*m.CostToSpin, balance, MinorTripleBalanceShift)
+	case 3:
+		return capAmount(MinorMajorCostMultiple*m.CostToSpin, balance, MinorMajorBalanceShift)
+	case 4, 5:
+		return math.Floor(balance / 8), false
+	case JackpotPrizeIndex:
+		return math.Floor(balance / 2), false
+	default:
+		return 0, false
+	}
+}
+
+func capAmount(amount, balance float64, shift uint) (float64, bool) {
+	limit := math.Floor(balance / float64(uint64(1)<<shift))
+	if amount > limit {
+		return limit, true
+	}
+	return amount, false
+}
+
+// LimitBalance returns the balance below which the given minor native prize (2 or 3) is limited to a
+// fraction of the balance rather than paying its full multiple of CostToSpin.
+func (m *PotModel) LimitBalance(prizeIndex int) float64 {
+	switch prizeIndex {
+	case 2:
+		return MinorTripleCostMultiple * m.CostToSpin * float64(uint64(1)<<MinorTripleBalanceShift)
+	case 3:
+		return MinorMajorCostMultiple * m.CostToSpin * float64(uint64(1)<<MinorMajorBalanceShift)
+	default:
+		return 0
+	}
+}
+
+// prizeProbability returns the probability that a spin wins the given prize, mixing boosted and
+// unboosted spins.
+func (m *PotModel) prizeProbability(prizeIndex int) float64 {
+	return (1-m.BoostFraction)*m.Unboosted[prizeIndex] + m.BoostFraction*m.Boosted[prizeIndex]
+}
+
+// expectedPayout returns the expected native tokens paid out by a spin accepted when the pot holds
+// balance.
+func (m *PotModel) expectedPayout(balance float64) float64 {
+	payout := 0.0
+	for i := 0; i < NumPrizes; i++ {
+		amount, _ := m.PrizeAmount(i, balance)
+		payout += m.prizeProbability(i) * amount
+	}
+	return payout
+}
+
+// ExpectedCost returns the expected native tokens paid into the pot by a spin.
+func (m *PotModel) ExpectedCost() float64 {
+	return (1-m.RespinFraction)*m.CostToSpin + m.RespinFraction*m.CostToRespin
+}
+
+// ExpectedPayout returns the expected native tokens paid out of the pot by a spin made when the pot
+// holds balance.
+func (m *PotModel) ExpectedPayout(balance float64) float64 {
+	return (1-m.RespinFraction)*m.expectedPayout(balance+m.CostToSpin) + m.RespinFraction*m.expectedPayout(balance+m.CostToRespin)
+}
+
+// ExpectedDrift returns the expected change in the pot from a spin made when the pot holds balance.
+func (m *PotModel) ExpectedDrift(balance float64) float64 {
+	return m.ExpectedCost() - m.ExpectedPayout(balance)
+}
+
+// EquilibriumBalance returns the balance at which the expected drift is zero. Below it the pot is
+// expected to grow, and above it to shrink. It returns +Inf if the pot is expected to grow without
+// bound (i.e. if no prize pays a fraction of the balance).
+func (m *PotModel) EquilibriumBalance() float64 {
+	if m.ExpectedDrift(0) <= 0 {
+		return 0
+	}
+	high := math.Max(m.CostToSpin, 1)
+	for m.ExpectedDrift(high) > 0 {
+		high *= 2
+		if math.IsInf(high, 1) {
+			return high
+		}
+	}
+	// The drift decreases as the balance grows, so the equilibrium can be found by bisection.
+	low := 0.0
+	for i := 0; i < 200 && high-low > 1; i++ {
+		mid := (low + high) / 2
+		if m.ExpectedDrift(mid) > 0 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return high
+}
+
+// PotSimulation summarizes Monte Carlo simulations of a pot.
+type PotSimulation struct {
+	Trials    int
+	Spins     int
+	Threshold float64
+	// Fraction of trials in which the pot fell below Threshold at some point.
+	BelowThreshold float64
+	// Mean and percentiles of the pot after Spins spins.
+	MeanFinal float64
+	P5Final   float64
+	P50Final  float64
+	P95Final  float64
+	// Number of times each prize was won across all trials, and the number of those payouts which
+	// were limited to a fraction of the balance.
+	Wins    [NumPrizes]int
+	Limited [NumPrizes]int
+}
+
+// Simulate runs trials simulations of spins spins, starting from the given balance.
+func (m *PotModel) Simulate(balance float64, spins, trials int, threshold float64, rng *rand.Rand) PotSimulation {
+	result := PotSimulation{Trials: trials, Spins: spins, Threshold: threshold}
+	if trials <= 0 {
+		return result
+	}
+
+	var unboostedCDF, boostedCDF [NumPrizes]float64
+	cumulativeUnboosted, cumulativeBoosted := 0.0, 0.0
+	for i := 0; i < NumPrizes; i++ {
+		cumulativeUnboosted += m.Unboosted[i]
+		cumulativeBoosted += m.Boosted[i]
+		unboostedCDF[i] = cumulativeUnboosted
+		boostedCDF[i] = cumulativeBoosted
+	}
+
+	finals := make([]float64, trials)
+	below := 0
+	total := 0.0
+	for trial := 0; trial < trials; trial++ {
+		pot := balance
+		wentBelow := pot < threshold
+		for spin := 0; spin < spins; spin++ {
+			if rng.Float64() < m.RespinFraction {
+				pot += m.CostToRespin
+			} else {
+				pot += m.CostToSpin
+			}
+
+			cdf := &unboostedCDF
+			if rng.Float64() < m.BoostFraction {
+				cdf = &boostedCDF
+			}
+			sample := rng.Float64()
+			for i := 0; i < NumPrizes; i++ {
+				if sample < cdf[i] {
+					amount, limited := m.PrizeAmount(i, pot)
+					pot -= amount
+					result.Wins[i]++
+					if limited {
+						result.Limited[i]++
+					}
+					break
+				}
+			}
+
+			if pot < threshold {
+				wentBelow = true
+			}
+		}
+		if wentBelow {
+			below++
+		}
+		finals[trial] = pot
+		total += pot
+	}
+
+	sort.Float64s(finals)
+	percentile := func(p float64) float64 {
+		return finals[int(p*float64(len(finals)-1))]
+	}
+	result.BelowThreshold = float64(below) / float64(trials)
+	result.MeanFinal = total / float64(trials)
+	result.P5Final = percentile(0.05)
+	result.P50Final = percentile(0.5)
+	result.P95Final = percentile(0.95)
+	return result
+}
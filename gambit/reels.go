@@ -0,0 +1,97 @@
+package gambit
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Number of bits of entropy each reel samples from. The left reel samples bits 60-89 of a spin's
+// entropy, the center reel bits 30-59, and the right reel bits 0-29.
+const ReelBits = 30
+
+// Offsets (in bits) of the samples of each reel in a spin's entropy.
+const (
+	LeftReelShift   = 2 * ReelBits
+	CenterReelShift = ReelBits
+	RightReelShift  = 0
+)
+
+// Mask which selects a reel's sample once it has been shifted to the low bits of the entropy.
+var reelSampleMask = big.NewInt(1<<ReelBits - 1)
+
+// ErrOutcomeOutOfBounds mirrors the contract's OutcomeOutOfBounds error. It is returned when asked for
+// the entropy of an outcome with a symbol that does not exist.
+var ErrOutcomeOutOfBounds error = errors.New("outcome out of bounds")
+
+// UnmodifiedReels are the CMFs of the reels used for unboosted spins, as initialized by the
+// DegenGambit constructor (UnmodifiedLeftReel, UnmodifiedCenterReel, and UnmodifiedRightReel).
+var UnmodifiedReels = Reels{
+	Left:   ReelCMF{24970744, 124853704, 174795184, 224736664, 324619624, 374561104, 424502584, 524385544, 574327024, 624268504, 724151464, 774092944, 824034424, 923917384, 973858864, 1023800344, 1048771084, 1061256454, 1073741824},
+	Center: ReelCMF{24970744, 74912224, 174795184, 224736664, 274678144, 374561104, 424502584, 474444064, 574327024, 624268504, 674209984, 774092944, 824034424, 873975904, 973858864, 1023800344, 1036285714, 1061256454, 1073741824},
+	Right:  ReelCMF{24970744, 74912224, 124853704, 224736664, 274678144, 324619624, 424502584, 474444064, 524385544, 624268504, 674209984, 724151464, 824034424, 873975904, 923917384, 1023800344, 1036285714, 1048771084, 1073741824},
+}
+
+// ImprovedReels are the CMFs of the reels used for boosted spins, as initialized by the DegenGambit
+// constructor (ImprovedLeftReel, ImprovedCenterReel, and ImprovedRightReel).
+var ImprovedReels = Reels{
+	Left:   ReelCMF{2526414, 104594597, 155628664, 206662731, 308730914, 359764981, 410799048, 512867231, 563901298, 614935365, 717003548, 768037615, 819071682, 921139865, 972173932, 1023207999, 1048474912, 1061108368, 1073741824},
+	Center: ReelCMF{2526414, 53560481, 155628664, 206662731, 257696798, 359764981, 410799048, 461833115, 563901298, 614935365, 665969432, 768037615, 819071682, 870105749, 972173932, 1023207999, 1035841455, 1061108368, 1073741824},
+	Right:  ReelCMF{2526414, 53560481, 104594548, 206662731, 257696798, 308730865, 410799048, 461833115, 512867182, 614935365, 665969432, 717003499, 819071682, 870105749, 921139816, 1023207999, 1035841455, 1048474911, 1073741824},
+}
+
+// DefaultReels returns UnmodifiedReels, or ImprovedReels if boosted is true.
+func DefaultReels(boosted bool) Reels {
+	if boosted {
+		return ImprovedReels
+	}
+	return UnmodifiedReels
+}
+
+// Symbol mirrors the contract's sample*Reel methods. It returns the symbol the reel shows for the
+// given 30-bit sample.
+func (cmf ReelCMF) Symbol(sample uint64) uint64 {
+	for symbol := uint64(0); symbol < NumSymbols-1; symbol++ {
+		if sample < cmf[symbol] {
+			return symbol
+		}
+	}
+	return NumSymbols - 1
+}
+
+// SampleForOutcome mirrors the DevDegenGambit contract's getSampleForOutcome method. It returns the
+// smallest sample for which the reel shows the given symbol: 0 for the null symbol, and otherwise the
+// CMF value of the previous symbol. The symbol must be less than NumSymbols.
+func (cmf ReelCMF) SampleForOutcome(symbol uint64) uint64 {
+	if symbol == 0 {
+		return 0
+	}
+	return cmf[symbol-1]
+}
+
+// Outcome mirrors the contract's outcome method. It returns the symbols shown by the left, center, and
+// right reels for a spin with the given entropy.
+func (reels Reels) Outcome(entropy *big.Int) (left, center, right uint64) {
+	return reels.Left.Symbol(reelSample(entropy, LeftReelShift)),
+		reels.Center.Symbol(reelSample(entropy, CenterReelShift)),
+		reels.Right.Symbol(reelSample(entropy, RightReelShift))
+}
+
+// EntropyForOutcome mirrors the DevDegenGambit contract's generateEntropyForUnmodifiedReelOutcome
+// and generateEntropyForImprovedReelOutcome methods (for UnmodifiedReels and ImprovedReels
+// respectively). It returns the smallest entropy for which a spin shows the given symbols.
+func (reels Reels) EntropyForOutcome(left, center, right uint64) (*big.Int, error) {
+	if left >= NumSymbols || center >= NumSymbols || right >= NumSymbols {
+		return nil, ErrOutcomeOutOfBounds
+	}
+
+	entropy := new(big.Int).Lsh(new(big.Int).SetUint64(reels.Left.SampleForOutcome(left)), LeftReelShift)
+	entropy.Or(entropy, new(big.Int).Lsh(new(big.Int).SetUint64(reels.Center.SampleForOutcome(center)), CenterReelShift))
+	entropy.Or(entropy, new(big.Int).SetUint64(reels.Right.SampleForOutcome(right)))
+	return entropy, nil
+}
+
+// reelSample extracts the 30-bit sample at the given offset from a spin's entropy.
+func reelSample(entropy *big.Int, shift uint) uint64 {
+	sample := new(big.Int).Rsh(entropy, shift)
+	return sample.And(sample, reelSampleMask).Uint64()
+}
@@ -0,0 +1,56 @@
+package gambit
+
+// Streak arithmetic used by the contract's _streaks method.
+const (
+	SecondsPerDay = 86400
+	DaysPerWeek   = 7
+)
+
+// Day returns the streak day (as used by LastStreakDay) containing the given Unix timestamp. Days
+// start at 00:00 UTC.
+func Day(timestamp uint64) uint64 {
+	return timestamp / SecondsPerDay
+}
+
+// Week returns the streak week (as used by LastStreakWeek) containing the given streak day. Weeks
+// start at 00:00 UTC on Thursdays, because day 0 (1 January 1970) was a Thursday.
+func Week(day uint64) uint64 {
+	return day / DaysPerWeek
+}
+
+// What a spin in the current period does to a streak.
+type StreakStatus string
+
+const (
+	// The player spun in the previous period, so a spin in the current period extends their streak
+	// and earns the streak reward.
+	StreakExtends StreakStatus = "extends"
+	// The player has already spun in the current period. Spinning again has no effect on the streak.
+	StreakCurrent StreakStatus = "current"
+	// The player last spun before the previous period, so their streak is broken. Spinning in the
+	// current period resets the streak length to 0 and starts a new streak.
+	StreakBroken StreakStatus = "broken"
+)
+
+// StreakStatusAt returns what a spin in period current (a day or a week) does to a streak whose last
+// spin was in period last (LastStreakDay or LastStreakWeek).
+func StreakStatusAt(last, current uint64) StreakStatus {
+	switch {
+	case last >= current:
+		return StreakCurrent
+	case last+1 == current:
+		return StreakExtends
+	default:
+		return StreakBroken
+	}
+}
+
+// EffectiveStreakLength returns the length of a streak as of period current. The contract only resets
+// CurrentDailyStreakLength and CurrentWeeklyStreakLength when the player next spins, so a broken streak
+// still reports its old length until then.
+func EffectiveStreakLength(length, last, current uint64) uint64 {
+	if StreakStatusAt(last, current) == StreakBroken {
+		return 0
+	}
+	return length
+}
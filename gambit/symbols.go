@@ -0,0 +1,143 @@
+package gambit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Number of symbols on each reel. Symbol 0 is the null symbol, symbols 1-15 are minor symbols, and
+// symbols 16-18 are major symbols.
+const (
+	NumSymbols     = 19
+	MaxMinorSymbol = 15
+)
+
+// Types of prize, as returned by payout and prizes.
+const (
+	PrizeTypeNative = 1
+	PrizeTypeGambit = 20
+)
+
+// Index of the jackpot (three of a kind with a major symbol) in prizes().
+const JackpotPrizeIndex = 6
+
+// Names of the reel symbols, as described in the comments on the reel CMFs in DegenGambit.sol.
+var SymbolNames = [NumSymbols]string{
+	"Null",
+	"Gold star",
+	"Diamonds (suit)",
+	"Clubs (suit)",
+	"Spades (suit)",
+	"Hearts (suit)",
+	"Diamond (gem)",
+	"Banana",
+	"Cherry",
+	"Pineapple",
+	"Orange",
+	"Apple",
+	"Bell",
+	"Gold coin",
+	"Crescent moon",
+	"Full moon",
+	"Gold 7",
+	"Red 7",
+	"Diamond 7",
+}
+
+// Descriptions of the prizes, indexed in the same way as prizes().
+var PrizeDescriptions = [7]string{
+	"Major symbol (Major Gambit)",
+	"Minor pair on the outside, different minor in the center (Minor Gambit)",
+	"Three of a kind, minor symbol",
+	"Minor pair on the outside, major in the center",
+	"Major pair on the outside, different major in the center",
+	"Three distinct major symbols",
+	"Three of a kind, major symbol (Jackpot)",
+}
+
+// SymbolName returns the name of the given reel symbol.
+func SymbolName(symbol uint64) string {
+	if symbol >= NumSymbols {
+		return fmt.Sprintf("Unknown symbol %d", symbol)
+	}
+	return SymbolNames[symbol]
+}
+
+// IsMajorSymbol returns true if the given symbol is one of the 7s.
+func IsMajorSymbol(symbol uint64) bool {
+	return symbol > MaxMinorSymbol && symbol < NumSymbols
+}
+
+// PrizeTypeName returns "native" or "GAMBIT" for the given type of prize.
+func PrizeTypeName(typeOfPrize uint64) string {
+	switch typeOfPrize {
+	case PrizeTypeNative:
+		return "native"
+	case PrizeTypeGambit:
+		return "GAMBIT"
+	default:
+		return fmt.Sprintf("unknown (%d)", typeOfPrize)
+	}
+}
+
+// PrizeIndex mirrors the case analysis in the contract's payout method. It returns the index (in
+// prizes()) of the prize awarded for the given reel outcome, and false if the outcome does not win a
+// prize.
+func PrizeIndex(left, center, right uint64) (int, bool) {
+	if left >= NumSymbols || center >= NumSymbols || right >= NumSymbols {
+		return 0, false
+	}
+	if left == 0 || center == 0 || right == 0 {
+		return 0, false
+	}
+
+	switch {
+	case left == right && left != center && left <= MaxMinorSymbol && center <= MaxMinorSymbol:
+		return 1, true
+	case left == right && left == center && left <= MaxMinorSymbol:
+		return 2, true
+	case left == right && IsMajorSymbol(center) && left <= MaxMinorSymbol:
+		return 3, true
+	case left != right && center != left && center != right && IsMajorSymbol(left) && IsMajorSymbol(center) && IsMajorSymbol(right):
+		return 5, true
+	case left == right && left != center && IsMajorSymbol(left) && IsMajorSymbol(center):
+		return 4, true
+	case left == center && center == right && IsMajorSymbol(left):
+		return JackpotPrizeIndex, true
+	case IsMajorSymbol(left) || IsMajorSymbol(center) || IsMajorSymbol(right):
+		return 0, true
+	}
+	return 0, false
+}
+
+// ExampleOutcomes holds, for each prize (indexed in the same way as prizes()), a reel outcome which
+// wins that prize. Every symbol used has a non-zero probability on both the unmodified and the
+// improved reels.
+var ExampleOutcomes = [NumPrizes][3]uint64{
+	{1, 16, 2},
+	{1, 2, 1},
+	{1, 1, 1},
+	{1, 16, 1},
+	{16, 17, 16},
+	{16, 17, 18},
+	{18, 18, 18},
+}
+
+// ParseSymbol resolves a reel symbol given either by its name (case-insensitive, e.g. "red 7") or by
+// its number (e.g. "17").
+func ParseSymbol(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	if symbol, parseErr := strconv.ParseUint(value, 10, 64); parseErr == nil {
+		if symbol >= NumSymbols {
+			return 0, fmt.Errorf("symbol must be less than %d (got %d)", NumSymbols, symbol)
+		}
+		return symbol, nil
+	}
+	for symbol, name := range SymbolNames {
+		if strings.EqualFold(name, value) {
+			return uint64(symbol), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown symbol: %q", value)
+}
@@ -0,0 +1,63 @@
+package gambit
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Number of decimals used by GAMBIT and by the native token.
+const Decimals = 18
+
+// FormatUnits formats an amount given in the token's smallest unit as a decimal number, e.g.
+// 2500000000000000000 with 18 decimals is formatted as "2.5". Trailing zeros are dropped.
+func FormatUnits(amount *big.Int, decimals int) string {
+	if amount == nil {
+		return "0"
+	}
+
+	negative := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole := digits[:len(digits)-decimals]
+	fraction := strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	result := whole
+	if fraction != "" {
+		result += "." + fraction
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// ParseUnits parses a decimal number into an amount in the token's smallest unit, e.g. "2.5" with 18
+// decimals is parsed as 2500000000000000000. It is the inverse of FormatUnits for non-negative amounts.
+// Amounts with more fractional digits than the token has decimals are rejected rather than rounded.
+func ParseUnits(value string, decimals int) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	whole, fraction, hasPoint := strings.Cut(value, ".")
+	if whole == "" && (!hasPoint || fraction == "") {
+		return nil, fmt.Errorf("invalid amount: %q", value)
+	}
+	for _, part := range []string{whole, fraction} {
+		for _, digit := range part {
+			if digit < '0' || digit > '9' {
+				return nil, fmt.Errorf("invalid amount: %q", value)
+			}
+		}
+	}
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("invalid amount: %q has more than %d decimal places", value, decimals)
+	}
+
+	amount, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %q", value)
+	}
+	return amount, nil
+}
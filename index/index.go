@@ -0,0 +1,339 @@
+// Package index maintains a local index of the events emitted by a DegenGambit contract.
+//
+// The contract only remembers the last winner of each prize and the current state of each player, so
+// anything historical (lifetime statistics, leaderboards, statements) has to be reconstructed from
+// its events. The index is stored as a JSON file and is brought up to date incrementally by Sync.
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/atomicfile"
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+)
+
+// Maximum number of blocks scanned in a single log query.
+const MaxBlocksPerQuery = 10000
+
+// Kinds of streak events.
+const (
+	StreakDaily  = "daily"
+	StreakWeekly = "weekly"
+)
+
+// Event contains the information common to every indexed event.
+type Event struct {
+	Player          common.Address `json:"player"`
+	BlockNumber     uint64         `json:"blockNumber"`
+	Timestamp       uint64         `json:"timestamp"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	LogIndex        uint           `json:"logIndex"`
+}
+
+// Spin is an indexed Spin event.
+type Spin struct {
+	Event
+	Boosted bool `json:"boosted"`
+	// Account which sent the spin transaction. This is the spin player unless the spin was made with
+	// spinFor.
+	Payer common.Address `json:"payer"`
+	// Native tokens sent with the spin transaction. Nil if the contract was called indirectly, in which
+	// case the value paid for the spin is not known.
+	Value *big.Int `json:"value,omitempty"`
+}
+
+// Award is an indexed Award event. Accepts which did not win a prize are recorded with a zero value.
+type Award struct {
+	Event
+	Value *big.Int `json:"value"`
+	// True if the prize was paid in native tokens, false if it was minted as GAMBIT.
+	Native bool `json:"native"`
+	// Index (in prizes()) of the prize that was won, once it has been determined by ResolvePrizes.
+	PrizeIndex *int `json:"prizeIndex,omitempty"`
+}
+
+// Streak is an indexed DailyStreak or WeeklyStreak event.
+type Streak struct {
+	Event
+	Kind string `json:"kind"`
+	// Day (for daily streaks) or week (for weekly streaks) on which the streak was extended.
+	Period uint64 `json:"period"`
+}
+
+// TokenTransfer is an indexed GAMBIT mint or burn. For mints, Player is the recipient. For burns, it is
+// the account whose tokens were burned.
+type TokenTransfer struct {
+	Event
+	Value *big.Int `json:"value"`
+	// For mints, whether the mint paid out a GAMBIT prize (as opposed to a streak reward).
+	Prize bool `json:"prize,omitempty"`
+}
+
+// Index holds the events emitted by a DegenGambit contract from StartBlock up to (but not including)
+// NextBlock.
+type Index struct {
+	Contract   common.Address `json:"contract"`
+	StartBlock uint64         `json:"startBlock"`
+	NextBlock  uint64         `json:"nextBlock"`
+	Spins      []Spin         `json:"spins"`
+	Awards     []Award        `json:"awards"`
+	Streaks    []Streak       `json:"streaks"`
+	// GAMBIT minted (as prizes and streak rewards) and burned (by boosted spins).
+	Mints []TokenTransfer `json:"mints"`
+	Burns []TokenTransfer `json:"burns"`
+}
+
+// New creates an empty index for the given contract which starts at startBlock.
+func New(contract common.Address, startBlock uint64) *Index {
+	return &Index{Contract: contract, StartBlock: startBlock, NextBlock: startBlock}
+}
+
+// Load reads an index from the given file.
+func Load(path string) (*Index, error) {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	var idx Index
+	if err := json.Unmarshal(contents, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse index from %s: %v", path, err)
+	}
+	return &idx, nil
+}
+
+// Open reads the index from the given file if it exists. Otherwise, it creates an empty index for the
+// given contract starting at startBlock. It is an error to open an index for a different contract.
+func Open(path string, contract common.Address, startBlock uint64) (*Index, error) {
+	idx, loadErr := Load(path)
+	if errors.Is(loadErr, os.ErrNotExist) {
+		return New(contract, startBlock), nil
+	} else if loadErr != nil {
+		return nil, loadErr
+	}
+
+	if idx.Contract != contract {
+		return nil, fmt.Errorf("index in %s is for contract %s, not %s", path, idx.Contract.Hex(), contract.Hex())
+	}
+	return idx, nil
+}
+
+// Save writes the index to the given file. The file is replaced atomically so that a crash while
+// saving does not corrupt the previous index.
+func (idx *Index) Save(path string) error {
+	contents, marshalErr := json.Marshal(idx)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	return atomicfile.WriteFile(path, contents)
+}
+
+// Sync adds the events emitted by the contract from NextBlock up to and including toBlock to the
+// index. Progress messages are written to out (if it is not nil). If the context is cancelled, the
+// index remains consistent and contains every block before its NextBlock.
+func (idx *Index) Sync(ctx context.Context, client *ethclient.Client, toBlock uint64, out io.Writer) error {
+	contract, contractErr := DegenGambit.NewDegenGambit(idx.Contract, client)
+	if contractErr != nil {
+		return contractErr
+	}
+
+	contractABI, abiErr := DegenGambit.DegenGambitMetaData.GetAbi()
+	if abiErr != nil {
+		return abiErr
+	}
+	spinTopic := contractABI.Events["Spin"].ID
+	awardTopic := contractABI.Events["Award"].ID
+	dailyStreakTopic := contractABI.Events["DailyStreak"].ID
+	weeklyStreakTopic := contractABI.Events["WeeklyStreak"].ID
+	transferTopic := contractABI.Events["Transfer"].ID
+
+	timestamps := make(map[uint64]uint64)
+	timestamp := func(blockNumber uint64) (uint64, error) {
+		if t, ok := timestamps[blockNumber]; ok {
+			return t, nil
+		}
+		header, headerErr := client.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+		if headerErr != nil {
+			return 0, fmt.Errorf("failed to get header for block %d: %v", blockNumber, headerErr)
+		}
+		timestamps[blockNumber] = header.Time
+		return header.Time, nil
+	}
+
+	for idx.NextBlock <= toBlock {
+		start := idx.NextBlock
+		end := toBlock
+		if end-start+1 > MaxBlocksPerQuery {
+			end = start + MaxBlocksPerQuery - 1
+		}
+
+		logs, filterErr := client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{idx.Contract},
+		})
+		if filterErr != nil {
+			return fmt.Errorf("failed to get logs from blocks %d-%d: %v", start, end, filterErr)
+		}
+
+		var spins []Spin
+		var awards []Award
+		var streaks []Streak
+		var mints, burns []TokenTransfer
+		// Indices (in mints) of the GAMBIT mints in each transaction which have not been matched to an
+		// award yet, used to tell GAMBIT prizes apart from native ones.
+		unmatchedMints := make(map[common.Hash][]int)
+
+		for _, log := range logs {
+			if log.Removed || len(log.Topics) == 0 {
+				continue
+			}
+
+			event := Event{BlockNumber: log.BlockNumber, TransactionHash: log.TxHash, LogIndex: log.Index}
+
+			switch log.Topics[0] {
+			case spinTopic:
+				spin, parseErr := contract.ParseSpin(log)
+				if parseErr != nil {
+					return parseErr
+				}
+				event.Player = spin.Player
+				spins = append(spins, Spin{Event: event, Boosted: spin.Bonus})
+			case awardTopic:
+				award, parseErr := contract.ParseAward(log)
+				if parseErr != nil {
+					return parseErr
+				}
+				event.Player = award.Player
+				native := false
+				if award.Value.Sign() > 0 {
+					native = !matchMint(mints, unmatchedMints, award)
+				}
+				awards = append(awards, Award{Event: event, Value: award.Value, Native: native})
+			case dailyStreakTopic:
+				streak, parseErr := contract.ParseDailyStreak(log)
+				if parseErr != nil {
+					return parseErr
+				}
+				event.Player = streak.Player
+				streaks = append(streaks, Streak{Event: event, Kind: StreakDaily, Period: streak.Day.Uint64()})
+			case weeklyStreakTopic:
+				streak, parseErr := contract.ParseWeeklyStreak(log)
+				if parseErr != nil {
+					return parseErr
+				}
+				event.Player = streak.Player
+				streaks = append(streaks, Streak{Event: event, Kind: StreakWeekly, Period: streak.Week.Uint64()})
+			case transferTopic:
+				transfer, parseErr := contract.ParseTransfer(log)
+				if parseErr != nil {
+					return parseErr
+				}
+				if transfer.From == (common.Address{}) {
+					event.Player = transfer.To
+					unmatchedMints[log.TxHash] = append(unmatchedMints[log.TxHash], len(mints))
+					mints = append(mints, TokenTransfer{Event: event, Value: transfer.Value})
+				} else if transfer.To == (common.Address{}) {
+					event.Player = transfer.From
+					burns = append(burns, TokenTransfer{Event: event, Value: transfer.Value})
+				}
+			}
+		}
+
+		// Record who paid for each spin, and how much. Transactions are read with a raw JSONRPC call
+		// because chains like Arbitrum have transaction types which go-ethereum cannot decode.
+		spinTransactions := make(map[common.Hash]*transactionSummary)
+		for i := range spins {
+			tx, ok := spinTransactions[spins[i].TransactionHash]
+			if !ok {
+				tx = new(transactionSummary)
+				if err := client.Client().CallContext(ctx, tx, "eth_getTransactionByHash", spins[i].TransactionHash); err != nil {
+					return fmt.Errorf("failed to get transaction %s: %v", spins[i].TransactionHash.Hex(), err)
+				}
+				spinTransactions[spins[i].TransactionHash] = tx
+			}
+			spins[i].Payer = tx.From
+			// The value of transactions which call the contract indirectly (e.g. through a multicall) cannot
+			// be attributed to individual spins.
+			if tx.To != nil && *tx.To == idx.Contract && tx.Value != nil {
+				spins[i].Value = tx.Value.ToInt()
+			}
+		}
+
+		events := make([]*Event, 0, len(spins)+len(awards)+len(streaks)+len(mints)+len(burns))
+		for i := range spins {
+			events = append(events, &spins[i].Event)
+		}
+		for i := range awards {
+			events = append(events, &awards[i].Event)
+		}
+		for i := range streaks {
+			events = append(events, &streaks[i].Event)
+		}
+		for i := range mints {
+			events = append(events, &mints[i].Event)
+		}
+		for i := range burns {
+			events = append(events, &burns[i].Event)
+		}
+		for _, event := range events {
+			t, err := timestamp(event.BlockNumber)
+			if err != nil {
+				return err
+			}
+			event.Timestamp = t
+		}
+
+		idx.Spins = append(idx.Spins, spins...)
+		idx.Awards = append(idx.Awards, awards...)
+		idx.Streaks = append(idx.Streaks, streaks...)
+		idx.Mints = append(idx.Mints, mints...)
+		idx.Burns = append(idx.Burns, burns...)
+		idx.NextBlock = end + 1
+
+		if out != nil {
+			fmt.Fprintf(out, "Indexed blocks %d-%d: %d spins, %d awards, %d streaks, %d mints, %d burns\n", start, end, len(spins), len(awards), len(streaks), len(mints), len(burns))
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// matchMint reports whether there is a GAMBIT mint in the award's transaction which pays the award,
+// and if so marks it as a prize. GAMBIT prizes are minted to the player immediately before the Award
+// event is emitted.
+func matchMint(mints []TokenTransfer, unmatched map[common.Hash][]int, award *DegenGambit.DegenGambitAward) bool {
+	txMints := unmatched[award.Raw.TxHash]
+	for i, mintIndex := range txMints {
+		mint := &mints[mintIndex]
+		if mint.Player == award.Player && mint.Value.Cmp(award.Value) == 0 {
+			mint.Prize = true
+			unmatched[award.Raw.TxHash] = append(txMints[:i], txMints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// transactionSummary holds the fields of a transaction that the index needs.
+type transactionSummary struct {
+	From  common.Address  `json:"from"`
+	To    *common.Address `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+}
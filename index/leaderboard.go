@@ -0,0 +1,162 @@
+package index
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// Metrics by which players can be ranked.
+const (
+	// Total native tokens won.
+	MetricNativeWon = "native-won"
+	// Largest single native token prize.
+	MetricLargestAward = "largest-award"
+	// Number of jackpots (prize 6) won.
+	MetricJackpots = "jackpots"
+	// Longest run of consecutive daily streak extensions (i.e. the highest CurrentDailyStreakLength
+	// the player reached).
+	MetricDailyStreak = "daily-streak"
+	// GAMBIT earned from daily and weekly streak rewards, as minted in the transactions which extended
+	// the player's streaks.
+	MetricStreakGambit = "streak-gambit"
+	// Number of spins.
+	MetricSpins = "spins"
+)
+
+// Metrics lists every supported leaderboard metric.
+var Metrics = []string{MetricNativeWon, MetricLargestAward, MetricJackpots, MetricDailyStreak, MetricStreakGambit, MetricSpins}
+
+// LeaderboardQuery selects the events a leaderboard is computed from.
+type LeaderboardQuery struct {
+	Metric string
+	// Only events with Since <= timestamp < Until are counted. An Until of 0 means no upper bound.
+	Since uint64
+	Until uint64
+	// Maximum number of entries to return. 0 means no limit.
+	Limit int
+}
+
+// LeaderboardEntry is a player's position on a leaderboard.
+type LeaderboardEntry struct {
+	Rank   int            `json:"rank"`
+	Player common.Address `json:"player"`
+	Value  *big.Int       `json:"value"`
+}
+
+func (q LeaderboardQuery) includes(event Event) bool {
+	return event.Timestamp >= q.Since && (q.Until == 0 || event.Timestamp < q.Until)
+}
+
+// Leaderboard ranks players by the query's metric. Players with a zero value are omitted. Ties are
+// broken by address so that the result is deterministic.
+func (idx *Index) Leaderboard(query LeaderboardQuery) ([]LeaderboardEntry, error) {
+	values := make(map[common.Address]*big.Int)
+	value := func(player common.Address) *big.Int {
+		v, ok := values[player]
+		if !ok {
+			v = big.NewInt(0)
+			values[player] = v
+		}
+		return v
+	}
+
+	switch query.Metric {
+	case MetricNativeWon, MetricLargestAward, MetricJackpots:
+		for _, award := range idx.Awards {
+			if !query.includes(award.Event) || !award.Native || award.Value.Sign() == 0 {
+				continue
+			}
+			v := value(award.Player)
+			switch query.Metric {
+			case MetricNativeWon:
+				v.Add(v, award.Value)
+			case MetricLargestAward:
+				if award.Value.Cmp(v) > 0 {
+					v.Set(award.Value)
+				}
+			case MetricJackpots:
+				if award.PrizeIndex != nil && *award.PrizeIndex == gambit.JackpotPrizeIndex {
+					v.Add(v, big.NewInt(1))
+				}
+			}
+		}
+	case MetricDailyStreak:
+		// A streak of length n consists of n DailyStreak events on consecutive days.
+		lastDay := make(map[common.Address]uint64)
+		run := make(map[common.Address]int64)
+		for _, streak := range idx.Streaks {
+			if !query.includes(streak.Event) || streak.Kind != StreakDaily {
+				continue
+			}
+			if last, ok := lastDay[streak.Player]; ok && last+1 == streak.Period {
+				run[streak.Player]++
+			} else {
+				run[streak.Player] = 1
+			}
+			lastDay[streak.Player] = streak.Period
+			v := value(streak.Player)
+			if run[streak.Player] > v.Int64() {
+				v.SetInt64(run[streak.Player])
+			}
+		}
+	case MetricStreakGambit:
+		// Streak rewards are minted to the player in the transaction which extends their streak. The
+		// minted amounts are used rather than the current DailyStreakReward and WeeklyStreakReward,
+		// which may have changed since.
+		type streakTransaction struct {
+			hash   common.Hash
+			player common.Address
+		}
+		streakTransactions := make(map[streakTransaction]bool)
+		for _, streak := range idx.Streaks {
+			if query.includes(streak.Event) {
+				streakTransactions[streakTransaction{hash: streak.TransactionHash, player: streak.Player}] = true
+			}
+		}
+		if len(streakTransactions) > 0 && len(idx.Mints) == 0 {
+			return nil, fmt.Errorf("the index does not record GAMBIT mints, which the %s leaderboard needs; re-index with --rebuild", MetricStreakGambit)
+		}
+		for _, mint := range idx.Mints {
+			if mint.Prize || !streakTransactions[streakTransaction{hash: mint.TransactionHash, player: mint.Player}] {
+				continue
+			}
+			v := value(mint.Player)
+			v.Add(v, mint.Value)
+		}
+	case MetricSpins:
+		for _, spin := range idx.Spins {
+			if query.includes(spin.Event) {
+				v := value(spin.Player)
+				v.Add(v, big.NewInt(1))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown leaderboard metric: %s", query.Metric)
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(values))
+	for player, v := range values {
+		if v.Sign() > 0 {
+			entries = append(entries, LeaderboardEntry{Player: player, Value: v})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if c := entries[i].Value.Cmp(entries[j].Value); c != 0 {
+			return c > 0
+		}
+		return entries[i].Player.Hex() < entries[j].Player.Hex()
+	})
+	if query.Limit > 0 && len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+	}
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,198 @@
+package index
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// Periods over which statements can be broken down.
+const (
+	// A single statement per player covering the whole query window.
+	PeriodAll = "all"
+	// UTC days (streak days).
+	PeriodDay = "day"
+	// Streak weeks, which start on Thursdays (UTC).
+	PeriodWeek = "week"
+	// UTC calendar months.
+	PeriodMonth = "month"
+)
+
+// Periods lists every supported statement period.
+var Periods = []string{PeriodAll, PeriodDay, PeriodWeek, PeriodMonth}
+
+// StatementQuery selects the events statements are computed from.
+type StatementQuery struct {
+	// Players to compute statements for. If empty, statements are computed for every player who
+	// appears in the index.
+	Players []common.Address
+	Period  string
+	// Only events with Since <= timestamp < Until are counted. An Until of 0 means no upper bound.
+	Since uint64
+	Until uint64
+}
+
+// Statement is a player's profit and loss over a period. Native token amounts and GAMBIT amounts are
+// in wei.
+type Statement struct {
+	Player common.Address `json:"player"`
+	// Label of the period (e.g. "2024-10-17" for a day, "2024-10" for a month, or "all"), and the
+	// timestamp at which it starts (0 for PeriodAll).
+	Period      string `json:"period"`
+	PeriodStart uint64 `json:"periodStart"`
+	// Number of spins made for the player, and the native tokens sent with them (by whoever paid).
+	Spins     int      `json:"spins"`
+	SpinValue *big.Int `json:"spinValue"`
+	// Number of the player's spins whose value is not known because the contract was called
+	// indirectly. They are not included in SpinValue.
+	UnknownValueSpins int `json:"unknownValueSpins"`
+	// Native tokens the player sent with spin transactions, including spins they paid for on behalf of
+	// other players using spinFor.
+	NativePaid *big.Int `json:"nativePaid"`
+	// Native token prizes received.
+	NativeWon *big.Int `json:"nativeWon"`
+	// GAMBIT minted to the player as prizes and as streak rewards.
+	GambitPrizes  *big.Int `json:"gambitPrizes"`
+	GambitStreaks *big.Int `json:"gambitStreaks"`
+	// GAMBIT burned from the player to boost spins.
+	GambitBurned *big.Int `json:"gambitBurned"`
+}
+
+// NativeNet returns the player's native token profit (or, if negative, loss): NativeWon - NativePaid.
+func (s *Statement) NativeNet() *big.Int {
+	return new(big.Int).Sub(s.NativeWon, s.NativePaid)
+}
+
+// GambitNet returns the player's net GAMBIT: GambitPrizes + GambitStreaks - GambitBurned.
+func (s *Statement) GambitNet() *big.Int {
+	net := new(big.Int).Add(s.GambitPrizes, s.GambitStreaks)
+	return net.Sub(net, s.GambitBurned)
+}
+
+// periodOf returns the label and start timestamp of the period containing the given timestamp.
+func periodOf(period string, timestamp uint64) (string, uint64) {
+	switch period {
+	case PeriodDay:
+		start := gambit.DayStart(gambit.Day(timestamp))
+		return start.Format("2006-01-02"), uint64(start.Unix())
+	case PeriodWeek:
+		start := gambit.WeekStart(gambit.Week(gambit.Day(timestamp)))
+		return start.Format("2006-01-02"), uint64(start.Unix())
+	case PeriodMonth:
+		t := time.Unix(int64(timestamp), 0).UTC()
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.Format("2006-01"), uint64(start.Unix())
+	default:
+		return PeriodAll, 0
+	}
+}
+
+// Statements computes profit and loss statements for the query's players, with one statement per
+// player for each period in which they had any activity. Statements are sorted by period and then by
+// player.
+//
+// Spin values are only recorded by indexes built since spin payers were added to the index; older
+// indexes must be rebuilt for them to be counted.
+func (idx *Index) Statements(query StatementQuery) ([]Statement, error) {
+	switch query.Period {
+	case PeriodAll, PeriodDay, PeriodWeek, PeriodMonth:
+	default:
+		return nil, fmt.Errorf("unknown statement period: %s", query.Period)
+	}
+
+	var players map[common.Address]bool
+	if len(query.Players) > 0 {
+		players = make(map[common.Address]bool, len(query.Players))
+		for _, player := range query.Players {
+			players[player] = true
+		}
+	}
+
+	type key struct {
+		player common.Address
+		start  uint64
+	}
+	statements := make(map[key]*Statement)
+	statement := func(player common.Address, event Event) *Statement {
+		if players != nil && !players[player] {
+			return nil
+		}
+		if event.Timestamp < query.Since || (query.Until != 0 && event.Timestamp >= query.Until) {
+			return nil
+		}
+		label, start := periodOf(query.Period, event.Timestamp)
+		k := key{player: player, start: start}
+		s, ok := statements[k]
+		if !ok {
+			s = &Statement{
+				Player:        player,
+				Period:        label,
+				PeriodStart:   start,
+				SpinValue:     big.NewInt(0),
+				NativePaid:    big.NewInt(0),
+				NativeWon:     big.NewInt(0),
+				GambitPrizes:  big.NewInt(0),
+				GambitStreaks: big.NewInt(0),
+				GambitBurned:  big.NewInt(0),
+			}
+			statements[k] = s
+		}
+		return s
+	}
+
+	for _, spin := range idx.Spins {
+		if s := statement(spin.Player, spin.Event); s != nil {
+			s.Spins++
+			if spin.Value != nil {
+				s.SpinValue.Add(s.SpinValue, spin.Value)
+			} else {
+				s.UnknownValueSpins++
+			}
+		}
+		if spin.Value == nil || spin.Value.Sign() == 0 {
+			continue
+		}
+		if s := statement(spin.Payer, spin.Event); s != nil {
+			s.NativePaid.Add(s.NativePaid, spin.Value)
+		}
+	}
+	for _, award := range idx.Awards {
+		if !award.Native || award.Value.Sign() == 0 {
+			continue
+		}
+		if s := statement(award.Player, award.Event); s != nil {
+			s.NativeWon.Add(s.NativeWon, award.Value)
+		}
+	}
+	for _, mint := range idx.Mints {
+		if s := statement(mint.Player, mint.Event); s != nil {
+			if mint.Prize {
+				s.GambitPrizes.Add(s.GambitPrizes, mint.Value)
+			} else {
+				s.GambitStreaks.Add(s.GambitStreaks, mint.Value)
+			}
+		}
+	}
+	for _, burn := range idx.Burns {
+		if s := statement(burn.Player, burn.Event); s != nil {
+			s.GambitBurned.Add(s.GambitBurned, burn.Value)
+		}
+	}
+
+	result := make([]Statement, 0, len(statements))
+	for _, s := range statements {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PeriodStart != result[j].PeriodStart {
+			return result[i].PeriodStart < result[j].PeriodStart
+		}
+		return result[i].Player.Hex() < result[j].Player.Hex()
+	})
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// ResolvePrizes determines which prize (by its index in prizes()) each winning award in the index
+// paid, for awards which have not been resolved yet.
+//
+// Award events do not say which prize was won, so the outcome of the spin being accepted is recomputed:
+// the entropy is derived from the hash of the player's last spin block (as in the contract's _entropy
+// method) and passed to the contract's outcome method. This assumes the contract uses the production
+// entropy source; it does not hold for DevDegenGambit contracts with overridden entropy. Awards whose
+// prize cannot be resolved are left unresolved and reported to out (if it is not nil).
+func (idx *Index) ResolvePrizes(ctx context.Context, client *ethclient.Client, out io.Writer) error {
+	contract, contractErr := DegenGambit.NewDegenGambit(idx.Contract, client)
+	if contractErr != nil {
+		return contractErr
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	// Find the spin that each award accepted: the player's most recent spin before the award.
+	type spinRef struct {
+		block   uint64
+		boosted bool
+	}
+	type item struct {
+		event Event
+		spin  *Spin
+		award int
+	}
+	items := make([]item, 0, len(idx.Spins)+len(idx.Awards))
+	for i := range idx.Spins {
+		items = append(items, item{event: idx.Spins[i].Event, spin: &idx.Spins[i], award: -1})
+	}
+	for i := range idx.Awards {
+		if idx.Awards[i].Value.Sign() > 0 {
+			items = append(items, item{event: idx.Awards[i].Event, award: i})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].event.BlockNumber != items[j].event.BlockNumber {
+			return items[i].event.BlockNumber < items[j].event.BlockNumber
+		}
+		return items[i].event.LogIndex < items[j].event.LogIndex
+	})
+
+	lastSpins := make(map[common.Address]spinRef)
+	blockHashes := make(map[uint64]common.Hash)
+	unresolved := 0
+
+	for _, it := range items {
+		if it.spin != nil {
+			lastSpins[it.spin.Player] = spinRef{block: it.spin.BlockNumber, boosted: it.spin.Boosted}
+			continue
+		}
+
+		award := &idx.Awards[it.award]
+		if award.PrizeIndex != nil {
+			continue
+		}
+
+		spin, ok := lastSpins[award.Player]
+		if !ok {
+			// The spin happened before the start of the index.
+			unresolved++
+			continue
+		}
+
+		blockHash, ok := blockHashes[spin.block]
+		if !ok {
+			// The block hash is read from a raw JSONRPC response rather than computed from a decoded header,
+			// because headers on chains like Arbitrum have fields which go-ethereum does not hash.
+			block := new(blockSummary)
+			if err := client.Client().CallContext(ctx, block, "eth_getBlockByNumber", hexutil.EncodeUint64(spin.block), false); err != nil {
+				return fmt.Errorf("failed to get block %d: %v", spin.block, err)
+			}
+			if block.Hash == (common.Hash{}) {
+				return fmt.Errorf("block %d not found", spin.block)
+			}
+			blockHash = block.Hash
+			blockHashes[spin.block] = blockHash
+		}
+
+		outcome, outcomeErr := contract.Outcome(callOpts, gambit.Entropy(blockHash, award.Player), spin.boosted)
+		if outcomeErr != nil {
+			return fmt.Errorf("failed to compute outcome for award in transaction %s: %v", award.TransactionHash.Hex(), outcomeErr)
+		}
+
+		prizeIndex, won := gambit.PrizeIndex(outcome.Left.Uint64(), outcome.Center.Uint64(), outcome.Right.Uint64())
+		// A recomputed outcome which disagrees with the award about the type of prize means that the
+		// contract does not use the production entropy source.
+		if !won || award.Native != (prizeIndex >= 2) {
+			unresolved++
+			continue
+		}
+		award.PrizeIndex = &prizeIndex
+	}
+
+	if unresolved > 0 && out != nil {
+		fmt.Fprintf(out, "Could not determine the prize for %d awards\n", unresolved)
+	}
+	return nil
+}
+
+// blockSummary holds the fields of a block that ResolvePrizes needs.
+type blockSummary struct {
+	Hash common.Hash `json:"hash"`
+}
@@ -0,0 +1,87 @@
+package index
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PlayerStats summarizes a player's indexed history.
+type PlayerStats struct {
+	Player       common.Address
+	Spins        int
+	BoostedSpins int
+	// Number of times the player accepted a spin, whether or not it won a prize.
+	Accepts int
+	// Number of accepts which won a prize, paid in native tokens or in GAMBIT.
+	NativeWins int
+	GambitWins int
+	NativeWon  *big.Int
+	GambitWon  *big.Int
+	// Largest single native token prize won by the player.
+	LargestNativeWin *big.Int
+	// Number of times the player extended their daily and weekly streaks.
+	DailyStreaks  int
+	WeeklyStreaks int
+	// Timestamps of the player's first and most recent spins (0 if they never spun).
+	FirstSpin uint64
+	LastSpin  uint64
+}
+
+// PlayerStats computes the lifetime statistics of the given player from the index.
+func (idx *Index) PlayerStats(player common.Address) PlayerStats {
+	stats := PlayerStats{
+		Player:           player,
+		NativeWon:        big.NewInt(0),
+		GambitWon:        big.NewInt(0),
+		LargestNativeWin: big.NewInt(0),
+	}
+
+	for _, spin := range idx.Spins {
+		if spin.Player != player {
+			continue
+		}
+		stats.Spins++
+		if spin.Boosted {
+			stats.BoostedSpins++
+		}
+		if stats.FirstSpin == 0 {
+			stats.FirstSpin = spin.Timestamp
+		}
+		stats.LastSpin = spin.Timestamp
+	}
+
+	for _, award := range idx.Awards {
+		if award.Player != player {
+			continue
+		}
+		stats.Accepts++
+		if award.Value.Sign() == 0 {
+			continue
+		}
+		if award.Native {
+			stats.NativeWins++
+			stats.NativeWon.Add(stats.NativeWon, award.Value)
+			if award.Value.Cmp(stats.LargestNativeWin) > 0 {
+				stats.LargestNativeWin = award.Value
+			}
+		} else {
+			stats.GambitWins++
+			stats.GambitWon.Add(stats.GambitWon, award.Value)
+		}
+	}
+
+	for _, streak := range idx.Streaks {
+		if streak.Player != player {
+			continue
+		}
+		switch streak.Kind {
+		case StreakDaily:
+			stats.DailyStreaks++
+		case StreakWeekly:
+			stats.WeeklyStreaks++
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,230 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/BlockInspector"
+)
+
+// Maximum number of headers requested in a single JSONRPC batch.
+const MaxHeadersPerBatch = 200
+
+// BlockTime is the number and timestamp of a block.
+type BlockTime struct {
+	Number    uint64
+	Timestamp uint64
+}
+
+// ReadBlockTimes reads the timestamps of the blocks from (and including) from up to (and including)
+// to, using batched JSONRPC requests. Only the number and timestamp of each header are decoded, so that
+// chains whose headers go-ethereum cannot fully decode are supported.
+func ReadBlockTimes(ctx context.Context, client *rpc.Client, from, to uint64) ([]BlockTime, error) {
+	type header struct {
+		Number    hexutil.Uint64 `json:"number"`
+		Timestamp hexutil.Uint64 `json:"timestamp"`
+	}
+
+	var blocks []BlockTime
+	for start := from; start <= to; start += MaxHeadersPerBatch {
+		end := start + MaxHeadersPerBatch - 1
+		if end > to {
+			end = to
+		}
+
+		headers := make([]header, end-start+1)
+		batch := make([]rpc.BatchElem, len(headers))
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.Uint64(start + uint64(i)), false},
+				Result: &headers[i],
+			}
+		}
+		if err := client.BatchCallContext(ctx, batch); err != nil {
+			return nil, fmt.Errorf("failed to get headers for blocks %d-%d: %v", start, end, err)
+		}
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return nil, fmt.Errorf("failed to get header for block %d: %v", start+uint64(i), elem.Error)
+			}
+			blocks = append(blocks, BlockTime{Number: uint64(headers[i].Number), Timestamp: uint64(headers[i].Timestamp)})
+		}
+	}
+	return blocks, nil
+}
+
+// Cadence summarizes block production over a range of blocks.
+type Cadence struct {
+	Blocks int
+	// Seconds between the first and last block.
+	Span            uint64
+	BlocksPerSecond float64
+	// Mean and standard deviation of the seconds between consecutive blocks.
+	MeanInterval   float64
+	IntervalStdDev float64
+	// Number of gaps between consecutive blocks of at least the idle threshold, and the longest gap (in
+	// seconds).
+	IdleGaps   int
+	LongestGap uint64
+}
+
+// MeasureCadence measures the cadence of the given blocks, which must be in order. Gaps between
+// consecutive blocks of at least idleGap seconds are counted as idle gaps.
+func MeasureCadence(blocks []BlockTime, idleGap uint64) Cadence {
+	cadence := Cadence{Blocks: len(blocks)}
+	if len(blocks) < 2 {
+		return cadence
+	}
+
+	cadence.Span = blocks[len(blocks)-1].Timestamp - blocks[0].Timestamp
+	if cadence.Span > 0 {
+		cadence.BlocksPerSecond = float64(len(blocks)-1) / float64(cadence.Span)
+	}
+
+	intervals := len(blocks) - 1
+	cadence.MeanInterval = float64(cadence.Span) / float64(intervals)
+	variance := 0.0
+	for i := 1; i < len(blocks); i++ {
+		gap := blocks[i].Timestamp - blocks[i-1].Timestamp
+		deviation := float64(gap) - cadence.MeanInterval
+		variance += deviation * deviation
+		if gap >= idleGap {
+			cadence.IdleGaps++
+		}
+		if gap > cadence.LongestGap {
+			cadence.LongestGap = gap
+		}
+	}
+	cadence.IntervalStdDev = math.Sqrt(variance / float64(intervals))
+
+	return cadence
+}
+
+// BlocksInWindow counts, for every window of the given number of seconds that starts at one of the
+// blocks, the blocks produced in that window, and returns the given percentile (between 0 and 1) of
+// those counts. Only windows which end before the last block are counted. It returns an error if the
+// blocks span less than the window.
+func BlocksInWindow(blocks []BlockTime, seconds uint64, percentile float64) (int, error) {
+	if seconds == 0 {
+		return 0, fmt.Errorf("the window must be at least 1 second long")
+	}
+	if len(blocks) < 2 || blocks[len(blocks)-1].Timestamp-blocks[0].Timestamp < seconds {
+		return 0, fmt.Errorf("the sampled blocks do not span a window of %d seconds", seconds)
+	}
+
+	var counts []int
+	end := 0
+	for start := range blocks {
+		windowEnd := blocks[start].Timestamp + seconds
+		if windowEnd > blocks[len(blocks)-1].Timestamp {
+			break
+		}
+		for end < len(blocks) && blocks[end].Timestamp < windowEnd {
+			end++
+		}
+		counts = append(counts, end-start)
+	}
+
+	sort.Ints(counts)
+	index := int(math.Ceil(percentile*float64(len(counts)))) - 1
+	if index < 0 {
+		index = 0
+	} else if index >= len(counts) {
+		index = len(counts) - 1
+	}
+	return counts[index], nil
+}
+
+// LiveSample is a reading of BlockInspector.blockNumbers.
+type LiveSample struct {
+	Time    time.Time
+	Numbers BlockNumbers
+}
+
+// SampleBlockNumbers reads BlockInspector.blockNumbers every interval for the given duration.
+func SampleBlockNumbers(ctx context.Context, contract *BlockInspector.BlockInspector, duration, interval time.Duration) ([]LiveSample, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(duration)
+
+	var samples []LiveSample
+	for {
+		numbers, ok, err := ReadBlockNumbers(&bind.CallOpts{Context: ctx}, contract)
+		if err != nil {
+			return samples, err
+		}
+		if !ok {
+			return samples, fmt.Errorf("blockNumbers reverted (is ArbSys available on this chain?)")
+		}
+		samples = append(samples, LiveSample{Time: time.Now(), Numbers: numbers})
+
+		if !time.Now().Before(deadline) {
+			return samples, nil
+		}
+		select {
+		case <-ctx.Done():
+			return samples, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// LiveCadence summarizes live samples of blockNumbers.
+type LiveCadence struct {
+	Duration time.Duration
+	// Number of blocks by which arbBlockNumber and block.number advanced.
+	ArbBlocks    uint64
+	ParentBlocks uint64
+	// Longest time for which arbBlockNumber did not advance.
+	LongestStall time.Duration
+	// Smallest and largest arbBlockNumber - block.number seen.
+	MinDivergence int64
+	MaxDivergence int64
+}
+
+// ArbBlocksPerSecond returns the rate at which arbBlockNumber advanced.
+func (c LiveCadence) ArbBlocksPerSecond() float64 {
+	if c.Duration <= 0 {
+		return 0
+	}
+	return float64(c.ArbBlocks) / c.Duration.Seconds()
+}
+
+// MeasureLive summarizes the given samples, which must be in order.
+func MeasureLive(samples []LiveSample) LiveCadence {
+	cadence := LiveCadence{}
+	if len(samples) == 0 {
+		return cadence
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	cadence.Duration = last.Time.Sub(first.Time)
+	cadence.ArbBlocks = last.Numbers.ArbBlockNumber - first.Numbers.ArbBlockNumber
+	cadence.ParentBlocks = last.Numbers.BlockNumber - first.Numbers.BlockNumber
+	cadence.MinDivergence, cadence.MaxDivergence = first.Numbers.Divergence(), first.Numbers.Divergence()
+
+	stallStart := first
+	for _, sample := range samples[1:] {
+		if divergence := sample.Numbers.Divergence(); divergence < cadence.MinDivergence {
+			cadence.MinDivergence = divergence
+		} else if divergence > cadence.MaxDivergence {
+			cadence.MaxDivergence = divergence
+		}
+		if sample.Numbers.ArbBlockNumber != stallStart.Numbers.ArbBlockNumber {
+			stallStart = sample
+			continue
+		}
+		if stall := sample.Time.Sub(stallStart.Time); stall > cadence.LongestStall {
+			cadence.LongestStall = stall
+		}
+	}
+	return cadence
+}
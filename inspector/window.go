@@ -0,0 +1,176 @@
+// Package inspector uses a deployed BlockInspector contract to measure the properties of a chain which
+// DegenGambit depends on: the relationship between block.number and ArbSys.arbBlockNumber, and how far
+// back block hashes remain available to contracts.
+//
+// DegenGambit derives a spin's entropy from ArbSys.arbBlockHash(LastSpinBlock), and allows the spin to
+// be accepted until arbBlockNumber exceeds LastSpinBlock + BlocksToAct. BlocksToAct must therefore not
+// exceed the number of blocks for which arbBlockHash remains available.
+package inspector
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/BlockInspector"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// Default maximum depth (in blocks) to which ProbeHashWindow searches for available block hashes.
+const DefaultMaxDepth = 1 << 20
+
+// BlockNumbers are the block numbers seen by a contract, as returned by BlockInspector.blockNumbers.
+type BlockNumbers struct {
+	// block.number. On Arbitrum chains, this approximates the number of the parent chain's block.
+	BlockNumber uint64
+	// ArbSys.arbBlockNumber, the number of the block on the chain itself.
+	ArbBlockNumber uint64
+}
+
+// Divergence returns ArbBlockNumber - BlockNumber. It is zero on chains where the two agree.
+func (n BlockNumbers) Divergence() int64 {
+	return int64(n.ArbBlockNumber) - int64(n.BlockNumber)
+}
+
+// ReadBlockNumbers reads the block numbers seen by the BlockInspector contract. ok is false if the
+// call reverted, which happens on chains without the ArbSys precompile.
+func ReadBlockNumbers(opts *bind.CallOpts, contract *BlockInspector.BlockInspector) (numbers BlockNumbers, ok bool, err error) {
+	blockNumber, arbBlockNumber, callErr := contract.BlockNumbers(opts)
+	if callErr != nil {
+		if gambit.RevertReason(callErr) != "" {
+			return numbers, false, nil
+		}
+		return numbers, false, fmt.Errorf("failed to call blockNumbers: %v", callErr)
+	}
+	return BlockNumbers{BlockNumber: blockNumber.Uint64(), ArbBlockNumber: arbBlockNumber.Uint64()}, true, nil
+}
+
+// HashWindow describes how far back block hashes are available to contracts.
+type HashWindow struct {
+	// Block numbers seen by the contract at the probed block.
+	At BlockNumbers
+	// Whether the ArbSys precompile is available.
+	ArbSys bool
+	// Largest k for which arbBlockHash(arbBlockNumber - k) returns a hash (0 if it never does), and
+	// largest k for which blockhash(block.number - k) returns a non-zero hash.
+	ArbBlockHashDepth uint64
+	BlockhashDepth    uint64
+	// Whether the search stopped at the maximum depth, in which case the true depths may be larger.
+	ArbBlockHashUnbounded bool
+	BlockhashUnbounded    bool
+	// Number of calls made to the contract.
+	Probes int
+}
+
+// ProbeHashWindow measures how far back block hashes are available, by searching for the largest
+// depth k at which BlockInspector.arbBlockHash(arbBlockNumber - k) and BlockInspector.hash(block.number -
+// k) still return hashes. All calls are made against the state at opts.BlockNumber, which must be set,
+// so that the chain does not advance during the search. Depths greater than maxDepth are not probed.
+func ProbeHashWindow(opts *bind.CallOpts, contract *BlockInspector.BlockInspector, maxDepth uint64) (HashWindow, error) {
+	window := HashWindow{}
+	if opts.BlockNumber == nil || !opts.BlockNumber.IsUint64() {
+		return window, fmt.Errorf("the block to probe at must be specified")
+	}
+
+	numbers, ok, numbersErr := ReadBlockNumbers(opts, contract)
+	window.Probes++
+	if numbersErr != nil {
+		return window, numbersErr
+	}
+	window.At = numbers
+	window.ArbSys = ok
+
+	if window.ArbSys {
+		depth, unbounded, probes, err := searchDepth(window.At.ArbBlockNumber, maxDepth, func(number uint64) (bool, error) {
+			hash, callErr := contract.ArbBlockHash(opts, new(big.Int).SetUint64(number))
+			if callErr != nil {
+				if gambit.RevertReason(callErr) != "" {
+					return false, nil
+				}
+				return false, fmt.Errorf("failed to call arbBlockHash(%d): %v", number, callErr)
+			}
+			return common.Hash(hash) != (common.Hash{}), nil
+		})
+		window.Probes += probes
+		if err != nil {
+			return window, err
+		}
+		window.ArbBlockHashDepth, window.ArbBlockHashUnbounded = depth, unbounded
+	}
+
+	blockNumber := window.At.BlockNumber
+	if !window.ArbSys {
+		// Without ArbSys, blockNumbers reverts, but block.number is simply the number of the probed block.
+		blockNumber = opts.BlockNumber.Uint64()
+		window.At = BlockNumbers{BlockNumber: blockNumber, ArbBlockNumber: blockNumber}
+	}
+	depth, unbounded, probes, err := searchDepth(blockNumber, maxDepth, func(number uint64) (bool, error) {
+		hash, callErr := contract.Hash(opts, new(big.Int).SetUint64(number))
+		if callErr != nil {
+			return false, fmt.Errorf("failed to call hash(%d): %v", number, callErr)
+		}
+		return common.Hash(hash) != (common.Hash{}), nil
+	})
+	window.Probes += probes
+	if err != nil {
+		return window, err
+	}
+	window.BlockhashDepth, window.BlockhashUnbounded = depth, unbounded
+
+	return window, nil
+}
+
+// searchDepth finds the largest k in [1, min(maxDepth, current)] for which available(current - k) is
+// true, assuming that availability is contiguous from k = 1. It returns 0 if available(current - 1) is
+// false. unbounded is true if every probed depth up to the limit was available.
+func searchDepth(current, maxDepth uint64, available func(number uint64) (bool, error)) (depth uint64, unbounded bool, probes int, err error) {
+	limit := maxDepth
+	if current < limit {
+		limit = current
+	}
+	if limit == 0 {
+		return 0, false, 0, nil
+	}
+
+	probe := func(k uint64) (bool, error) {
+		probes++
+		return available(current - k)
+	}
+
+	// Exponential search for an unavailable depth, followed by a binary search between the deepest
+	// available depth and the shallowest unavailable one.
+	low, high := uint64(0), uint64(0)
+	for k := uint64(1); ; k *= 2 {
+		if k > limit {
+			k = limit
+		}
+		ok, probeErr := probe(k)
+		if probeErr != nil {
+			return 0, false, probes, probeErr
+		}
+		if !ok {
+			high = k
+			break
+		}
+		low = k
+		if k == limit {
+			return limit, true, probes, nil
+		}
+	}
+
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		ok, probeErr := probe(mid)
+		if probeErr != nil {
+			return 0, false, probes, probeErr
+		}
+		if ok {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return low, false, probes, nil
+}
@@ -0,0 +1,398 @@
+// Package keeper implements a daemon which accepts winning spins on behalf of players who have opted
+// in, so that they do not lose their prizes by failing to accept within BlocksToAct blocks of their
+// spin.
+//
+// The keeper watches Spin events for its players. For each spin that HasPrize reports as a winner, it
+// submits acceptFor(player) before the LastSpinBlock + BlocksToAct deadline. Spins which the player
+// already accepted themselves are skipped.
+package keeper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// Default interval at which the keeper checks the chain for new spins.
+const DefaultPollInterval = 2 * time.Second
+
+// Maximum number of blocks scanned for Spin events in a single log query.
+const MaxBlocksPerQuery = 10000
+
+// Config describes the players a keeper works for.
+type Config struct {
+	// Address of the DegenGambit contract.
+	Contract common.Address
+	// Players who have opted in to have their winning spins accepted by the keeper.
+	Players []common.Address
+	// Block from which to start looking for spins. If 0, the keeper starts BlocksToAct blocks before
+	// the current block so that spins made while it was offline are not missed.
+	FromBlock uint64
+	// Interval at which the keeper checks for new spins.
+	PollInterval time.Duration
+}
+
+// Stats summarizes the work the keeper has done.
+type Stats struct {
+	// Number of acceptFor transactions which were mined successfully.
+	Claims int
+	// Number of acceptFor transactions which reverted.
+	FailedClaims int
+	// Number of winning spins whose deadline passed before they could be claimed.
+	Missed int
+	// Number of spins which were accepted by somebody else before the keeper could claim them.
+	Skipped int
+	// Total gas used, and total fees paid, by the keeper's acceptFor transactions.
+	GasUsed  uint64
+	GasSpent *big.Int
+	// Number of claims made for each player.
+	ClaimsByPlayer map[common.Address]int
+}
+
+// spin is a spin the keeper is watching.
+type spin struct {
+	player    common.Address
+	spinBlock uint64
+	// Whether HasPrize reported the spin as a winner.
+	winner bool
+	claim  *types.Transaction
+	// Hashes of the claim and of the fee-bumped replacements the transaction manager made for it.
+	claimHashes []common.Hash
+}
+
+// Keeper claims winning spins on behalf of players.
+type Keeper struct {
+	client      *ethclient.Client
+	contract    *DegenGambit.DegenGambit
+	manager     *txmanager.Manager
+	config      Config
+	out         io.Writer
+	blocksToAct uint64
+
+	mu      sync.Mutex
+	stats   Stats
+	watched map[common.Address]*spin
+	scanned uint64
+}
+
+// New creates a keeper which submits its transactions through the given transaction manager. Progress
+// messages are written to out.
+func New(client *ethclient.Client, manager *txmanager.Manager, config Config, out io.Writer) (*Keeper, error) {
+	contract, contractErr := DegenGambit.NewDegenGambit(config.Contract, client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	return &Keeper{
+		client:   client,
+		contract: contract,
+		manager:  manager,
+		config:   config,
+		out:      out,
+		stats: Stats{
+			GasSpent:       big.NewInt(0),
+			ClaimsByPlayer: make(map[common.Address]int),
+		},
+		watched: make(map[common.Address]*spin),
+	}, nil
+}
+
+// Stats returns a copy of the keeper's statistics.
+func (k *Keeper) Stats() Stats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	stats := k.stats
+	stats.GasSpent = new(big.Int).Set(k.stats.GasSpent)
+	stats.ClaimsByPlayer = make(map[common.Address]int, len(k.stats.ClaimsByPlayer))
+	for player, claims := range k.stats.ClaimsByPlayer {
+		stats.ClaimsByPlayer[player] = claims
+	}
+	return stats
+}
+
+// Run watches for spins and claims prizes until the context is cancelled.
+func (k *Keeper) Run(ctx context.Context) error {
+	blocksToAct, blocksToActErr := k.contract.BlocksToAct(&bind.CallOpts{Context: ctx})
+	if blocksToActErr != nil {
+		return fmt.Errorf("failed to get BlocksToAct: %v", blocksToActErr)
+	}
+	k.blocksToAct = blocksToAct.Uint64()
+
+	head, headErr := k.client.BlockNumber(ctx)
+	if headErr != nil {
+		return headErr
+	}
+	fromBlock := k.config.FromBlock
+	if fromBlock == 0 && head > k.blocksToAct {
+		fromBlock = head - k.blocksToAct
+	}
+	if fromBlock > 0 {
+		k.scanned = fromBlock - 1
+	}
+
+	ticker := time.NewTicker(k.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := k.Tick(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Fprintf(k.out, "Error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick performs a single round of the keeper's work: it picks up new spins, checks on submitted
+// claims, and claims prizes for winning spins.
+func (k *Keeper) Tick(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	head, headErr := k.client.BlockNumber(ctx)
+	if headErr != nil {
+		return headErr
+	}
+
+	if err := k.scanSpins(ctx, head); err != nil {
+		return err
+	}
+
+	// A node which cannot report the account's nonce right now should not hold up claims whose
+	// deadlines are approaching. Rebroadcasting is retried on the next tick.
+	if err := k.manager.Rebroadcast(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Fprintf(k.out, "Error: failed to rebroadcast pending claims: %v\n", err)
+	}
+
+	players := make([]common.Address, 0, len(k.watched))
+	for player := range k.watched {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Hex() < players[j].Hex() })
+
+	for _, player := range players {
+		if err := k.process(ctx, k.watched[player], head); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanSpins adds the players who spun since the last scan to the watch list.
+func (k *Keeper) scanSpins(ctx context.Context, head uint64) error {
+	for k.scanned < head {
+		start := k.scanned + 1
+		end := head
+		if end-start+1 > MaxBlocksPerQuery {
+			end = start + MaxBlocksPerQuery - 1
+		}
+
+		iterator, filterErr := k.contract.FilterSpin(&bind.FilterOpts{Start: start, End: &end, Context: ctx}, k.config.Players, nil)
+		if filterErr != nil {
+			return fmt.Errorf("failed to get Spin events from blocks %d-%d: %v", start, end, filterErr)
+		}
+		for iterator.Next() {
+			player := iterator.Event.Player
+			if _, ok := k.watched[player]; !ok {
+				k.watched[player] = &spin{player: player}
+			}
+		}
+		iterErr := iterator.Error()
+		iterator.Close()
+		if iterErr != nil {
+			return iterErr
+		}
+
+		k.scanned = end
+	}
+	return nil
+}
+
+// process decides what to do about a watched player's spin.
+func (k *Keeper) process(ctx context.Context, watched *spin, head uint64) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	if watched.claim != nil {
+		k.trackReplacements(watched)
+		for _, hash := range watched.claimHashes {
+			receipt, receiptErr := k.client.TransactionReceipt(ctx, hash)
+			if receiptErr == nil {
+				k.recordClaim(watched, receipt)
+				delete(k.watched, watched.player)
+				return nil
+			}
+		}
+	}
+
+	lastSpinBlockRaw, lastSpinBlockErr := k.contract.LastSpinBlock(callOpts, watched.player)
+	if lastSpinBlockErr != nil {
+		return fmt.Errorf("failed to get LastSpinBlock for %s: %v", watched.player.Hex(), lastSpinBlockErr)
+	}
+	lastSpinBlock := lastSpinBlockRaw.Uint64()
+
+	if lastSpinBlock == 0 {
+		// The spin was accepted, either by the player or by a claim whose receipt we did not see.
+		if watched.claim == nil {
+			k.stats.Skipped++
+		} else {
+			k.stats.Claims++
+			k.stats.ClaimsByPlayer[watched.player]++
+		}
+		delete(k.watched, watched.player)
+		return nil
+	}
+
+	if lastSpinBlock != watched.spinBlock {
+		// The player spun (or respun) since we last looked, so any claim in flight is for an outdated spin.
+		watched.spinBlock = lastSpinBlock
+		watched.winner = false
+		watched.claim = nil
+		watched.claimHashes = nil
+	}
+
+	// A claim submitted now is mined in block head+1 at the earliest, where accepting reverts with
+	// DeadlineExceeded if head is already at the deadline.
+	deadline := lastSpinBlock + k.blocksToAct
+	if head >= deadline {
+		delete(k.watched, watched.player)
+		if k.wasWinner(ctx, watched, deadline) {
+			k.stats.Missed++
+			fmt.Fprintf(k.out, "Missed deadline for %s (spin at block %d)\n", watched.player.Hex(), lastSpinBlock)
+		}
+		return nil
+	}
+
+	if head <= lastSpinBlock || watched.claim != nil {
+		return nil
+	}
+
+	hasPrize, hasPrizeErr := k.contract.HasPrize(callOpts, watched.player)
+	if hasPrizeErr != nil {
+		return fmt.Errorf("failed to check HasPrize for %s: %v", watched.player.Hex(), hasPrizeErr)
+	}
+	if !hasPrize {
+		// Nothing to claim. If the player respins, the new Spin event puts them back on the watch list.
+		delete(k.watched, watched.player)
+		return nil
+	}
+	watched.winner = true
+
+	tx, txErr := k.manager.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return k.contract.AcceptFor(opts, watched.player)
+	})
+	if txErr != nil {
+		reason := gambit.RevertReason(txErr)
+		if reason == gambit.ErrorDeadlineExceeded {
+			// The player accepted in the meantime (which clears LastSpinBlock) or the deadline passed.
+			delete(k.watched, watched.player)
+			return nil
+		}
+		return fmt.Errorf("failed to submit acceptFor for %s: %v", watched.player.Hex(), txErr)
+	}
+
+	watched.claim = tx
+	watched.claimHashes = []common.Hash{tx.Hash()}
+	fmt.Fprintf(k.out, "Submitted acceptFor(%s) for spin at block %d (deadline: block %d): %s\n", watched.player.Hex(), lastSpinBlock, lastSpinBlock+k.blocksToAct, tx.Hash().Hex())
+	return nil
+}
+
+// trackReplacements adds the hashes of any fee-bumped replacements of the watched spin's claim.
+func (k *Keeper) trackReplacements(watched *spin) {
+	for _, pending := range k.manager.Pending() {
+		if pending.Nonce != watched.claim.Nonce() {
+			continue
+		}
+		for _, hash := range append(append([]common.Hash{}, pending.Replaced...), pending.Hash) {
+			known := false
+			for _, claimHash := range watched.claimHashes {
+				if claimHash == hash {
+					known = true
+					break
+				}
+			}
+			if !known {
+				watched.claimHashes = append(watched.claimHashes, hash)
+			}
+		}
+	}
+}
+
+// wasWinner reports whether a spin whose deadline has passed was a winning spin. Spins the keeper did
+// not check before the deadline are checked with HasPrize as of the deadline block, the last block in
+// which they could have been accepted.
+func (k *Keeper) wasWinner(ctx context.Context, watched *spin, deadline uint64) bool {
+	if watched.winner || watched.claim != nil {
+		return true
+	}
+	hasPrize, hasPrizeErr := k.contract.HasPrize(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(deadline)}, watched.player)
+	if hasPrizeErr != nil {
+		fmt.Fprintf(k.out, "Error: failed to check whether the expired spin of %s (at block %d) won: %v\n", watched.player.Hex(), watched.spinBlock, hasPrizeErr)
+		return false
+	}
+	return hasPrize
+}
+
+func (k *Keeper) recordClaim(watched *spin, receipt *types.Receipt) {
+	k.stats.GasUsed += receipt.GasUsed
+	if receipt.EffectiveGasPrice != nil {
+		fee := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+		k.stats.GasSpent.Add(k.stats.GasSpent, fee)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		k.stats.FailedClaims++
+		fmt.Fprintf(k.out, "Claim for %s reverted: %s\n", watched.player.Hex(), receipt.TxHash.Hex())
+		return
+	}
+
+	k.stats.Claims++
+	k.stats.ClaimsByPlayer[watched.player]++
+
+	prize := big.NewInt(0)
+	for _, log := range receipt.Logs {
+		if award, parseErr := k.contract.ParseAward(*log); parseErr == nil {
+			prize = award.Value
+		}
+	}
+	fmt.Fprintf(k.out, "Claimed prize of %s for %s (gas used: %d): %s\n", prize.String(), watched.player.Hex(), receipt.GasUsed, receipt.TxHash.Hex())
+}
+
+// Report writes a human readable summary of the keeper's statistics to w.
+func (s Stats) Report(w io.Writer) {
+	fmt.Fprintf(w, "Claims: %d\nFailed claims: %d\nMissed deadlines: %d\nAccepted by others: %d\n", s.Claims, s.FailedClaims, s.Missed, s.Skipped)
+	fmt.Fprintf(w, "Gas used: %d\nGas spent (wei): %s\n", s.GasUsed, s.GasSpent.String())
+
+	players := make([]common.Address, 0, len(s.ClaimsByPlayer))
+	for player := range s.ClaimsByPlayer {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Hex() < players[j].Hex() })
+	for _, player := range players {
+		fmt.Fprintf(w, "  %s: %d claims\n", player.Hex(), s.ClaimsByPlayer[player])
+	}
+}
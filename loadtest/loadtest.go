@@ -0,0 +1,317 @@
+// Package loadtest runs a fleet of players against a Degen's Gambit contract to measure how the game
+// (and the chain it runs on) behaves under contention.
+//
+// Each player repeatedly spins, optionally respins, and accepts the outcome of their spin, with a
+// configurable think time between actions. The load test records the latency of every transaction,
+// the reason for every revert (e.g. WaitForTick, DeadlineExceeded), and the overall throughput.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// Number of GAMBIT tokens (in their finest denomination) burned by a boosted spin.
+var boostCost *big.Int = big.NewInt(1e18)
+
+// Config describes a load test.
+type Config struct {
+	// Address of the DegenGambit contract under test.
+	Contract common.Address
+	// Accounts of the players taking part in the load test.
+	Players []signer.Signer
+	// Number of spin/accept cycles each player performs. If 0, players keep playing until Duration
+	// elapses.
+	Cycles int
+	// Maximum duration of the load test. If 0, the load test only stops after Cycles cycles.
+	Duration time.Duration
+	// Amount of time a player waits between actions.
+	ThinkTime time.Duration
+	// Fraction (between 0 and 1) of spins which players boost, if they hold GAMBIT to burn.
+	BoostRate float64
+	// Fraction (between 0 and 1) of spins which players follow up with a respin before accepting.
+	RespinRate float64
+}
+
+// Validate checks that the load test configuration is usable.
+func (c Config) Validate() error {
+	if len(c.Players) == 0 {
+		return errors.New("load test needs at least one player")
+	}
+	if c.Cycles <= 0 && c.Duration <= 0 {
+		return errors.New("load test needs either a number of cycles or a duration")
+	}
+	if c.BoostRate < 0 || c.BoostRate > 1 {
+		return fmt.Errorf("boost rate must be between 0 and 1 (got %f)", c.BoostRate)
+	}
+	if c.RespinRate < 0 || c.RespinRate > 1 {
+		return fmt.Errorf("respin rate must be between 0 and 1 (got %f)", c.RespinRate)
+	}
+	return nil
+}
+
+// Fund tops up the native token balance of each of the given accounts to at least amount, sending
+// the difference from the account managed by funder. It waits for all funding transactions to be
+// mined.
+func Fund(ctx context.Context, client *ethclient.Client, funder *txmanager.Manager, accounts []common.Address, amount *big.Int) error {
+	var transactions []*types.Transaction
+	for _, account := range accounts {
+		balance, balanceErr := client.BalanceAt(ctx, account, nil)
+		if balanceErr != nil {
+			return fmt.Errorf("failed to get balance of %s: %v", account.Hex(), balanceErr)
+		}
+		if balance.Cmp(amount) >= 0 {
+			continue
+		}
+
+		topUp := new(big.Int).Sub(amount, balance)
+		recipient := bind.NewBoundContract(account, abi.ABI{}, client, client, client)
+		tx, txErr := funder.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			opts.Value = topUp
+			return recipient.RawTransact(opts, nil)
+		})
+		if txErr != nil {
+			return fmt.Errorf("failed to fund %s: %v", account.Hex(), txErr)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	for _, tx := range transactions {
+		receipt, waitErr := bind.WaitMined(ctx, client, tx)
+		if waitErr != nil {
+			return waitErr
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return fmt.Errorf("funding transaction %s failed", tx.Hash().Hex())
+		}
+	}
+
+	return nil
+}
+
+// Run runs the load test described by the configuration and returns the statistics it collected.
+// Cancelling the context stops all players.
+func Run(ctx context.Context, client *ethclient.Client, config Config) (*Stats, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	chainID, chainIDErr := client.ChainID(ctx)
+	if chainIDErr != nil {
+		return nil, chainIDErr
+	}
+
+	contract, contractErr := DegenGambit.NewDegenGambit(config.Contract, client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	players := make([]*player, len(config.Players))
+	for i, playerSigner := range config.Players {
+		manager, managerErr := txmanager.New(ctx, client, signer.NewTransactOpts(playerSigner, chainID), chainID, "")
+		if managerErr != nil {
+			return nil, fmt.Errorf("failed to set up player %s: %v", playerSigner.Address().Hex(), managerErr)
+		}
+		players[i] = &player{
+			address:  playerSigner.Address(),
+			manager:  manager,
+			client:   client,
+			contract: contract,
+			config:   config,
+			random:   rand.New(rand.NewSource(time.Now().UnixNano() + int64(i))),
+		}
+	}
+
+	stats := NewStats()
+	stats.Started = time.Now()
+
+	var wg sync.WaitGroup
+	for _, p := range players {
+		wg.Add(1)
+		go func(p *player) {
+			defer wg.Done()
+			p.play(ctx, stats)
+		}(p)
+	}
+	wg.Wait()
+
+	stats.Finished = time.Now()
+	return stats, nil
+}
+
+type player struct {
+	address  common.Address
+	manager  *txmanager.Manager
+	client   *ethclient.Client
+	contract *DegenGambit.DegenGambit
+	config   Config
+	random   *rand.Rand
+}
+
+func (p *player) play(ctx context.Context, stats *Stats) {
+	for cycle := 0; p.config.Cycles <= 0 || cycle < p.config.Cycles; cycle++ {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !p.spin(ctx, stats, OperationSpin) {
+			p.think(ctx)
+			continue
+		}
+
+		if p.random.Float64() < p.config.RespinRate {
+			p.think(ctx)
+			p.spin(ctx, stats, OperationRespin)
+		}
+
+		p.think(ctx)
+		p.accept(ctx, stats)
+		p.think(ctx)
+	}
+}
+
+func (p *player) think(ctx context.Context) {
+	if p.config.ThinkTime <= 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(p.config.ThinkTime):
+	}
+}
+
+// spin submits a spin (or respin) and reports whether it succeeded.
+func (p *player) spin(ctx context.Context, stats *Stats, operation Operation) bool {
+	callOpts := &bind.CallOpts{Context: ctx}
+	cost, costErr := p.contract.SpinCost(callOpts, p.address)
+	if costErr != nil {
+		stats.recordFailure(operation, describeError(costErr))
+		return false
+	}
+
+	boost := false
+	if p.random.Float64() < p.config.BoostRate {
+		balance, balanceErr := p.contract.BalanceOf(callOpts, p.address)
+		boost = balanceErr == nil && balance.Cmp(boostCost) >= 0
+	}
+
+	_, ok := p.transact(ctx, stats, operation, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		opts.Value = cost
+		return p.contract.Spin(opts, boost)
+	})
+	if ok && boost {
+		stats.recordBoost()
+	}
+	return ok
+}
+
+func (p *player) accept(ctx context.Context, stats *Stats) {
+	receipt, ok := p.transact(ctx, stats, OperationAccept, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return p.contract.Accept(opts)
+	})
+	if !ok {
+		return
+	}
+
+	for _, log := range receipt.Logs {
+		award, parseErr := p.contract.ParseAward(*log)
+		if parseErr != nil || award.Value.Sign() == 0 {
+			continue
+		}
+		if isGambitMint(p.contract, receipt, award.Player, award.Value) {
+			stats.recordPrize(nil)
+		} else {
+			stats.recordPrize(award.Value)
+		}
+	}
+}
+
+// transact submits a transaction, waits for it to be mined, and records the outcome.
+func (p *player) transact(ctx context.Context, stats *Stats, operation Operation, transact txmanager.TransactFunc) (*types.Receipt, bool) {
+	submittedAt := time.Now()
+	tx, txErr := p.manager.Transact(ctx, transact)
+	if txErr != nil {
+		if ctx.Err() == nil {
+			stats.recordFailure(operation, describeError(txErr))
+		}
+		return nil, false
+	}
+
+	receipt, waitErr := bind.WaitMined(ctx, p.client, tx)
+	if waitErr != nil {
+		if ctx.Err() == nil {
+			stats.recordFailure(operation, describeError(waitErr))
+		}
+		return nil, false
+	}
+	latency := time.Since(submittedAt)
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		stats.recordFailure(operation, p.replayRevert(ctx, tx, receipt))
+		return receipt, false
+	}
+
+	stats.recordSuccess(operation, latency)
+	return receipt, true
+}
+
+// replayRevert replays a reverted transaction at the block it was mined in to find out why it reverted.
+func (p *player) replayRevert(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) string {
+	msg := ethereum.CallMsg{
+		From:  p.address,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	_, callErr := p.client.CallContract(ctx, msg, receipt.BlockNumber)
+	if reason := gambit.RevertReason(callErr); reason != "" {
+		return reason
+	}
+	return "reverted (unknown reason)"
+}
+
+func describeError(err error) string {
+	if reason := gambit.RevertReason(err); reason != "" {
+		return reason
+	}
+	return err.Error()
+}
+
+// isGambitMint reports whether the receipt contains a mint of the given amount of GAMBIT to the player,
+// which distinguishes GAMBIT prizes from native token prizes.
+func isGambitMint(contract *DegenGambit.DegenGambit, receipt *types.Receipt, player common.Address, amount *big.Int) bool {
+	for _, log := range receipt.Logs {
+		transfer, parseErr := contract.ParseTransfer(*log)
+		if parseErr != nil {
+			continue
+		}
+		if transfer.From == (common.Address{}) && transfer.To == player && transfer.Value.Cmp(amount) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Operation is a kind of transaction submitted by the players in a load test.
+type Operation string
+
+const (
+	OperationSpin   Operation = "spin"
+	OperationRespin Operation = "respin"
+	OperationAccept Operation = "accept"
+)
+
+// Operations lists the operations in the order in which they are reported.
+var Operations []Operation = []Operation{OperationSpin, OperationRespin, OperationAccept}
+
+// OperationStats collects the results of one kind of operation.
+type OperationStats struct {
+	// Number of transactions attempted.
+	Attempted int
+	// Number of transactions which were mined successfully.
+	Succeeded int
+	// Time from submission until the receipt was available, for every successful transaction.
+	Latencies []time.Duration
+	// Number of failures, by revert reason (or error message if the failure was not a revert).
+	Failures map[string]int
+}
+
+// Stats collects the results of a load test. It is safe for concurrent use.
+type Stats struct {
+	Started  time.Time
+	Finished time.Time
+
+	// Number of accepted spins which won a prize, and the total value of native token prizes.
+	Prizes    int
+	NativeWon *big.Int
+	// Number of spins which were boosted by spending GAMBIT.
+	BoostedSpins int
+
+	mu         sync.Mutex
+	operations map[Operation]*OperationStats
+}
+
+// NewStats creates an empty set of load test statistics.
+func NewStats() *Stats {
+	stats := &Stats{
+		NativeWon:  big.NewInt(0),
+		operations: make(map[Operation]*OperationStats),
+	}
+	for _, operation := range Operations {
+		stats.operations[operation] = &OperationStats{Failures: make(map[string]int)}
+	}
+	return stats
+}
+
+func (s *Stats) recordSuccess(operation Operation, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	operationStats := s.operations[operation]
+	operationStats.Attempted++
+	operationStats.Succeeded++
+	operationStats.Latencies = append(operationStats.Latencies, latency)
+}
+
+func (s *Stats) recordFailure(operation Operation, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	operationStats := s.operations[operation]
+	operationStats.Attempted++
+	operationStats.Failures[reason]++
+}
+
+func (s *Stats) recordPrize(nativeValue *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Prizes++
+	if nativeValue != nil {
+		s.NativeWon.Add(s.NativeWon, nativeValue)
+	}
+}
+
+func (s *Stats) recordBoost() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.BoostedSpins++
+}
+
+// Operation returns a copy of the statistics for the given operation.
+func (s *Stats) Operation(operation Operation) OperationStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	operationStats := s.operations[operation]
+	result := OperationStats{
+		Attempted: operationStats.Attempted,
+		Succeeded: operationStats.Succeeded,
+		Latencies: append([]time.Duration{}, operationStats.Latencies...),
+		Failures:  make(map[string]int, len(operationStats.Failures)),
+	}
+	for reason, count := range operationStats.Failures {
+		result.Failures[reason] = count
+	}
+	return result
+}
+
+// Percentile returns the latency below which the given fraction (between 0 and 1) of successful
+// transactions fall.
+func (o OperationStats) Percentile(fraction float64) time.Duration {
+	if len(o.Latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, o.Latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(fraction * float64(len(sorted)-1))
+	return sorted[index]
+}
+
+// Report writes a human readable summary of the load test to w.
+func (s *Stats) Report(w io.Writer) {
+	elapsed := s.Finished.Sub(s.Started)
+	totalSucceeded := 0
+
+	fmt.Fprintf(w, "Elapsed: %s\n\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "%-8s %10s %10s %10s %12s %12s %12s\n", "OP", "ATTEMPTED", "SUCCEEDED", "FAILED", "P50", "P95", "MAX")
+	for _, operation := range Operations {
+		operationStats := s.Operation(operation)
+		totalSucceeded += operationStats.Succeeded
+		fmt.Fprintf(
+			w,
+			"%-8s %10d %10d %10d %12s %12s %12s\n",
+			operation,
+			operationStats.Attempted,
+			operationStats.Succeeded,
+			operationStats.Attempted-operationStats.Succeeded,
+			operationStats.Percentile(0.5).Round(time.Millisecond),
+			operationStats.Percentile(0.95).Round(time.Millisecond),
+			operationStats.Percentile(1).Round(time.Millisecond),
+		)
+	}
+
+	fmt.Fprintln(w, "\nFailures:")
+	anyFailures := false
+	for _, operation := range Operations {
+		operationStats := s.Operation(operation)
+		reasons := make([]string, 0, len(operationStats.Failures))
+		for reason := range operationStats.Failures {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		for _, reason := range reasons {
+			anyFailures = true
+			fmt.Fprintf(w, "  %-8s %6d  %s\n", operation, operationStats.Failures[reason], reason)
+		}
+	}
+	if !anyFailures {
+		fmt.Fprintln(w, "  none")
+	}
+
+	throughput := 0.0
+	if elapsed > 0 {
+		throughput = float64(totalSucceeded) / elapsed.Seconds()
+	}
+	fmt.Fprintf(w, "\nThroughput: %.2f successful transactions per second\n", throughput)
+	fmt.Fprintf(w, "Prizes won: %d (native token prizes: %s)\n", s.Prizes, s.NativeWon.String())
+	fmt.Fprintf(w, "Boosted spins: %d\n", s.BoostedSpins)
+}
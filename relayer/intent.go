@@ -0,0 +1,156 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Name and version of the EIP-712 signing domain for relayer intents.
+const (
+	DomainName    = "Degen Casino Relayer"
+	DomainVersion = "1"
+)
+
+// Actions that players can ask the relayer to perform.
+const (
+	ActionSpin   = "spin"
+	ActionAccept = "accept"
+)
+
+var ErrInvalidSignature error = errors.New("intent signature does not match player")
+
+// Intent is a player's signed request for the relayer to act on their behalf. For spins, the relayer
+// calls spinFor(player, player, boost). For accepts, it calls acceptFor(player).
+type Intent struct {
+	Action string         `json:"action"`
+	Player common.Address `json:"player"`
+	// Only used for spins.
+	Boost bool `json:"boost"`
+	// Must be greater than the nonce of every intent the relayer has already accepted from the player.
+	Nonce *math.HexOrDecimal256 `json:"nonce"`
+	// Unix timestamp after which the relayer must not act on the intent.
+	Deadline *math.HexOrDecimal256 `json:"deadline"`
+}
+
+// SignedIntent is an intent together with the player's EIP-712 signature over it.
+type SignedIntent struct {
+	Intent    Intent        `json:"intent"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Types returns the EIP-712 types for relayer intents.
+func Types() apitypes.Types {
+	return apitypes.Types{
+		"EIP712Domain": []apitypes.Type{
+			{Name: "name", Type: "string"},
+			{Name: "version", Type: "string"},
+			{Name: "chainId", Type: "uint256"},
+			{Name: "verifyingContract", Type: "address"},
+		},
+		"SpinIntent": []apitypes.Type{
+			{Name: "player", Type: "address"},
+			{Name: "boost", Type: "bool"},
+			{Name: "nonce", Type: "uint256"},
+			{Name: "deadline", Type: "uint256"},
+		},
+		"AcceptIntent": []apitypes.Type{
+			{Name: "player", Type: "address"},
+			{Name: "nonce", Type: "uint256"},
+			{Name: "deadline", Type: "uint256"},
+		},
+	}
+}
+
+// Domain returns the EIP-712 domain for intents which target the given DegenGambit contract.
+func Domain(chainID *big.Int, contract common.Address) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              DomainName,
+		Version:           DomainVersion,
+		ChainId:           (*math.HexOrDecimal256)(chainID),
+		VerifyingContract: contract.Hex(),
+	}
+}
+
+// TypedData returns the EIP-712 typed data that the player signs for the intent.
+func (i Intent) TypedData(chainID *big.Int, contract common.Address) (apitypes.TypedData, error) {
+	if i.Nonce == nil || i.Deadline == nil {
+		return apitypes.TypedData{}, errors.New("intent must have a nonce and a deadline")
+	}
+
+	typedData := apitypes.TypedData{
+		Types:  Types(),
+		Domain: Domain(chainID, contract),
+	}
+
+	nonce := (*big.Int)(i.Nonce).String()
+	deadline := (*big.Int)(i.Deadline).String()
+
+	switch i.Action {
+	case ActionSpin:
+		typedData.PrimaryType = "SpinIntent"
+		typedData.Message = apitypes.TypedDataMessage{
+			"player":   i.Player.Hex(),
+			"boost":    i.Boost,
+			"nonce":    nonce,
+			"deadline": deadline,
+		}
+	case ActionAccept:
+		typedData.PrimaryType = "AcceptIntent"
+		typedData.Message = apitypes.TypedDataMessage{
+			"player":   i.Player.Hex(),
+			"nonce":    nonce,
+			"deadline": deadline,
+		}
+	default:
+		return apitypes.TypedData{}, fmt.Errorf("unknown action: %s", i.Action)
+	}
+
+	return typedData, nil
+}
+
+// Hash returns the EIP-712 hash of the intent.
+func (i Intent) Hash(chainID *big.Int, contract common.Address) (common.Hash, error) {
+	typedData, typedDataErr := i.TypedData(chainID, contract)
+	if typedDataErr != nil {
+		return common.Hash{}, typedDataErr
+	}
+	hash, _, hashErr := apitypes.TypedDataAndHash(typedData)
+	if hashErr != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash intent: %v", hashErr)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// Verify checks that the signature on the intent was made by the intent's player.
+func (s SignedIntent) Verify(chainID *big.Int, contract common.Address) error {
+	hash, hashErr := s.Intent.Hash(chainID, contract)
+	if hashErr != nil {
+		return hashErr
+	}
+
+	if len(s.Signature) != crypto.SignatureLength {
+		return fmt.Errorf("signature must be %d bytes long", crypto.SignatureLength)
+	}
+	signature := make([]byte, crypto.SignatureLength)
+	copy(signature, s.Signature)
+	// Wallets produce signatures with V in {27, 28}.
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, recoverErr := crypto.SigToPub(hash.Bytes(), signature)
+	if recoverErr != nil {
+		return fmt.Errorf("failed to recover signer: %v", recoverErr)
+	}
+	if crypto.PubkeyToAddress(*publicKey) != s.Intent.Player {
+		return ErrInvalidSignature
+	}
+	return nil
+}
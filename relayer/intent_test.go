@@ -0,0 +1,105 @@
+package relayer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	testChainID  = big.NewInt(1337)
+	testContract = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+)
+
+func newTestIntent(action string, player common.Address, nonce int64) Intent {
+	return Intent{
+		Action:   action,
+		Player:   player,
+		Nonce:    (*math.HexOrDecimal256)(big.NewInt(nonce)),
+		Deadline: (*math.HexOrDecimal256)(big.NewInt(2000000000)),
+	}
+}
+
+// signIntent signs the intent the way wallets do, with V in {27, 28}.
+func signIntent(t *testing.T, intent Intent, chainID *big.Int, contract common.Address) SignedIntent {
+	t.Helper()
+	key, keyErr := crypto.GenerateKey()
+	if keyErr != nil {
+		t.Fatalf("failed to generate key: %v", keyErr)
+	}
+	intent.Player = crypto.PubkeyToAddress(key.PublicKey)
+
+	hash, hashErr := intent.Hash(chainID, contract)
+	if hashErr != nil {
+		t.Fatalf("failed to hash intent: %v", hashErr)
+	}
+	signature, signErr := crypto.Sign(hash.Bytes(), key)
+	if signErr != nil {
+		t.Fatalf("failed to sign intent: %v", signErr)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return SignedIntent{Intent: intent, Signature: signature}
+}
+
+func TestVerifyIntent(t *testing.T) {
+	for _, action := range []string{ActionSpin, ActionAccept} {
+		signed := signIntent(t, newTestIntent(action, common.Address{}, 1), testChainID, testContract)
+		if err := signed.Verify(testChainID, testContract); err != nil {
+			t.Fatalf("expected %s intent to verify: %v", action, err)
+		}
+
+		// Signatures with V in {0, 1} are accepted as well.
+		unnormalized := SignedIntent{Intent: signed.Intent, Signature: append([]byte{}, signed.Signature...)}
+		unnormalized.Signature[crypto.RecoveryIDOffset] -= 27
+		if err := unnormalized.Verify(testChainID, testContract); err != nil {
+			t.Fatalf("expected %s intent with V in {0, 1} to verify: %v", action, err)
+		}
+	}
+}
+
+func TestVerifyIntentRejectsMismatches(t *testing.T) {
+	signed := signIntent(t, newTestIntent(ActionSpin, common.Address{}, 1), testChainID, testContract)
+
+	otherContract := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	if err := signed.Verify(testChainID, otherContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected an intent for another contract to be rejected, got: %v", err)
+	}
+	if err := signed.Verify(big.NewInt(1), testContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected an intent for another chain to be rejected, got: %v", err)
+	}
+
+	tampered := signed
+	tampered.Intent.Boost = true
+	if err := tampered.Verify(testChainID, testContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected a tampered intent to be rejected, got: %v", err)
+	}
+
+	// A spin intent signature must not be usable as an accept intent.
+	asAccept := signed
+	asAccept.Intent.Action = ActionAccept
+	if err := asAccept.Verify(testChainID, testContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected a spin signature to be rejected for an accept, got: %v", err)
+	}
+
+	otherPlayer := signed
+	otherPlayer.Intent.Player = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	if err := otherPlayer.Verify(testChainID, testContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected a signature from another player to be rejected, got: %v", err)
+	}
+
+	truncated := signed
+	truncated.Signature = signed.Signature[:64]
+	if err := truncated.Verify(testChainID, testContract); err == nil {
+		t.Fatalf("expected a truncated signature to be rejected")
+	}
+
+	unknown := signed
+	unknown.Intent.Action = "withdraw"
+	if err := unknown.Verify(testChainID, testContract); err == nil {
+		t.Fatalf("expected an unknown action to be rejected")
+	}
+}
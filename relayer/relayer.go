@@ -0,0 +1,536 @@
+// Package relayer implements a service which lets players without gas play Degen's Gambit.
+//
+// Players sign EIP-712 intents to spin or accept. The relayer checks the signature, the player's rate
+// limit and budget, and then submits spinFor(player, player, boost) or acceptFor(player) from its own
+// account, paying the spin cost and gas. Results are returned to the player over HTTP and posted to a
+// webhook.
+//
+// Each player's last intent nonce, recent intents, and daily spend can be persisted to a state file, so
+// that intents which were already relayed cannot be replayed after the relayer restarts.
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/atomicfile"
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+const (
+	StatusMined    = "mined"
+	StatusReverted = "reverted"
+	// The transaction was submitted but had not been mined when the response was sent. The final
+	// result is delivered to the webhook.
+	StatusSubmitted = "submitted"
+	StatusRejected  = "rejected"
+	StatusFailed    = "failed"
+)
+
+var (
+	ErrIntentExpired     error = errors.New("intent deadline has passed")
+	ErrNonceUsed         error = errors.New("intent nonce must be greater than the nonce of the player's last intent")
+	ErrRateLimited       error = errors.New("player has exceeded their rate limit")
+	ErrBudgetExceeded    error = errors.New("player has exceeded their daily budget")
+	ErrBoostNotSupported error = errors.New("the relayer does not sponsor boosted spins")
+)
+
+// Config describes a relayer's policies.
+type Config struct {
+	// Address of the DegenGambit contract.
+	Contract common.Address
+	// Maximum number of intents a single player may submit in each RateLimitWindow. 0 means no limit.
+	RateLimit       int
+	RateLimitWindow time.Duration
+	// Maximum amount (in wei) the relayer spends on a single player per UTC day, counting both spin
+	// costs and gas fees. Nil means no limit.
+	DailyBudget *big.Int
+	// Boosted spins burn GAMBIT from the account that calls spinFor, which is the relayer. They are
+	// only relayed if AllowBoost is set.
+	AllowBoost bool
+	// Path to a file in which the players' nonces, rate limit windows, and daily spend are persisted.
+	// If empty, they are only kept in memory and every unexpired intent can be replayed after a restart.
+	StatePath string
+}
+
+// Result is the outcome of a relayed intent.
+type Result struct {
+	Action          string         `json:"action"`
+	Player          common.Address `json:"player"`
+	Nonce           string         `json:"nonce"`
+	Status          string         `json:"status"`
+	Error           string         `json:"error,omitempty"`
+	TransactionHash *common.Hash   `json:"transactionHash,omitempty"`
+	BlockNumber     uint64         `json:"blockNumber,omitempty"`
+	GasUsed         uint64         `json:"gasUsed,omitempty"`
+	// Native tokens (in wei) the relayer spent on the intent: the spin cost plus gas fees.
+	Spent string `json:"spent,omitempty"`
+	// For accepts, the prize awarded to the player.
+	Prize string `json:"prize,omitempty"`
+}
+
+type playerState struct {
+	lastNonce   *big.Int
+	recent      []time.Time
+	budgetDay   int64
+	spentOnDay  *big.Int
+	reservedDay *big.Int
+}
+
+// reservation records what reserve changed for an intent, so that it can be undone if the intent's
+// transaction cannot be submitted, or settled once it has been mined.
+type reservation struct {
+	previousNonce *big.Int
+	at            time.Time
+	// UTC day on which the reservation was made, and the amount reserved from that day's budget: the
+	// spin cost plus the most the transaction can pay in gas fees.
+	day    int64
+	amount *big.Int
+}
+
+// PlayerRecord is a player's state as persisted in the relayer's state file.
+type PlayerRecord struct {
+	LastNonce *big.Int    `json:"lastNonce,omitempty"`
+	Recent    []time.Time `json:"recent,omitempty"`
+	// UTC day (days since the Unix epoch) to which SpentOnDay applies.
+	BudgetDay int64 `json:"budgetDay"`
+	// Amount spent on the player on BudgetDay. Reservations for transactions which had not been mined
+	// when the state was saved are counted as spent.
+	SpentOnDay *big.Int `json:"spentOnDay"`
+}
+
+// State is the part of the relayer which is persisted to disk.
+type State struct {
+	ChainID  string                          `json:"chainId"`
+	Contract common.Address                  `json:"contract"`
+	Players  map[common.Address]PlayerRecord `json:"players"`
+}
+
+// Relayer checks and submits player intents.
+type Relayer struct {
+	client   *ethclient.Client
+	contract *DegenGambit.DegenGambit
+	manager  *txmanager.Manager
+	chainID  *big.Int
+	config   Config
+
+	// Destination for errors which do not affect the result of an intent, like failures to save the
+	// players' state after a transaction was mined. Defaults to os.Stderr.
+	Log io.Writer
+
+	mu      sync.Mutex
+	players map[common.Address]*playerState
+}
+
+// New creates a relayer which submits transactions through the given transaction manager. If
+// config.StatePath is set, the players' state is loaded from that file.
+func New(client *ethclient.Client, manager *txmanager.Manager, chainID *big.Int, config Config) (*Relayer, error) {
+	contract, contractErr := DegenGambit.NewDegenGambit(config.Contract, client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+	r := &Relayer{
+		client:   client,
+		contract: contract,
+		manager:  manager,
+		chainID:  chainID,
+		config:   config,
+		Log:      os.Stderr,
+		players:  make(map[common.Address]*playerState),
+	}
+
+	state, stateErr := LoadState(config.StatePath)
+	if stateErr != nil {
+		return nil, stateErr
+	}
+	if state != nil {
+		if state.ChainID != chainID.String() {
+			return nil, fmt.Errorf("state file %s was created for chain %s, not %s", config.StatePath, state.ChainID, chainID.String())
+		} else if state.Contract != config.Contract {
+			return nil, fmt.Errorf("state file %s was created for contract %s, not %s", config.StatePath, state.Contract.Hex(), config.Contract.Hex())
+		}
+		for player, record := range state.Players {
+			spentOnDay := big.NewInt(0)
+			if record.SpentOnDay != nil {
+				spentOnDay.Set(record.SpentOnDay)
+			}
+			r.players[player] = &playerState{
+				lastNonce:   record.LastNonce,
+				recent:      record.Recent,
+				budgetDay:   record.BudgetDay,
+				spentOnDay:  spentOnDay,
+				reservedDay: big.NewInt(0),
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// ChainID returns the ID of the chain the relayer submits transactions to.
+func (r *Relayer) ChainID() *big.Int {
+	return r.chainID
+}
+
+// Contract returns the address of the DegenGambit contract the relayer plays on.
+func (r *Relayer) Contract() common.Address {
+	return r.config.Contract
+}
+
+// Submit checks the signed intent against the relayer's policies and submits the corresponding
+// transaction. The returned channel receives the result once the transaction has been mined. If the
+// intent is rejected, Submit returns an error and no transaction is sent.
+func (r *Relayer) Submit(ctx context.Context, signed SignedIntent) (*types.Transaction, <-chan Result, error) {
+	intent := signed.Intent
+
+	if err := signed.Verify(r.chainID, r.config.Contract); err != nil {
+		return nil, nil, err
+	}
+	if big.NewInt(time.Now().Unix()).Cmp((*big.Int)(intent.Deadline)) > 0 {
+		return nil, nil, ErrIntentExpired
+	}
+	if intent.Action == ActionSpin && intent.Boost && !r.config.AllowBoost {
+		return nil, nil, ErrBoostNotSupported
+	}
+
+	var value *big.Int
+	if intent.Action == ActionSpin {
+		spinCost, spinCostErr := r.contract.SpinCost(&bind.CallOpts{Context: ctx}, intent.Player)
+		if spinCostErr != nil {
+			return nil, nil, fmt.Errorf("failed to get spin cost: %v", spinCostErr)
+		}
+		value = spinCost
+	} else {
+		value = big.NewInt(0)
+	}
+
+	var reserved *reservation
+	tx, txErr := r.manager.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		// Build and sign the transaction without sending it, so that the player's budget is checked
+		// against its actual gas limit and fee cap.
+		unsent := *opts
+		unsent.NoSend = true
+		var tx *types.Transaction
+		var buildErr error
+		if intent.Action == ActionSpin {
+			unsent.Value = value
+			tx, buildErr = r.contract.SpinFor(&unsent, intent.Player, intent.Player, intent.Boost)
+		} else {
+			tx, buildErr = r.contract.AcceptFor(&unsent, intent.Player)
+		}
+		if buildErr != nil {
+			return nil, buildErr
+		}
+
+		reservation, reserveErr := r.reserve(intent, tx.Cost(), time.Now())
+		if reserveErr != nil {
+			return nil, reserveErr
+		}
+		reserved = &reservation
+
+		if err := r.client.SendTransaction(opts.Context, tx); err != nil {
+			return nil, err
+		}
+		return tx, nil
+	})
+	if txErr != nil {
+		if reserved != nil {
+			// The node did not accept the transaction, so the player can submit the same intent again.
+			r.cancel(intent, *reserved)
+		}
+		if reason := gambit.RevertReason(txErr); reason != "" {
+			return nil, nil, fmt.Errorf("transaction would revert: %s", reason)
+		}
+		return nil, nil, txErr
+	}
+
+	result := make(chan Result, 1)
+	go func() {
+		result <- r.await(intent, tx, value, *reserved)
+	}()
+
+	return tx, result, nil
+}
+
+// await waits for the transaction to be mined and settles the player's budget.
+func (r *Relayer) await(intent Intent, tx *types.Transaction, value *big.Int, reserved reservation) Result {
+	hash := tx.Hash()
+	result := Result{
+		Action:          intent.Action,
+		Player:          intent.Player,
+		Nonce:           (*big.Int)(intent.Nonce).String(),
+		TransactionHash: &hash,
+	}
+
+	receipt, waitErr := r.waitMined(tx)
+	if waitErr != nil {
+		// The transaction may still be mined, so the whole reservation counts as spent.
+		r.release(intent.Player, reserved, reserved.amount, time.Now())
+		result.Status = StatusFailed
+		result.Error = waitErr.Error()
+		return result
+	}
+
+	spent := big.NewInt(0)
+	if receipt.EffectiveGasPrice != nil {
+		spent.Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		spent.Add(spent, value)
+	}
+	r.release(intent.Player, reserved, spent, time.Now())
+
+	result.TransactionHash = &receipt.TxHash
+	result.BlockNumber = receipt.BlockNumber.Uint64()
+	result.GasUsed = receipt.GasUsed
+	result.Spent = spent.String()
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		result.Status = StatusReverted
+		return result
+	}
+
+	result.Status = StatusMined
+	if intent.Action == ActionAccept {
+		for _, log := range receipt.Logs {
+			if award, parseErr := r.contract.ParseAward(*log); parseErr == nil {
+				result.Prize = award.Value.String()
+			}
+		}
+	}
+	return result
+}
+
+// waitMined waits for the transaction, or any of its fee-bumped replacements, to be mined.
+func (r *Relayer) waitMined(tx *types.Transaction) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	hashes := []common.Hash{tx.Hash()}
+	for {
+		for _, hash := range hashes {
+			receipt, receiptErr := r.client.TransactionReceipt(ctx, hash)
+			if receiptErr == nil {
+				return receipt, nil
+			}
+		}
+
+		// Pick up replacements made by the transaction manager.
+		for _, pending := range r.manager.Pending() {
+			if pending.Nonce == tx.Nonce() {
+				hashes = append(append([]common.Hash{}, pending.Replaced...), pending.Hash)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s was not mined in time", tx.Hash().Hex())
+		case <-ticker.C:
+		}
+	}
+}
+
+// reserve checks the player's nonce, rate limit, and budget, consumes the intent's nonce, and reserves
+// amount from the budget. The nonce is persisted before reserve returns.
+func (r *Relayer) reserve(intent Intent, amount *big.Int, now time.Time) (reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.playerState(intent.Player, now)
+
+	nonce := (*big.Int)(intent.Nonce)
+	if state.lastNonce != nil && nonce.Cmp(state.lastNonce) <= 0 {
+		return reservation{}, ErrNonceUsed
+	}
+
+	if r.config.RateLimit > 0 {
+		cutoff := now.Add(-r.config.RateLimitWindow)
+		recent := state.recent[:0]
+		for _, t := range state.recent {
+			if t.After(cutoff) {
+				recent = append(recent, t)
+			}
+		}
+		state.recent = recent
+		if len(state.recent) >= r.config.RateLimit {
+			return reservation{}, ErrRateLimited
+		}
+	}
+
+	if r.config.DailyBudget != nil {
+		committed := new(big.Int).Add(state.spentOnDay, state.reservedDay)
+		if committed.Add(committed, amount).Cmp(r.config.DailyBudget) > 0 {
+			return reservation{}, ErrBudgetExceeded
+		}
+	}
+
+	reserved := reservation{
+		previousNonce: state.lastNonce,
+		at:            now,
+		day:           state.budgetDay,
+		amount:        new(big.Int).Set(amount),
+	}
+	state.lastNonce = new(big.Int).Set(nonce)
+	if r.config.RateLimit > 0 {
+		state.recent = append(state.recent, now)
+	}
+	state.reservedDay.Add(state.reservedDay, amount)
+
+	if err := r.save(); err != nil {
+		r.undo(intent, reserved, now)
+		return reservation{}, fmt.Errorf("failed to save relayer state: %v", err)
+	}
+	return reserved, nil
+}
+
+// cancel undoes a reservation whose transaction could not be submitted, restoring the player's nonce
+// so that the intent is not burned.
+func (r *Relayer) cancel(intent Intent, reserved reservation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.undo(intent, reserved, time.Now())
+	if err := r.save(); err != nil {
+		r.logf("Failed to save relayer state after cancelling intent %s from %s: %v", (*big.Int)(intent.Nonce).String(), intent.Player.Hex(), err)
+	}
+}
+
+// undo reverts the changes reserve made for an intent. It must be called with r.mu held.
+func (r *Relayer) undo(intent Intent, reserved reservation, now time.Time) {
+	state := r.playerState(intent.Player, now)
+
+	// A later intent from the same player may have been reserved in the meantime, in which case its
+	// nonce must stay consumed.
+	if state.lastNonce != nil && state.lastNonce.Cmp((*big.Int)(intent.Nonce)) == 0 {
+		state.lastNonce = reserved.previousNonce
+	}
+	for i, t := range state.recent {
+		if t.Equal(reserved.at) {
+			state.recent = append(state.recent[:i], state.recent[i+1:]...)
+			break
+		}
+	}
+	// Reservations from earlier days were dropped when the day changed.
+	if reserved.day == state.budgetDay {
+		subtractReserved(state, reserved.amount)
+	}
+}
+
+// release settles a reservation made by reserve, recording the amount actually spent against the day
+// on which the reservation was made.
+func (r *Relayer) release(player common.Address, reserved reservation, spent *big.Int, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.playerState(player, now)
+	// If the reservation was made before the current UTC day started, it was dropped along with the rest
+	// of that day's budget, and what it spent does not count against today's.
+	if reserved.day == state.budgetDay {
+		subtractReserved(state, reserved.amount)
+		state.spentOnDay.Add(state.spentOnDay, spent)
+	}
+	if err := r.save(); err != nil {
+		r.logf("Failed to save relayer state after settling a transaction for %s: %v", player.Hex(), err)
+	}
+}
+
+func subtractReserved(state *playerState, amount *big.Int) {
+	state.reservedDay.Sub(state.reservedDay, amount)
+	if state.reservedDay.Sign() < 0 {
+		state.reservedDay.SetInt64(0)
+	}
+}
+
+// playerState returns the state for the given player, resetting their budget at the start of each UTC
+// day. It must be called with r.mu held.
+func (r *Relayer) playerState(player common.Address, now time.Time) *playerState {
+	today := now.Unix() / 86400
+	state, ok := r.players[player]
+	if !ok {
+		state = &playerState{budgetDay: today, spentOnDay: big.NewInt(0), reservedDay: big.NewInt(0)}
+		r.players[player] = state
+	}
+	if state.budgetDay != today {
+		state.budgetDay = today
+		state.spentOnDay = big.NewInt(0)
+		state.reservedDay = big.NewInt(0)
+	}
+	return state
+}
+
+func (r *Relayer) logf(format string, args ...interface{}) {
+	if r.Log != nil {
+		fmt.Fprintf(r.Log, format+"\n", args...)
+	}
+}
+
+// save persists the players' state to config.StatePath. It must be called with r.mu held.
+func (r *Relayer) save() error {
+	if r.config.StatePath == "" {
+		return nil
+	}
+
+	state := State{
+		ChainID:  r.chainID.String(),
+		Contract: r.config.Contract,
+		Players:  make(map[common.Address]PlayerRecord, len(r.players)),
+	}
+	for player, playerState := range r.players {
+		state.Players[player] = PlayerRecord{
+			LastNonce:  playerState.lastNonce,
+			Recent:     playerState.recent,
+			BudgetDay:  playerState.budgetDay,
+			SpentOnDay: new(big.Int).Add(playerState.spentOnDay, playerState.reservedDay),
+		}
+	}
+	return state.Save(r.config.StatePath)
+}
+
+// LoadState reads relayer state from the given file. It returns a nil state (and no error) if the path
+// is empty or the file does not exist.
+func LoadState(path string) (*State, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	contents, readErr := os.ReadFile(path)
+	if errors.Is(readErr, os.ErrNotExist) {
+		return nil, nil
+	} else if readErr != nil {
+		return nil, readErr
+	}
+
+	var state State
+	if err := json.Unmarshal(contents, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse relayer state from %s: %v", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state to the given file. The file is replaced atomically so that a crash while
+// saving does not corrupt the previous state.
+func (s *State) Save(path string) error {
+	contents, marshalErr := json.MarshalIndent(s, "", "  ")
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	return atomicfile.WriteFile(path, contents)
+}
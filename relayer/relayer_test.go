@@ -0,0 +1,145 @@
+package relayer
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var testPlayer = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+
+// newTestRelayer creates a relayer which can check and reserve intents but not submit them.
+func newTestRelayer(t *testing.T, config Config) *Relayer {
+	t.Helper()
+	config.Contract = testContract
+	if config.StatePath == "" {
+		config.StatePath = filepath.Join(t.TempDir(), "players.json")
+	}
+	return &Relayer{
+		chainID: testChainID,
+		config:  config,
+		players: make(map[common.Address]*playerState),
+	}
+}
+
+func TestReserveNonces(t *testing.T) {
+	r := newTestRelayer(t, Config{})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 5), big.NewInt(0), now)
+	if err != nil {
+		t.Fatalf("failed to reserve first intent: %v", err)
+	}
+	for _, nonce := range []int64{5, 4} {
+		if _, err := r.reserve(newTestIntent(ActionAccept, testPlayer, nonce), big.NewInt(0), now); !errors.Is(err, ErrNonceUsed) {
+			t.Fatalf("expected nonce %d to be rejected, got: %v", nonce, err)
+		}
+	}
+
+	// Cancelling the intent, because its transaction could not be sent, lets the player use the nonce again.
+	r.cancel(newTestIntent(ActionSpin, testPlayer, 5), first)
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 5), big.NewInt(0), now); err != nil {
+		t.Fatalf("expected the cancelled nonce to be reusable: %v", err)
+	}
+
+	// Nonces are persisted, so intents cannot be replayed after a restart.
+	state, stateErr := LoadState(r.config.StatePath)
+	if stateErr != nil {
+		t.Fatalf("failed to load state: %v", stateErr)
+	}
+	if record := state.Players[testPlayer]; record.LastNonce == nil || record.LastNonce.Int64() != 5 {
+		t.Fatalf("expected nonce 5 to be persisted, got %+v", record)
+	}
+}
+
+func TestReserveRateLimit(t *testing.T) {
+	r := newTestRelayer(t, Config{RateLimit: 2, RateLimitWindow: time.Minute})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for nonce := int64(1); nonce <= 2; nonce++ {
+		if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, nonce), big.NewInt(0), now.Add(time.Duration(nonce)*time.Second)); err != nil {
+			t.Fatalf("failed to reserve intent %d: %v", nonce, err)
+		}
+	}
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 3), big.NewInt(0), now.Add(30*time.Second)); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected the third intent in the window to be rate limited, got: %v", err)
+	}
+	// A rate limited intent does not consume its nonce.
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 3), big.NewInt(0), now.Add(61*time.Second)); err != nil {
+		t.Fatalf("expected the intent to be accepted once the first one left the window: %v", err)
+	}
+
+	other := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	if _, err := r.reserve(newTestIntent(ActionSpin, other, 1), big.NewInt(0), now.Add(61*time.Second)); err != nil {
+		t.Fatalf("expected the rate limit to apply per player: %v", err)
+	}
+}
+
+func TestReserveBudget(t *testing.T) {
+	r := newTestRelayer(t, Config{DailyBudget: big.NewInt(100)})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 1), big.NewInt(60), now)
+	if err != nil {
+		t.Fatalf("failed to reserve the first intent: %v", err)
+	}
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 2), big.NewInt(50), now); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected the reservations to exceed the budget, got: %v", err)
+	}
+
+	// The first transaction used less gas than its gas limit allowed, which frees up the difference.
+	r.release(testPlayer, first, big.NewInt(30), now)
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 2), big.NewInt(70), now); err != nil {
+		t.Fatalf("expected the settled reservation to free up budget: %v", err)
+	}
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 3), big.NewInt(1), now); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected the budget to be exhausted, got: %v", err)
+	}
+}
+
+func TestReserveBudgetResetsAtUTCMidnight(t *testing.T) {
+	r := newTestRelayer(t, Config{DailyBudget: big.NewInt(100)})
+	beforeMidnight := time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)
+	afterMidnight := time.Date(2024, 5, 2, 0, 1, 0, 0, time.UTC)
+
+	spent, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 1), big.NewInt(40), beforeMidnight)
+	if err != nil {
+		t.Fatalf("failed to reserve: %v", err)
+	}
+	r.release(testPlayer, spent, big.NewInt(40), beforeMidnight)
+	pending, pendingErr := r.reserve(newTestIntent(ActionSpin, testPlayer, 2), big.NewInt(60), beforeMidnight)
+	if pendingErr != nil {
+		t.Fatalf("failed to reserve: %v", pendingErr)
+	}
+
+	// Both what was spent and what was still reserved yesterday are dropped at midnight.
+	today, todayErr := r.reserve(newTestIntent(ActionSpin, testPlayer, 3), big.NewInt(100), afterMidnight)
+	if todayErr != nil {
+		t.Fatalf("expected the budget to reset at midnight: %v", todayErr)
+	}
+
+	// Yesterday's transaction is mined today. It counts against yesterday's budget, not today's, and
+	// does not release any of today's reservation.
+	r.release(testPlayer, pending, big.NewInt(60), afterMidnight)
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 4), big.NewInt(1), afterMidnight); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected yesterday's settlement to leave today's reservation in place, got: %v", err)
+	}
+
+	r.release(testPlayer, today, big.NewInt(90), afterMidnight)
+	if _, err := r.reserve(newTestIntent(ActionSpin, testPlayer, 4), big.NewInt(10), afterMidnight); err != nil {
+		t.Fatalf("expected today's settlement to free up the unused budget: %v", err)
+	}
+
+	state, stateErr := LoadState(r.config.StatePath)
+	if stateErr != nil {
+		t.Fatalf("failed to load state: %v", stateErr)
+	}
+	record := state.Players[testPlayer]
+	if record.BudgetDay != afterMidnight.Unix()/86400 || record.SpentOnDay.Int64() != 100 {
+		t.Fatalf("expected 100 wei to be recorded for today, got %+v", record)
+	}
+}
@@ -0,0 +1,174 @@
+package relayer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Default amount of time the server waits for a relayed transaction to be mined before responding.
+const DefaultWaitTimeout = 30 * time.Second
+
+// Maximum size of an intent request body.
+const maxRequestSize = 64 * 1024
+
+// Server exposes a relayer over HTTP.
+//
+// Endpoints:
+//
+//	GET  /domain  - EIP-712 domain and types that players sign intents with
+//	POST /spin    - relay a signed SpinIntent
+//	POST /accept  - relay a signed AcceptIntent
+//
+// POST bodies are SignedIntent JSON objects. Responses are Result JSON objects. If the transaction is
+// not mined within WaitTimeout, the response has status "submitted" and the final result is only
+// delivered to the webhook.
+type Server struct {
+	Relayer     *Relayer
+	WaitTimeout time.Duration
+	// If set, every result is POSTed to this URL as JSON.
+	WebhookURL string
+	// Errors that occur while delivering webhooks are written here.
+	Log io.Writer
+
+	webhookClient *http.Client
+}
+
+// NewServer creates an HTTP server for the given relayer.
+func NewServer(r *Relayer, webhookURL string, log io.Writer) *Server {
+	return &Server{
+		Relayer:       r,
+		WaitTimeout:   DefaultWaitTimeout,
+		WebhookURL:    webhookURL,
+		Log:           log,
+		webhookClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Handler returns the HTTP handler for the server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /domain", s.handleDomain)
+	mux.HandleFunc("POST /spin", s.handleIntent(ActionSpin))
+	mux.HandleFunc("POST /accept", s.handleIntent(ActionAccept))
+	return mux
+}
+
+type domainResponse struct {
+	Domain apitypes.TypedDataDomain `json:"domain"`
+	Types  apitypes.Types           `json:"types"`
+}
+
+func (s *Server) handleDomain(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, domainResponse{
+		Domain: Domain(s.Relayer.ChainID(), s.Relayer.Contract()),
+		Types:  Types(),
+	})
+}
+
+func (s *Server) handleIntent(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var signed SignedIntent
+		decoder := json.NewDecoder(io.LimitReader(req.Body, maxRequestSize))
+		if err := decoder.Decode(&signed); err != nil {
+			writeJSON(w, http.StatusBadRequest, Result{Action: action, Status: StatusRejected, Error: fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+
+		rejected := Result{Action: action, Player: signed.Intent.Player, Status: StatusRejected}
+		if signed.Intent.Nonce != nil {
+			rejected.Nonce = (*big.Int)(signed.Intent.Nonce).String()
+		}
+
+		if signed.Intent.Action != action {
+			rejected.Error = fmt.Sprintf("intent action must be %s", action)
+			writeJSON(w, http.StatusBadRequest, rejected)
+			return
+		}
+
+		tx, results, submitErr := s.Relayer.Submit(req.Context(), signed)
+		if submitErr != nil {
+			rejected.Error = submitErr.Error()
+			writeJSON(w, statusForError(submitErr), rejected)
+			return
+		}
+
+		hash := tx.Hash()
+		pending := Result{
+			Action:          action,
+			Player:          signed.Intent.Player,
+			Nonce:           (*big.Int)(signed.Intent.Nonce).String(),
+			Status:          StatusSubmitted,
+			TransactionHash: &hash,
+		}
+
+		// The webhook receives the final result even if the HTTP client has gone away.
+		final := make(chan Result, 1)
+		go func() {
+			result := <-results
+			final <- result
+			s.deliverWebhook(result)
+		}()
+
+		timer := time.NewTimer(s.WaitTimeout)
+		defer timer.Stop()
+
+		select {
+		case result := <-final:
+			writeJSON(w, http.StatusOK, result)
+		case <-timer.C:
+			writeJSON(w, http.StatusAccepted, pending)
+		case <-req.Context().Done():
+		}
+	}
+}
+
+func (s *Server) deliverWebhook(result Result) {
+	if s.WebhookURL == "" {
+		return
+	}
+
+	body, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		fmt.Fprintf(s.Log, "Error: failed to encode webhook: %v\n", marshalErr)
+		return
+	}
+
+	response, postErr := s.webhookClient.Post(s.WebhookURL, "application/json", bytes.NewReader(body))
+	if postErr != nil {
+		fmt.Fprintf(s.Log, "Error: failed to deliver webhook: %v\n", postErr)
+		return
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= 300 {
+		fmt.Fprintf(s.Log, "Error: webhook returned status %d\n", response.StatusCode)
+	}
+}
+
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrBudgetExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrIntentExpired), errors.Is(err, ErrNonceUsed), errors.Is(err, ErrBoostNotSupported):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,119 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Notifier delivers notifications to players.
+type Notifier interface {
+	Notify(ctx context.Context, player Player, notification Notification) error
+}
+
+// WebhookNotifier POSTs each notification as JSON to a URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier which POSTs notifications to the given URL.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type webhookPayload struct {
+	Notification
+	Email string `json:"email,omitempty"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, player Player, notification Notification) error {
+	body, marshalErr := json.Marshal(webhookPayload{Notification: notification, Email: player.Email})
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	request, requestErr := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if requestErr != nil {
+		return requestErr
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, responseErr := n.Client.Do(request)
+	if responseErr != nil {
+		return fmt.Errorf("failed to deliver webhook: %v", responseErr)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SMTPNotifier emails each notification to the player. Players without an email address are skipped.
+type SMTPNotifier struct {
+	// Address (host:port) of the SMTP server.
+	Addr string
+	From string
+	// Optional. If nil, no authentication is attempted.
+	Auth smtp.Auth
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, player Player, notification Notification) error {
+	if player.Email == "" {
+		return nil
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", n.From)
+	fmt.Fprintf(&message, "To: %s\r\n", player.Email)
+	fmt.Fprintf(&message, "Subject: %s\r\n", notification.Subject())
+	fmt.Fprintf(&message, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&message, "%s\r\n", notification.Message)
+
+	if err := smtp.SendMail(n.Addr, n.Auth, n.From, []string{player.Email}, []byte(message.String())); err != nil {
+		return fmt.Errorf("failed to send email to %s: %v", player.Email, err)
+	}
+	return nil
+}
+
+// WriterNotifier writes notifications to a writer. It is useful for dry runs.
+type WriterNotifier struct {
+	Out io.Writer
+}
+
+func (n *WriterNotifier) Notify(ctx context.Context, player Player, notification Notification) error {
+	_, err := fmt.Fprintf(n.Out, "[%s] %s: %s\n", notification.Type, player.Address.Hex(), notification.Message)
+	return err
+}
+
+// MultiNotifier delivers notifications through several notifiers. It attempts every notifier and
+// returns the first error.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Notify(ctx context.Context, player Player, notification Notification) error {
+	var firstErr error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, player, notification); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Player is a player who has registered for streak reminders.
+type Player struct {
+	Address common.Address
+	// Optional. Used by the SMTP notifier.
+	Email string
+}
@@ -0,0 +1,274 @@
+// Package reminder implements a daemon which warns registered players before their DegenGambit
+// streaks break, and reports the rewards they lost when a streak does break.
+//
+// Streaks are tracked by the contract in UTC days (timestamp / 86400) and weeks (day / 7). A streak
+// can only be extended in the period immediately after the player's LastStreakDay or LastStreakWeek,
+// so the deadline for each streak is the end of that period.
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// Defaults for Config.
+const (
+	DefaultPollInterval     = time.Minute
+	DefaultDailyWarnBefore  = 2 * time.Hour
+	DefaultWeeklyWarnBefore = 24 * time.Hour
+)
+
+// Types of notification.
+const (
+	NotificationExpiring = "expiring"
+	NotificationBroken   = "broken"
+)
+
+// Kinds of streak.
+const (
+	StreakDaily  = "daily"
+	StreakWeekly = "weekly"
+)
+
+// Config describes which players to remind, and when.
+type Config struct {
+	// Address of the DegenGambit contract.
+	Contract common.Address
+	Players  []Player
+	// How long before the end of the day (or week) in which a player must spin to warn them that their
+	// daily (or weekly) streak is about to break.
+	DailyWarnBefore  time.Duration
+	WeeklyWarnBefore time.Duration
+	PollInterval     time.Duration
+}
+
+// Notification tells a player about one of their streaks.
+type Notification struct {
+	Player common.Address `json:"player"`
+	// NotificationExpiring or NotificationBroken.
+	Type string `json:"type"`
+	// StreakDaily or StreakWeekly.
+	Streak string `json:"streak"`
+	// Length of the streak which is expiring or broke.
+	Length uint64 `json:"length"`
+	// For expiring streaks, the time by which the player must spin. For broken streaks, the time at
+	// which the streak broke.
+	Deadline time.Time `json:"deadline"`
+	// GAMBIT (in wei) the player earns by extending the streak (for expiring streaks), or lost by not
+	// extending it (for broken streaks).
+	Reward  *big.Int `json:"reward"`
+	Message string   `json:"message"`
+
+	// Identifies the notification so that it is only delivered once.
+	key string
+}
+
+// Subject returns a short summary of the notification, e.g. for an email subject line.
+func (n Notification) Subject() string {
+	if n.Type == NotificationBroken {
+		return fmt.Sprintf("Your Degen's Gambit %s streak has ended", n.Streak)
+	}
+	return fmt.Sprintf("Your Degen's Gambit %s streak is about to end", n.Streak)
+}
+
+// Stats summarizes the notifications the daemon has sent.
+type Stats struct {
+	Expiring int
+	Broken   int
+	Failed   int
+	// Total streak rewards (in GAMBIT wei) lost by players whose streaks broke.
+	LostRewards *big.Int
+}
+
+// Reminder watches registered players' streaks and notifies them.
+type Reminder struct {
+	client   *ethclient.Client
+	contract *DegenGambit.DegenGambit
+	notifier Notifier
+	config   Config
+	out      io.Writer
+	rewards  gambit.StreakRewards
+
+	stats Stats
+	// Notifications already sent, keyed by player, type, streak and period, so that each is only
+	// sent once.
+	sent map[string]bool
+}
+
+// New creates a reminder daemon which delivers notifications through the given notifier. Progress
+// messages and delivery errors are written to out.
+func New(client *ethclient.Client, notifier Notifier, config Config, out io.Writer) (*Reminder, error) {
+	contract, contractErr := DegenGambit.NewDegenGambit(config.Contract, client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.DailyWarnBefore <= 0 {
+		config.DailyWarnBefore = DefaultDailyWarnBefore
+	}
+	if config.WeeklyWarnBefore <= 0 {
+		config.WeeklyWarnBefore = DefaultWeeklyWarnBefore
+	}
+	return &Reminder{
+		client:   client,
+		contract: contract,
+		notifier: notifier,
+		config:   config,
+		out:      out,
+		stats:    Stats{LostRewards: big.NewInt(0)},
+		sent:     make(map[string]bool),
+	}, nil
+}
+
+// Stats returns a copy of the daemon's statistics.
+func (r *Reminder) Stats() Stats {
+	stats := r.stats
+	stats.LostRewards = new(big.Int).Set(r.stats.LostRewards)
+	return stats
+}
+
+// Run checks players' streaks every PollInterval until the context is cancelled.
+func (r *Reminder) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Tick(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Fprintf(r.out, "Error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick checks every player's streaks once, using the timestamp of the latest block as the current
+// time.
+func (r *Reminder) Tick(ctx context.Context) error {
+	if r.rewards.Daily == nil {
+		rewards, rewardsErr := gambit.ReadStreakRewards(&bind.CallOpts{Context: ctx}, r.contract)
+		if rewardsErr != nil {
+			return rewardsErr
+		}
+		r.rewards = rewards
+	}
+
+	header, headerErr := r.client.HeaderByNumber(ctx, nil)
+	if headerErr != nil {
+		return headerErr
+	}
+	callOpts := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+
+	for _, player := range r.config.Players {
+		state, stateErr := gambit.ReadStreakState(callOpts, r.contract, player.Address)
+		if stateErr != nil {
+			return fmt.Errorf("failed to read streaks for %s: %v", player.Address.Hex(), stateErr)
+		}
+		for _, notification := range r.Check(player.Address, state, header.Time) {
+			r.deliver(ctx, player, notification)
+		}
+	}
+	return nil
+}
+
+// Check returns the notifications which are due for a player with the given streak state at the given
+// Unix timestamp. Notifications which have already been delivered are not returned again.
+func (r *Reminder) Check(player common.Address, state gambit.StreakState, now uint64) []Notification {
+	var notifications []Notification
+
+	if n, ok := r.checkStreak(player, StreakDaily, state.CurrentDailyStreakLength, state.LastStreakDay, state.DailyWindow(now), r.config.DailyWarnBefore, r.rewards.Daily, now); ok {
+		notifications = append(notifications, n)
+	}
+	if n, ok := r.checkStreak(player, StreakWeekly, state.CurrentWeeklyStreakLength, state.LastStreakWeek, state.WeeklyWindow(now), r.config.WeeklyWarnBefore, r.rewards.Weekly, now); ok {
+		notifications = append(notifications, n)
+	}
+
+	return notifications
+}
+
+func (r *Reminder) checkStreak(player common.Address, streak string, length, last uint64, window gambit.StreakWindow, warnBefore time.Duration, reward *big.Int, now uint64) (Notification, bool) {
+	notification := Notification{Player: player, Streak: streak, Length: length, Reward: reward}
+	var key string
+
+	switch window.Status {
+	case gambit.StreakExtends:
+		// The player must spin before the end of the current period.
+		remaining := window.End.Sub(time.Unix(int64(now), 0))
+		if remaining > warnBefore {
+			return Notification{}, false
+		}
+		notification.Type = NotificationExpiring
+		notification.Deadline = window.End
+		notification.Message = fmt.Sprintf("Your %s streak (length %d) ends at %s. Spin before then to extend it and earn %s GAMBIT.", streak, length, window.End.Format("2006-01-02 15:04 MST"), gambit.FormatUnits(reward, gambit.Decimals))
+		key = fmt.Sprintf("%s/%s/%s/%d", player.Hex(), NotificationExpiring, streak, window.Period)
+	case gambit.StreakBroken:
+		// The contract only resets the streak length when the player next spins, so a broken streak
+		// still has its old length. Streaks of length 0 have no rewards to lose. Only streaks which
+		// broke at the start of the current period are reported, so that players are not told about
+		// streaks which broke long ago.
+		if length == 0 || last == 0 || window.Period != last+2 {
+			return Notification{}, false
+		}
+		var brokeAt time.Time
+		if streak == StreakDaily {
+			brokeAt = gambit.DayStart(last + 2)
+		} else {
+			brokeAt = gambit.WeekStart(last + 2)
+		}
+		notification.Type = NotificationBroken
+		notification.Deadline = brokeAt
+		notification.Message = fmt.Sprintf("Your %s streak (length %d) ended at %s. You missed out on %s GAMBIT. Spin to start a new streak.", streak, length, brokeAt.Format("2006-01-02 15:04 MST"), gambit.FormatUnits(reward, gambit.Decimals))
+		key = fmt.Sprintf("%s/%s/%s/%d", player.Hex(), NotificationBroken, streak, last)
+	default:
+		return Notification{}, false
+	}
+
+	if r.sent[key] {
+		return Notification{}, false
+	}
+	notification.key = key
+	return notification, true
+}
+
+func (r *Reminder) deliver(ctx context.Context, player Player, notification Notification) {
+	if err := r.notifier.Notify(ctx, player, notification); err != nil {
+		// The notification is retried on the next tick.
+		r.stats.Failed++
+		fmt.Fprintf(r.out, "Error: failed to notify %s: %v\n", player.Address.Hex(), err)
+		return
+	}
+	r.sent[notification.key] = true
+
+	switch notification.Type {
+	case NotificationExpiring:
+		r.stats.Expiring++
+	case NotificationBroken:
+		r.stats.Broken++
+		r.stats.LostRewards.Add(r.stats.LostRewards, notification.Reward)
+	}
+	fmt.Fprintf(r.out, "Notified %s: %s %s streak (length %d)\n", player.Address.Hex(), notification.Type, notification.Streak, notification.Length)
+}
+
+// Report writes a human readable summary of the daemon's statistics to w.
+func (s Stats) Report(w io.Writer) {
+	fmt.Fprintf(w, "Expiry warnings sent: %d\nBroken streaks reported: %d\nFailed deliveries: %d\n", s.Expiring, s.Broken, s.Failed)
+	fmt.Fprintf(w, "Streak rewards lost: %s GAMBIT\n", gambit.FormatUnits(s.LostRewards, gambit.Decimals))
+}
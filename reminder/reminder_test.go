@@ -0,0 +1,339 @@
+package reminder
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// Day on which the tests' player last extended their streaks. The UTC week ends with this day, so
+// the day after it starts both a new day and a new week.
+const lastWeekDay uint64 = 20005
+
+var (
+	testPlayer = Player{Address: common.HexToAddress("0x00000000000000000000000000000000000000aa"), Email: "degen@example.com"}
+	// The player spun yesterday and in the previous week, so both streaks are extended by a spin today.
+	testState = gambit.StreakState{
+		LastStreakDay:             lastWeekDay - 1,
+		CurrentDailyStreakLength:  3,
+		LastStreakWeek:            gambit.Week(lastWeekDay) - 1,
+		CurrentWeeklyStreakLength: 2,
+	}
+	testRewards = gambit.StreakRewards{Daily: big.NewInt(1e18), Weekly: big.NewInt(5e18)}
+)
+
+func newTestReminder(notifier Notifier) *Reminder {
+	return &Reminder{
+		notifier: notifier,
+		config: Config{
+			Players:          []Player{testPlayer},
+			DailyWarnBefore:  DefaultDailyWarnBefore,
+			WeeklyWarnBefore: DefaultWeeklyWarnBefore,
+		},
+		out:     io.Discard,
+		rewards: testRewards,
+		stats:   Stats{LostRewards: big.NewInt(0)},
+		sent:    make(map[string]bool),
+	}
+}
+
+// checkAndDeliver delivers the notifications which are due at the given time, as Tick does.
+func checkAndDeliver(r *Reminder, state gambit.StreakState, now time.Time) []Notification {
+	notifications := r.Check(testPlayer.Address, state, uint64(now.Unix()))
+	for _, notification := range notifications {
+		r.deliver(context.Background(), testPlayer, notification)
+	}
+	return notifications
+}
+
+func notificationTypes(notifications []Notification) []string {
+	types := make([]string, len(notifications))
+	for i, notification := range notifications {
+		types[i] = notification.Type + "/" + notification.Streak
+	}
+	return types
+}
+
+// webhookStandIn records the notifications POSTed to it. It fails the first failures requests.
+type webhookStandIn struct {
+	mu       sync.Mutex
+	failures int
+	received []webhookPayload
+}
+
+func (w *webhookStandIn) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var payload webhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.received = append(w.received, payload)
+}
+
+func (w *webhookStandIn) payloads() []webhookPayload {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]webhookPayload{}, w.received...)
+}
+
+func TestWebhookRemindersAcrossDayAndWeekBoundaries(t *testing.T) {
+	standIn := &webhookStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	r := newTestReminder(NewWebhookNotifier(server.URL))
+	endOfWeek := gambit.DayStart(lastWeekDay + 1)
+	if endOfWeek != gambit.WeekStart(gambit.Week(lastWeekDay)+1) {
+		t.Fatalf("day %d is not the last day of a week", lastWeekDay)
+	}
+
+	// A day before the end of the week, only the weekly streak is close to expiring.
+	notifications := checkAndDeliver(r, testState, endOfWeek.Add(-23*time.Hour))
+	if types := notificationTypes(notifications); len(types) != 1 || types[0] != "expiring/weekly" {
+		t.Fatalf("expected a weekly expiry warning, got %v", types)
+	}
+
+	// An hour before midnight, the daily streak is also about to expire. The weekly warning is not
+	// sent again.
+	notifications = checkAndDeliver(r, testState, endOfWeek.Add(-time.Hour))
+	if types := notificationTypes(notifications); len(types) != 1 || types[0] != "expiring/daily" {
+		t.Fatalf("expected a daily expiry warning, got %v", types)
+	}
+	if notifications[0].Deadline != endOfWeek || notifications[0].Length != 3 || notifications[0].Reward.Cmp(testRewards.Daily) != 0 {
+		t.Errorf("unexpected daily expiry warning: %+v", notifications[0])
+	}
+
+	if notifications := checkAndDeliver(r, testState, endOfWeek.Add(-time.Minute)); len(notifications) != 0 {
+		t.Fatalf("expected no repeated warnings, got %v", notificationTypes(notifications))
+	}
+
+	// The player did not spin, so both streaks broke at midnight.
+	notifications = checkAndDeliver(r, testState, endOfWeek.Add(time.Hour))
+	if types := notificationTypes(notifications); len(types) != 2 || types[0] != "broken/daily" || types[1] != "broken/weekly" {
+		t.Fatalf("expected broken daily and weekly streaks, got %v", types)
+	}
+	for _, notification := range notifications {
+		if notification.Deadline != endOfWeek {
+			t.Errorf("expected %s streak to break at %s, got %s", notification.Streak, endOfWeek, notification.Deadline)
+		}
+	}
+
+	if notifications := checkAndDeliver(r, testState, endOfWeek.Add(2*time.Hour)); len(notifications) != 0 {
+		t.Fatalf("expected broken streaks to be reported once, got %v", notificationTypes(notifications))
+	}
+
+	// Streaks which broke before the current period are not reported, even by a reminder which did
+	// not see them break.
+	late := newTestReminder(NewWebhookNotifier(server.URL))
+	if notifications := checkAndDeliver(late, testState, endOfWeek.Add(25*time.Hour)); len(notifications) != 1 || notifications[0].Streak != StreakWeekly {
+		t.Fatalf("expected only the weekly streak to be reported a day after it broke, got %v", notificationTypes(notifications))
+	}
+
+	received := standIn.payloads()
+	if len(received) != 5 {
+		t.Fatalf("expected the webhook to receive 5 notifications, got %d", len(received))
+	}
+	for _, payload := range received {
+		if payload.Player != testPlayer.Address || payload.Email != testPlayer.Email || payload.Message == "" {
+			t.Errorf("unexpected webhook payload: %+v", payload)
+		}
+	}
+
+	stats := r.Stats()
+	if stats.Expiring != 2 || stats.Broken != 2 || stats.Failed != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	lost := new(big.Int).Add(testRewards.Daily, testRewards.Weekly)
+	if stats.LostRewards.Cmp(lost) != 0 {
+		t.Errorf("expected %s lost rewards, got %s", lost.String(), stats.LostRewards.String())
+	}
+}
+
+func TestWebhookFailureIsRetried(t *testing.T) {
+	standIn := &webhookStandIn{failures: 1}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	r := newTestReminder(NewWebhookNotifier(server.URL))
+	now := gambit.DayStart(lastWeekDay + 1).Add(-time.Hour)
+	state := testState
+	state.CurrentWeeklyStreakLength = 0
+	state.LastStreakWeek = gambit.Week(lastWeekDay)
+
+	checkAndDeliver(r, state, now)
+	if stats := r.Stats(); stats.Failed != 1 || stats.Expiring != 0 {
+		t.Fatalf("expected a failed delivery, got %+v", stats)
+	}
+
+	notifications := checkAndDeliver(r, state, now.Add(time.Minute))
+	if types := notificationTypes(notifications); len(types) != 1 || types[0] != "expiring/daily" {
+		t.Fatalf("expected the daily warning to be retried, got %v", types)
+	}
+	if stats := r.Stats(); stats.Expiring != 1 || len(standIn.payloads()) != 1 {
+		t.Fatalf("expected the retried warning to be delivered, got %+v", stats)
+	}
+}
+
+// smtpStandIn is a minimal SMTP server which accepts every message without authentication.
+type smtpStandIn struct {
+	listener net.Listener
+	messages chan smtpMessage
+}
+
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	listener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatalf("failed to listen: %v", listenErr)
+	}
+	s := &smtpStandIn{listener: listener, messages: make(chan smtpMessage, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP")
+
+	var message smtpMessage
+	for {
+		line, readErr := text.ReadLine()
+		if readErr != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			text.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message = smtpMessage{From: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, dataErr := io.ReadAll(text.DotReader())
+			if dataErr != nil {
+				return
+			}
+			message.Data = string(data)
+			s.messages <- message
+			text.PrintfLine("250 OK")
+		case command == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *smtpStandIn) next(t *testing.T) smtpMessage {
+	t.Helper()
+	select {
+	case message := <-s.messages:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for an email")
+		return smtpMessage{}
+	}
+}
+
+func TestSMTPRemindersAcrossDayAndWeekBoundaries(t *testing.T) {
+	standIn := newSMTPStandIn(t)
+	r := newTestReminder(&SMTPNotifier{Addr: standIn.listener.Addr().String(), From: "reminders@example.com"})
+	endOfWeek := gambit.DayStart(lastWeekDay + 1)
+
+	notifications := checkAndDeliver(r, testState, endOfWeek.Add(-time.Hour))
+	if types := notificationTypes(notifications); len(types) != 2 || types[0] != "expiring/daily" || types[1] != "expiring/weekly" {
+		t.Fatalf("expected daily and weekly expiry warnings, got %v", types)
+	}
+	for _, notification := range notifications {
+		message := standIn.next(t)
+		if message.From != "reminders@example.com" || len(message.To) != 1 || message.To[0] != testPlayer.Email {
+			t.Errorf("unexpected envelope: %+v", message)
+		}
+		headers, headersErr := textproto.NewReader(bufio.NewReader(strings.NewReader(message.Data))).ReadMIMEHeader()
+		if headersErr != nil {
+			t.Fatalf("failed to parse email headers: %v", headersErr)
+		}
+		if headers.Get("Subject") != notification.Subject() {
+			t.Errorf("expected subject %q, got %q", notification.Subject(), headers.Get("Subject"))
+		}
+		if !strings.Contains(message.Data, notification.Message) {
+			t.Errorf("email does not contain the notification message: %s", message.Data)
+		}
+	}
+
+	notifications = checkAndDeliver(r, testState, endOfWeek)
+	if types := notificationTypes(notifications); len(types) != 2 || types[0] != "broken/daily" || types[1] != "broken/weekly" {
+		t.Fatalf("expected broken daily and weekly streaks, got %v", types)
+	}
+	for _, notification := range notifications {
+		message := standIn.next(t)
+		if !strings.Contains(message.Data, "Subject: "+notification.Subject()) || !strings.Contains(message.Data, "You missed out on") {
+			t.Errorf("unexpected broken streak email: %s", message.Data)
+		}
+	}
+
+	if notifications := checkAndDeliver(r, testState, endOfWeek.Add(time.Hour)); len(notifications) != 0 {
+		t.Fatalf("expected no repeated notifications, got %v", notificationTypes(notifications))
+	}
+
+	// A player who spins on the new day extends neither streak (they are broken), and has no streak
+	// rewards to lose until the new streaks have a length.
+	restarted := gambit.StreakState{LastStreakDay: lastWeekDay + 1, LastStreakWeek: gambit.Week(lastWeekDay + 1)}
+	if notifications := checkAndDeliver(r, restarted, gambit.DayStart(lastWeekDay+3)); len(notifications) != 0 {
+		t.Fatalf("expected no notifications for streaks of length 0, got %v", notificationTypes(notifications))
+	}
+
+	if stats := r.Stats(); stats.Expiring != 2 || stats.Broken != 2 || stats.Failed != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestSMTPNotifierSkipsPlayersWithoutEmail(t *testing.T) {
+	// No server listens on this address, so any attempt to send would fail.
+	notifier := &SMTPNotifier{Addr: "127.0.0.1:1", From: "reminders@example.com"}
+	if err := notifier.Notify(context.Background(), Player{Address: testPlayer.Address}, Notification{}); err != nil {
+		t.Fatalf("expected players without an email address to be skipped, got: %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+// Package safetx builds Safe transactions and collects their owners' signatures without the Safe
+// transaction service, so that owners whose keys are kept on air-gapped machines can approve them.
+//
+// A Safe transaction is written to a JSON file, which is carried to each owner in turn. Each owner
+// signs the transaction's SafeTxHash (as computed by DegenGambit.CalculateSafeTxHash) and adds their
+// signature to the file. Files signed by different owners in parallel can be merged. Once enough
+// owners have signed, the signatures are packed in the order the Safe expects and the execTransaction
+// call is built.
+//
+// SafeTxHash includes the chain ID in its EIP-712 domain, as Safe contracts from version 1.3.0 onwards
+// expect. Older Safes compute a different hash and reject the signatures.
+package safetx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/G7DAO/seer/bindings/GnosisSafe"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
+)
+
+var ErrHashMismatch error = errors.New("SafeTxHash does not match the transaction")
+var ErrDifferentTransactions error = errors.New("cannot merge signatures for different Safe transactions")
+
+// Signature is an owner's signature of a SafeTxHash.
+type Signature struct {
+	Owner     common.Address `json:"owner"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// Transaction is a Safe transaction together with the signatures collected for it. It is the format
+// of the files which are passed between owners.
+type Transaction struct {
+	ChainID     *big.Int                        `json:"chainId"`
+	Safe        common.Address                  `json:"safe"`
+	Description string                          `json:"description,omitempty"`
+	Transaction DegenGambit.SafeTransactionData `json:"transaction"`
+	Signatures  []Signature                     `json:"signatures"`
+}
+
+// New builds an unsigned Safe transaction. Gas refunds are not used: safeTxGas, baseGas, and gasPrice
+// are zero, as in the proposals the bindings create.
+func New(chainID *big.Int, safe, to common.Address, value *big.Int, data []byte, operation DegenGambit.SafeOperationType, nonce *big.Int) (*Transaction, error) {
+	if operation.String() == "Unknown" {
+		return nil, fmt.Errorf("operation must be 0 (Call) or 1 (DelegateCall)")
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	tx := &Transaction{
+		ChainID: chainID,
+		Safe:    safe,
+		Transaction: DegenGambit.SafeTransactionData{
+			To:             to.Hex(),
+			Value:          value.String(),
+			Data:           common.Bytes2Hex(data),
+			Operation:      operation,
+			GasPrice:       "0",
+			GasToken:       DegenGambit.NativeTokenAddress,
+			RefundReceiver: DegenGambit.NativeTokenAddress,
+			Nonce:          nonce,
+		},
+		Signatures: []Signature{},
+	}
+
+	hash, hashErr := tx.ComputeHash()
+	if hashErr != nil {
+		return nil, hashErr
+	}
+	tx.Transaction.SafeTxHash = hash.Hex()
+	return tx, nil
+}
+
+// Load reads a Safe transaction from a JSON file and checks its hash and signatures.
+func Load(path string) (*Transaction, error) {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	var tx Transaction
+	if err := json.Unmarshal(contents, &tx); err != nil {
+		return nil, fmt.Errorf("failed to parse Safe transaction from %s: %v", path, err)
+	}
+	if err := tx.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Safe transaction %s: %v", path, err)
+	}
+	return &tx, nil
+}
+
+// Write writes the transaction to the given writer as indented JSON.
+func (t *Transaction) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t)
+}
+
+// Save writes the transaction to the given file as indented JSON.
+func (t *Transaction) Save(path string) error {
+	file, createErr := os.Create(path)
+	if createErr != nil {
+		return createErr
+	}
+	defer file.Close()
+	return t.Write(file)
+}
+
+// ComputeHash computes the SafeTxHash of the transaction from its fields.
+func (t *Transaction) ComputeHash() (common.Hash, error) {
+	if t.ChainID == nil {
+		return common.Hash{}, fmt.Errorf("chain ID not specified")
+	}
+	if t.Transaction.Nonce == nil {
+		return common.Hash{}, fmt.Errorf("nonce not specified")
+	}
+	return DegenGambit.CalculateSafeTxHash(t.Safe, t.Transaction, t.ChainID)
+}
+
+// Hash returns the SafeTxHash recorded in the transaction.
+func (t *Transaction) Hash() common.Hash {
+	return common.HexToHash(t.Transaction.SafeTxHash)
+}
+
+// Validate checks that the recorded SafeTxHash matches the transaction's fields, so that a file which
+// was edited after it was built cannot be signed or executed, and that every signature was made by
+// the owner it is attributed to.
+func (t *Transaction) Validate() error {
+	if !common.IsHexAddress(t.Transaction.To) {
+		return fmt.Errorf("to is not a valid Ethereum address")
+	}
+	if _, ok := new(big.Int).SetString(t.Transaction.Value, 10); !ok {
+		return fmt.Errorf("value is not a valid integer: %s", t.Transaction.Value)
+	}
+	if _, decodeErr := hexutil.Decode("0x" + t.Transaction.Data); decodeErr != nil && t.Transaction.Data != "" {
+		return fmt.Errorf("data is not valid hex: %v", decodeErr)
+	}
+	if t.Transaction.Operation.String() == "Unknown" {
+		return fmt.Errorf("operation must be 0 (Call) or 1 (DelegateCall)")
+	}
+
+	hash, hashErr := t.ComputeHash()
+	if hashErr != nil {
+		return hashErr
+	}
+	if hash != t.Hash() {
+		return fmt.Errorf("%w (recorded %s, computed %s)", ErrHashMismatch, t.Transaction.SafeTxHash, hash.Hex())
+	}
+
+	seen := make(map[common.Address]bool)
+	for _, signature := range t.Signatures {
+		owner, recoverErr := Recover(hash, signature.Signature)
+		if recoverErr != nil {
+			return fmt.Errorf("invalid signature by %s: %v", signature.Owner.Hex(), recoverErr)
+		}
+		if owner != signature.Owner {
+			return fmt.Errorf("signature attributed to %s was made by %s", signature.Owner.Hex(), owner.Hex())
+		}
+		if seen[owner] {
+			return fmt.Errorf("duplicate signature by %s", owner.Hex())
+		}
+		seen[owner] = true
+	}
+	return nil
+}
+
+// Sign signs the transaction's SafeTxHash and adds the signature to the transaction, replacing any
+// earlier signature by the same owner.
+func (t *Transaction) Sign(s signer.HashSigner) error {
+	hash, hashErr := t.ComputeHash()
+	if hashErr != nil {
+		return hashErr
+	}
+	if hash != t.Hash() {
+		return ErrHashMismatch
+	}
+
+	signature, signErr := s.SignHash(hash)
+	if signErr != nil {
+		return fmt.Errorf("failed to sign SafeTxHash: %v", signErr)
+	}
+	t.addSignature(Signature{Owner: s.Address(), Signature: signature})
+	return nil
+}
+
+// Merge adds the signatures from other copies of the same transaction. Each copy is validated first,
+// so that a copy with a hash or signatures which do not match its transaction cannot add signatures.
+func (t *Transaction) Merge(others ...*Transaction) error {
+	for _, other := range others {
+		if err := other.Validate(); err != nil {
+			return fmt.Errorf("invalid Safe transaction %s: %w", other.Transaction.SafeTxHash, err)
+		}
+		if other.Hash() != t.Hash() || other.Safe != t.Safe || other.ChainID.Cmp(t.ChainID) != 0 {
+			return fmt.Errorf("%w (%s and %s)", ErrDifferentTransactions, t.Transaction.SafeTxHash, other.Transaction.SafeTxHash)
+		}
+		for _, signature := range other.Signatures {
+			t.addSignature(signature)
+		}
+	}
+	return nil
+}
+
+// addSignature adds a signature, replacing any earlier signature by the same owner.
+func (t *Transaction) addSignature(signature Signature) {
+	for i, existing := range t.Signatures {
+		if existing.Owner == signature.Owner {
+			t.Signatures[i] = signature
+			return
+		}
+	}
+	t.Signatures = append(t.Signatures, signature)
+}
+
+// CheckOwners checks that every signature was made by one of the given owners and that there are at
+// least threshold signatures.
+func (t *Transaction) CheckOwners(owners []common.Address, threshold *big.Int) error {
+	isOwner := make(map[common.Address]bool, len(owners))
+	for _, owner := range owners {
+		isOwner[owner] = true
+	}
+	for _, signature := range t.Signatures {
+		if !isOwner[signature.Owner] {
+			return fmt.Errorf("%s is not an owner of the Safe %s", signature.Owner.Hex(), t.Safe.Hex())
+		}
+	}
+	if big.NewInt(int64(len(t.Signatures))).Cmp(threshold) < 0 {
+		return fmt.Errorf("the Safe %s requires %s signatures, but the transaction has %d", t.Safe.Hex(), threshold.String(), len(t.Signatures))
+	}
+	return nil
+}
+
+// PackedSignatures returns the signatures in the form execTransaction expects: 65-byte signatures,
+// concatenated in ascending order of their owners' addresses.
+func (t *Transaction) PackedSignatures() []byte {
+	signatures := make([]Signature, len(t.Signatures))
+	copy(signatures, t.Signatures)
+	sort.Slice(signatures, func(i, j int) bool {
+		return bytes.Compare(signatures[i].Owner.Bytes(), signatures[j].Owner.Bytes()) < 0
+	})
+
+	packed := make([]byte, 0, 65*len(signatures))
+	for _, signature := range signatures {
+		packed = append(packed, signature.Signature...)
+	}
+	return packed
+}
+
+// ExecTransactionArgs returns the arguments of the Safe's execTransaction method for the transaction.
+func (t *Transaction) ExecTransactionArgs() (to common.Address, value *big.Int, data []byte, operation uint8, safeTxGas, baseGas, gasPrice *big.Int, gasToken, refundReceiver common.Address, signatures []byte) {
+	value, _ = new(big.Int).SetString(t.Transaction.Value, 10)
+	gasPrice, ok := new(big.Int).SetString(t.Transaction.GasPrice, 10)
+	if !ok {
+		gasPrice = big.NewInt(0)
+	}
+	return common.HexToAddress(t.Transaction.To),
+		value,
+		common.FromHex(t.Transaction.Data),
+		uint8(t.Transaction.Operation),
+		new(big.Int).SetUint64(t.Transaction.SafeTxGas),
+		new(big.Int).SetUint64(t.Transaction.BaseGas),
+		gasPrice,
+		common.HexToAddress(t.Transaction.GasToken),
+		common.HexToAddress(t.Transaction.RefundReceiver),
+		t.PackedSignatures()
+}
+
+// ExecTransactionCalldata returns the calldata of the execTransaction call which executes the
+// transaction, to be sent to the Safe.
+func (t *Transaction) ExecTransactionCalldata() ([]byte, error) {
+	safeABI, abiErr := GnosisSafe.GnosisSafeMetaData.GetAbi()
+	if abiErr != nil {
+		return nil, abiErr
+	}
+	to, value, data, operation, safeTxGas, baseGas, gasPrice, gasToken, refundReceiver, signatures := t.ExecTransactionArgs()
+	return safeABI.Pack("execTransaction", to, value, data, operation, safeTxGas, baseGas, gasPrice, gasToken, refundReceiver, signatures)
+}
+
+// Recover returns the owner who made the given signature of a SafeTxHash. Only ECDSA signatures of
+// the hash itself (v = 27 or 28) are supported.
+func Recover(hash common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes, got %d", crypto.SignatureLength, len(signature))
+	}
+	v := signature[crypto.RecoveryIDOffset]
+	if v != 27 && v != 28 {
+		return common.Address{}, fmt.Errorf("unsupported signature type (v = %d)", v)
+	}
+
+	normalized := make([]byte, crypto.SignatureLength)
+	copy(normalized, signature)
+	normalized[crypto.RecoveryIDOffset] -= 27
+
+	publicKey, recoverErr := crypto.SigToPub(hash.Bytes(), normalized)
+	if recoverErr != nil {
+		return common.Address{}, recoverErr
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// ParseOperation parses a Safe operation type given as a number (0 or 1) or a name ("call" or
+// "delegatecall", case-insensitively).
+func ParseOperation(value string) (DegenGambit.SafeOperationType, error) {
+	switch strings.ToLower(value) {
+	case "0", "call":
+		return DegenGambit.Call, nil
+	case "1", "delegatecall":
+		return DegenGambit.DelegateCall, nil
+	}
+	return 0, fmt.Errorf("invalid Safe operation %q: must be 0 (Call) or 1 (DelegateCall)", value)
+}
@@ -0,0 +1,226 @@
+package safetx
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/G7DAO/seer/bindings/GnosisSafe"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+	"github.com/PermissionlessGames/degen-casino/signer"
+)
+
+var (
+	testChainID = big.NewInt(13746)
+	testSafe    = common.HexToAddress("0x5afe00000000000000000000000000000000cafe")
+	testTo      = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testData    = []byte{0x2d, 0x82, 0x36, 0xe8, 0x00, 0x01}
+)
+
+func newTestOwner(t *testing.T) *signer.KeySigner {
+	t.Helper()
+	key, keyErr := crypto.GenerateKey()
+	if keyErr != nil {
+		t.Fatalf("failed to generate key: %v", keyErr)
+	}
+	return signer.NewKeySigner(key)
+}
+
+func newTestTransaction(t *testing.T) *Transaction {
+	t.Helper()
+	tx, buildErr := New(testChainID, testSafe, testTo, big.NewInt(1000), testData, DegenGambit.Call, big.NewInt(4))
+	if buildErr != nil {
+		t.Fatalf("failed to build Safe transaction: %v", buildErr)
+	}
+	return tx
+}
+
+// copyThroughFile saves the transaction to a file and loads it again, as owners do when they pass the
+// file between them.
+func copyThroughFile(t *testing.T, tx *Transaction) *Transaction {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "safe-tx.json")
+	if err := tx.Save(path); err != nil {
+		t.Fatalf("failed to save Safe transaction: %v", err)
+	}
+	loaded, loadErr := Load(path)
+	if loadErr != nil {
+		t.Fatalf("failed to load Safe transaction: %v", loadErr)
+	}
+	return loaded
+}
+
+// word left-pads a value to 32 bytes, as abi.encode does.
+func word(value []byte) []byte {
+	return common.LeftPadBytes(value, 32)
+}
+
+// TestSafeTxHash checks the SafeTxHash against the EIP-712 hash that Safe contracts from version
+// 1.3.0 compute in getTransactionHash.
+func TestSafeTxHash(t *testing.T) {
+	tx := newTestTransaction(t)
+
+	domainTypeHash := crypto.Keccak256([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	domainSeparator := crypto.Keccak256(domainTypeHash, word(testChainID.Bytes()), word(testSafe.Bytes()))
+
+	safeTxTypeHash := crypto.Keccak256([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+	structHash := crypto.Keccak256(
+		safeTxTypeHash,
+		word(testTo.Bytes()),
+		word(big.NewInt(1000).Bytes()),
+		crypto.Keccak256(testData),
+		word([]byte{byte(DegenGambit.Call)}),
+		word(nil),
+		word(nil),
+		word(nil),
+		word(nil),
+		word(nil),
+		word(big.NewInt(4).Bytes()),
+	)
+	expected := common.BytesToHash(crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash))
+
+	if tx.Hash() != expected {
+		t.Fatalf("expected SafeTxHash %s, got %s", expected.Hex(), tx.Hash().Hex())
+	}
+}
+
+func TestSignMergeAndExecute(t *testing.T) {
+	owners := []*signer.KeySigner{newTestOwner(t), newTestOwner(t)}
+	tx := newTestTransaction(t)
+
+	// Each owner signs their own copy of the file.
+	copies := make([]*Transaction, len(owners))
+	for i, owner := range owners {
+		copies[i] = copyThroughFile(t, tx)
+		if err := copies[i].Sign(owner); err != nil {
+			t.Fatalf("owner %s failed to sign: %v", owner.Address().Hex(), err)
+		}
+		copies[i] = copyThroughFile(t, copies[i])
+	}
+
+	merged := copies[0]
+	if err := merged.Merge(copies[1]); err != nil {
+		t.Fatalf("failed to merge signatures: %v", err)
+	}
+	// Merging the same signatures again does not duplicate them.
+	if err := merged.Merge(copies...); err != nil {
+		t.Fatalf("failed to merge signatures again: %v", err)
+	}
+	if len(merged.Signatures) != len(owners) {
+		t.Fatalf("expected %d signatures, got %d", len(owners), len(merged.Signatures))
+	}
+	if err := merged.Validate(); err != nil {
+		t.Fatalf("merged transaction is invalid: %v", err)
+	}
+
+	threshold := big.NewInt(2)
+	ownerAddresses := []common.Address{owners[0].Address(), owners[1].Address()}
+	if err := merged.CheckOwners(ownerAddresses, threshold); err != nil {
+		t.Fatalf("CheckOwners failed: %v", err)
+	}
+	if err := copies[1].CheckOwners(ownerAddresses, threshold); err == nil {
+		t.Fatalf("expected a transaction with one signature to be below the threshold")
+	}
+	if err := merged.CheckOwners(ownerAddresses[:1], big.NewInt(1)); err == nil {
+		t.Fatalf("expected a signature by a non-owner to be rejected")
+	}
+
+	// The Safe requires signatures in ascending order of owner address.
+	packed := merged.PackedSignatures()
+	if len(packed) != 65*len(owners) {
+		t.Fatalf("expected %d bytes of signatures, got %d", 65*len(owners), len(packed))
+	}
+	var previous common.Address
+	for i := 0; i < len(owners); i++ {
+		owner, recoverErr := Recover(merged.Hash(), packed[65*i:65*(i+1)])
+		if recoverErr != nil {
+			t.Fatalf("failed to recover signature %d: %v", i, recoverErr)
+		}
+		if i > 0 && bytes.Compare(previous.Bytes(), owner.Bytes()) >= 0 {
+			t.Fatalf("signatures are not in ascending order of owner: %s before %s", previous.Hex(), owner.Hex())
+		}
+		previous = owner
+	}
+
+	calldata, calldataErr := merged.ExecTransactionCalldata()
+	if calldataErr != nil {
+		t.Fatalf("failed to build execTransaction calldata: %v", calldataErr)
+	}
+	safeABI, abiErr := GnosisSafe.GnosisSafeMetaData.GetAbi()
+	if abiErr != nil {
+		t.Fatalf("failed to parse Safe ABI: %v", abiErr)
+	}
+	method, methodErr := safeABI.MethodById(calldata[:4])
+	if methodErr != nil || method.Name != "execTransaction" {
+		t.Fatalf("calldata does not call execTransaction: %v", methodErr)
+	}
+	args, unpackErr := method.Inputs.Unpack(calldata[4:])
+	if unpackErr != nil {
+		t.Fatalf("failed to unpack execTransaction arguments: %v", unpackErr)
+	}
+	if args[0].(common.Address) != testTo || args[1].(*big.Int).Cmp(big.NewInt(1000)) != 0 || !bytes.Equal(args[2].([]byte), testData) || args[3].(uint8) != uint8(DegenGambit.Call) {
+		t.Errorf("unexpected execTransaction call arguments: %v", args[:4])
+	}
+	if !bytes.Equal(args[9].([]byte), packed) {
+		t.Errorf("execTransaction signatures do not match the packed signatures")
+	}
+}
+
+func TestMergeRejectsInvalidCopies(t *testing.T) {
+	owner := newTestOwner(t)
+	tx := newTestTransaction(t)
+
+	// A copy whose transaction was edited but whose recorded hash was not.
+	tampered := copyThroughFile(t, tx)
+	if err := tampered.Sign(owner); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	tampered.Transaction.Value = "1000000"
+	if err := tx.Merge(tampered); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected a copy with a mismatched hash to be rejected, got: %v", err)
+	}
+
+	// A copy with a signature attributed to somebody other than the owner who made it.
+	misattributed := copyThroughFile(t, tx)
+	if err := misattributed.Sign(owner); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	misattributed.Signatures[0].Owner = newTestOwner(t).Address()
+	if err := tx.Merge(misattributed); err == nil {
+		t.Fatalf("expected a misattributed signature to be rejected")
+	}
+
+	// A different transaction for the same Safe.
+	other, buildErr := New(testChainID, testSafe, testTo, big.NewInt(1000), testData, DegenGambit.Call, big.NewInt(5))
+	if buildErr != nil {
+		t.Fatalf("failed to build Safe transaction: %v", buildErr)
+	}
+	if err := tx.Merge(other); !errors.Is(err, ErrDifferentTransactions) {
+		t.Fatalf("expected a different transaction to be rejected, got: %v", err)
+	}
+
+	if len(tx.Signatures) != 0 {
+		t.Fatalf("rejected copies added %d signatures", len(tx.Signatures))
+	}
+}
+
+func TestSignRejectsTamperedTransaction(t *testing.T) {
+	tx := newTestTransaction(t)
+	tx.Transaction.To = common.HexToAddress("0x00000000000000000000000000000000000000bb").Hex()
+	if err := tx.Sign(newTestOwner(t)); !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected signing a tampered transaction to fail, got: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "safe-tx.json")
+	if err := tx.Save(path); err != nil {
+		t.Fatalf("failed to save Safe transaction: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected loading a tampered transaction to fail")
+	}
+}
@@ -0,0 +1,225 @@
+package safetx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DegenGambit"
+)
+
+// ProposeURL returns the Safe transaction service endpoint to which proposals for the given Safe are
+// posted, relative to the service's base URL. It is the URL format CreateSafeProposal in the bindings
+// uses (with base URL https://safe-client.safe.global).
+func ProposeURL(baseURL string, chainID *big.Int, safe common.Address) string {
+	return fmt.Sprintf("%s/v1/chains/%s/transactions/%s/propose", baseURL, chainID.String(), safe.Hex())
+}
+
+// proposal is the request body of a proposal, in the format CreateSafeProposal in the bindings posts.
+type proposal struct {
+	To             string `json:"to"`
+	Value          string `json:"value"`
+	Data           string `json:"data"`
+	Operation      int    `json:"operation"`
+	SafeTxGas      string `json:"safeTxGas"`
+	BaseGas        string `json:"baseGas"`
+	GasPrice       string `json:"gasPrice"`
+	GasToken       string `json:"gasToken"`
+	RefundReceiver string `json:"refundReceiver"`
+	Nonce          string `json:"nonce"`
+	SafeTxHash     string `json:"safeTxHash"`
+	Sender         string `json:"sender"`
+	Signature      string `json:"signature"`
+	Origin         string `json:"origin"`
+}
+
+// Propose posts each of the transaction's signatures to a Safe transaction service as a proposal, as
+// CreateSafeProposal in the bindings does for a single signature. proposeURL is the full endpoint (see
+// ProposeURL).
+func Propose(ctx context.Context, client *http.Client, proposeURL string, tx *Transaction) error {
+	if len(tx.Signatures) == 0 {
+		return fmt.Errorf("the Safe transaction has no signatures to propose")
+	}
+
+	for _, signature := range tx.Signatures {
+		body := proposal{
+			To:             tx.Transaction.To,
+			Value:          tx.Transaction.Value,
+			Data:           "0x" + tx.Transaction.Data,
+			Operation:      int(tx.Transaction.Operation),
+			SafeTxGas:      fmt.Sprintf("%d", tx.Transaction.SafeTxGas),
+			BaseGas:        fmt.Sprintf("%d", tx.Transaction.BaseGas),
+			GasPrice:       tx.Transaction.GasPrice,
+			GasToken:       tx.Transaction.GasToken,
+			RefundReceiver: tx.Transaction.RefundReceiver,
+			Nonce:          tx.Transaction.Nonce.String(),
+			SafeTxHash:     tx.Transaction.SafeTxHash,
+			Sender:         signature.Owner.Hex(),
+			Signature:      signature.Signature.String(),
+			Origin:         fmt.Sprintf("{\"url\":\"%s\",\"name\":\"Degen Casino\"}", proposeURL),
+		}
+		jsonBody, marshalErr := json.Marshal(body)
+		if marshalErr != nil {
+			return marshalErr
+		}
+
+		request, requestErr := http.NewRequestWithContext(ctx, http.MethodPost, proposeURL, bytes.NewReader(jsonBody))
+		if requestErr != nil {
+			return fmt.Errorf("failed to create request: %v", requestErr)
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		response, responseErr := client.Do(request)
+		if responseErr != nil {
+			return fmt.Errorf("failed to send proposal: %v", responseErr)
+		}
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+			return fmt.Errorf("proposal by %s rejected with status %d: %s", signature.Owner.Hex(), response.StatusCode, bytes.TrimSpace(responseBody))
+		}
+	}
+	return nil
+}
+
+// Service is a local stand-in for the Safe transaction service. It accepts proposals in the format
+// Propose and CreateSafeProposal in the bindings post, checks their SafeTxHash and signature, and
+// collects the signatures for each transaction. Collected transactions can be fetched in the format of
+// the files used by the offline workflow:
+//
+//	POST /v1/chains/{chainID}/transactions/{safe}/propose
+//	GET  /v1/transactions/{safeTxHash}
+type Service struct {
+	mu           sync.Mutex
+	transactions map[common.Hash]*Transaction
+}
+
+// NewService creates an empty stand-in transaction service.
+func NewService() *Service {
+	return &Service{transactions: make(map[common.Hash]*Transaction)}
+}
+
+// Transaction returns the transaction with the given SafeTxHash and the signatures proposed for it.
+func (s *Service) Transaction(hash common.Hash) (*Transaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.transactions[hash]
+	if !ok {
+		return nil, false
+	}
+	copied := *tx
+	copied.Signatures = append([]Signature{}, tx.Signatures...)
+	return &copied, true
+}
+
+// Handler returns the HTTP handler which serves the stand-in service's endpoints.
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chains/{chainID}/transactions/{safe}/propose", s.handlePropose)
+	mux.HandleFunc("GET /v1/transactions/{safeTxHash}", s.handleTransaction)
+	return mux
+}
+
+func (s *Service) handlePropose(w http.ResponseWriter, r *http.Request) {
+	chainID, ok := new(big.Int).SetString(r.PathValue("chainID"), 10)
+	if !ok {
+		http.Error(w, "invalid chain ID", http.StatusBadRequest)
+		return
+	}
+	if !common.IsHexAddress(r.PathValue("safe")) {
+		http.Error(w, "invalid Safe address", http.StatusBadRequest)
+		return
+	}
+	safe := common.HexToAddress(r.PathValue("safe"))
+
+	var body proposal
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("invalid proposal: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	tx, txErr := body.transaction(chainID, safe)
+	if txErr != nil {
+		http.Error(w, txErr.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, found := s.transactions[tx.Hash()]
+	if !found {
+		s.transactions[tx.Hash()] = tx
+	} else if err := existing.Merge(tx); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Service) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	hashBytes, decodeErr := hexutil.Decode(r.PathValue("safeTxHash"))
+	if decodeErr != nil || len(hashBytes) != common.HashLength {
+		http.Error(w, "invalid SafeTxHash", http.StatusBadRequest)
+		return
+	}
+	tx, ok := s.Transaction(common.BytesToHash(hashBytes))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	tx.Write(w)
+}
+
+// transaction converts a proposal into a transaction with a single signature, and validates it.
+func (p proposal) transaction(chainID *big.Int, safe common.Address) (*Transaction, error) {
+	nonce, ok := new(big.Int).SetString(p.Nonce, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid nonce: %s", p.Nonce)
+	}
+	var safeTxGas, baseGas uint64
+	if _, err := fmt.Sscanf(p.SafeTxGas, "%d", &safeTxGas); err != nil {
+		return nil, fmt.Errorf("invalid safeTxGas: %s", p.SafeTxGas)
+	}
+	if _, err := fmt.Sscanf(p.BaseGas, "%d", &baseGas); err != nil {
+		return nil, fmt.Errorf("invalid baseGas: %s", p.BaseGas)
+	}
+	if !common.IsHexAddress(p.Sender) {
+		return nil, fmt.Errorf("invalid sender: %s", p.Sender)
+	}
+	signature, signatureErr := hexutil.Decode(p.Signature)
+	if signatureErr != nil {
+		return nil, fmt.Errorf("invalid signature: %v", signatureErr)
+	}
+
+	tx := &Transaction{
+		ChainID: chainID,
+		Safe:    safe,
+		Transaction: DegenGambit.SafeTransactionData{
+			To:             p.To,
+			Value:          p.Value,
+			Data:           common.Bytes2Hex(common.FromHex(p.Data)),
+			Operation:      DegenGambit.SafeOperationType(p.Operation),
+			SafeTxGas:      safeTxGas,
+			BaseGas:        baseGas,
+			GasPrice:       p.GasPrice,
+			GasToken:       p.GasToken,
+			RefundReceiver: p.RefundReceiver,
+			Nonce:          nonce,
+			SafeTxHash:     p.SafeTxHash,
+		},
+		Signatures: []Signature{{Owner: common.HexToAddress(p.Sender), Signature: signature}},
+	}
+	if err := tx.Validate(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
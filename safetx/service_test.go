@@ -0,0 +1,105 @@
+package safetx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProposeToService(t *testing.T) {
+	service := NewService()
+	server := httptest.NewServer(service.Handler())
+	defer server.Close()
+
+	owners := []common.Address{}
+	tx := newTestTransaction(t)
+	for i := 0; i < 2; i++ {
+		owner := newTestOwner(t)
+		if err := tx.Sign(owner); err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		owners = append(owners, owner.Address())
+	}
+
+	ctx := context.Background()
+	proposeURL := ProposeURL(server.URL, testChainID, testSafe)
+	// Propose each owner's signature separately, as owners without access to each other's files do.
+	for i := range tx.Signatures {
+		single := *tx
+		single.Signatures = tx.Signatures[i : i+1]
+		if err := Propose(ctx, server.Client(), proposeURL, &single); err != nil {
+			t.Fatalf("failed to propose signature %d: %v", i, err)
+		}
+	}
+
+	collected, ok := service.Transaction(tx.Hash())
+	if !ok {
+		t.Fatalf("the service did not record the transaction")
+	}
+	if err := collected.CheckOwners(owners, big.NewInt(2)); err != nil {
+		t.Fatalf("the service did not collect both signatures: %v", err)
+	}
+
+	// The collected transaction can be fetched in the format of the offline workflow's files.
+	response, getErr := server.Client().Get(server.URL + "/v1/transactions/" + tx.Hash().Hex())
+	if getErr != nil {
+		t.Fatalf("failed to fetch transaction: %v", getErr)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+	var fetched Transaction
+	if err := json.NewDecoder(response.Body).Decode(&fetched); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if err := fetched.Validate(); err != nil {
+		t.Fatalf("fetched transaction is invalid: %v", err)
+	}
+	if !bytes.Equal(fetched.PackedSignatures(), tx.PackedSignatures()) {
+		t.Fatalf("fetched signatures do not match the proposed signatures")
+	}
+
+	if err := Propose(ctx, server.Client(), proposeURL, newTestTransaction(t)); err == nil {
+		t.Fatalf("expected proposing a transaction without signatures to fail")
+	}
+}
+
+func TestServiceRejectsInvalidProposals(t *testing.T) {
+	server := httptest.NewServer(NewService().Handler())
+	defer server.Close()
+
+	tx := newTestTransaction(t)
+	if err := tx.Sign(newTestOwner(t)); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	ctx := context.Background()
+
+	// The transaction was built for a different Safe, so its hash does not match.
+	otherSafe := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	if err := Propose(ctx, server.Client(), ProposeURL(server.URL, testChainID, otherSafe), tx); err == nil || !strings.Contains(err.Error(), "422") {
+		t.Fatalf("expected a proposal for the wrong Safe to be rejected, got: %v", err)
+	}
+
+	tampered := *tx
+	tampered.Transaction.Value = "1000000"
+	if err := Propose(ctx, server.Client(), ProposeURL(server.URL, testChainID, testSafe), &tampered); err == nil || !strings.Contains(err.Error(), "422") {
+		t.Fatalf("expected a tampered proposal to be rejected, got: %v", err)
+	}
+
+	response, getErr := server.Client().Get(server.URL + "/v1/transactions/" + tx.Hash().Hex())
+	if getErr != nil {
+		t.Fatalf("failed to fetch transaction: %v", getErr)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected rejected proposals not to be recorded, got status %d", response.StatusCode)
+	}
+}
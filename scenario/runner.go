@@ -0,0 +1,568 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DevDegenGambit"
+	"github.com/PermissionlessGames/degen-casino/gambit"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// StepResult is the outcome of a single step.
+type StepResult struct {
+	// Position of the step in the scenario, starting at 1.
+	Index int
+	Title string
+	// Assertions which did not hold.
+	Failures []string
+	// Error which stopped the step from running (for example, an unexpected revert). Steps after a
+	// step with an error are skipped, because the state they expect was not set up.
+	Err     error
+	Skipped bool
+}
+
+// Passed returns true if the step ran and all its assertions held.
+func (r StepResult) Passed() bool {
+	return !r.Skipped && r.Err == nil && len(r.Failures) == 0
+}
+
+// Result is the outcome of a scenario.
+type Result struct {
+	Scenario string
+	Steps    []StepResult
+}
+
+// Passed returns true if every step of the scenario passed.
+func (r Result) Passed() bool {
+	for _, step := range r.Steps {
+		if !step.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the number of steps which did not pass, including skipped steps.
+func (r Result) Failed() int {
+	failed := 0
+	for _, step := range r.Steps {
+		if !step.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// acceptance is what the most recent accept in a scenario did.
+type acceptance struct {
+	left, center, right uint64
+	prizeIndex          int
+	won                 bool
+	amount              *big.Int
+}
+
+// Runner runs scenarios against a DevDegenGambit contract, sending transactions from the account
+// managed by its transaction manager.
+type Runner struct {
+	client   *ethclient.Client
+	address  common.Address
+	contract *DevDegenGambit.DevDegenGambit
+	manager  *txmanager.Manager
+	out      io.Writer
+}
+
+// NewRunner creates a runner for the DevDegenGambit contract at the given address. The result of each
+// step is written to out as it completes.
+func NewRunner(client *ethclient.Client, manager *txmanager.Manager, contractAddress common.Address, out io.Writer) (*Runner, error) {
+	contract, contractErr := DevDegenGambit.NewDevDegenGambit(contractAddress, client)
+	if contractErr != nil {
+		return nil, contractErr
+	}
+	return &Runner{
+		client:   client,
+		address:  contractAddress,
+		contract: contract,
+		manager:  manager,
+		out:      out,
+	}, nil
+}
+
+// run is the state of a scenario while it runs.
+type run struct {
+	*Runner
+	scenario   *Scenario
+	self       common.Address
+	lastAccept *acceptance
+}
+
+// Run runs the steps of the scenario in order. A step whose action fails unexpectedly stops the
+// scenario, and the remaining steps are reported as skipped. Failed assertions do not stop the
+// scenario. The returned error is only non-nil if the context was cancelled.
+func (r *Runner) Run(ctx context.Context, scenario *Scenario) (Result, error) {
+	state := &run{Runner: r, scenario: scenario, self: r.manager.Address()}
+	result := Result{Scenario: scenario.Name}
+
+	stopped := false
+	for i, step := range scenario.Steps {
+		stepResult := StepResult{Index: i + 1, Title: step.Title()}
+		if stopped {
+			stepResult.Skipped = true
+		} else {
+			stepResult.Failures, stepResult.Err = state.step(ctx, step)
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			stopped = stepResult.Err != nil
+		}
+		result.Steps = append(result.Steps, stepResult)
+		stepResult.Report(r.out)
+	}
+
+	return result, nil
+}
+
+// Report writes a line describing the outcome of the step to w, followed by its failed assertions.
+func (r StepResult) Report(w io.Writer) {
+	status := "PASS"
+	switch {
+	case r.Skipped:
+		status = "SKIP"
+	case !r.Passed():
+		status = "FAIL"
+	}
+	fmt.Fprintf(w, "%s  %d. %s\n", status, r.Index, r.Title)
+	if r.Err != nil {
+		fmt.Fprintf(w, "        error: %v\n", r.Err)
+	}
+	for _, failure := range r.Failures {
+		fmt.Fprintf(w, "        %s\n", failure)
+	}
+}
+
+// step performs the step's action and checks its expectations.
+func (r *run) step(ctx context.Context, step Step) ([]string, error) {
+	var failures []string
+
+	if step.Action() != "" {
+		reason, actionErr := r.act(ctx, step)
+		if actionErr != nil {
+			return nil, actionErr
+		}
+		switch {
+		case step.ExpectRevert == "" && reason != "":
+			return nil, fmt.Errorf("%s reverted: %s", step.Action(), reason)
+		case step.ExpectRevert != "" && reason == "":
+			failures = append(failures, fmt.Sprintf("expected revert with %s, but %s succeeded", step.ExpectRevert, step.Action()))
+		case step.ExpectRevert != "" && reason != step.ExpectRevert:
+			failures = append(failures, fmt.Sprintf("expected revert with %s, got %s", step.ExpectRevert, reason))
+		}
+	}
+
+	if step.Expect != nil {
+		expectFailures, expectErr := r.check(ctx, step.Expect)
+		if expectErr != nil {
+			return failures, expectErr
+		}
+		failures = append(failures, expectFailures...)
+	}
+
+	return failures, nil
+}
+
+// act performs the step's action. It returns the reason the action reverted, or an empty string if
+// it succeeded.
+func (r *run) act(ctx context.Context, step Step) (string, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	resolve := func(player string) common.Address {
+		// Players were checked by Validate.
+		address, _ := r.scenario.Resolve(player, r.self)
+		return address
+	}
+
+	switch {
+	case step.SetEntropy != nil:
+		player := resolve(step.SetEntropy.Player)
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetEntropy(opts, player, step.SetEntropy.Entropy.Int)
+		})
+
+	case step.SetOutcome != nil:
+		player := resolve(step.SetOutcome.Player)
+		reels := step.SetOutcome.Reels
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetEntropyFromOutcomes(opts, new(big.Int).SetUint64(reels[0]), new(big.Int).SetUint64(reels[1]), new(big.Int).SetUint64(reels[2]), player, step.SetOutcome.Boost)
+		})
+
+	case step.SetEntropySource != nil:
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetEntropySource(opts, step.SetEntropySource.FromHash)
+		})
+
+	case step.SetLastSpinBlock != nil:
+		player := resolve(step.SetLastSpinBlock.Player)
+		var block uint64
+		if step.SetLastSpinBlock.Block != nil {
+			block = *step.SetLastSpinBlock.Block
+		} else {
+			head, headErr := r.client.BlockNumber(ctx)
+			if headErr != nil {
+				return "", headErr
+			}
+			if *step.SetLastSpinBlock.BlocksAgo > head {
+				return "", fmt.Errorf("blocks-ago (%d) is greater than the latest block number (%d)", *step.SetLastSpinBlock.BlocksAgo, head)
+			}
+			block = head - *step.SetLastSpinBlock.BlocksAgo
+		}
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetLastSpinBlock(opts, player, new(big.Int).SetUint64(block))
+		})
+
+	case step.SetLastSpinBoosted != nil:
+		player := resolve(step.SetLastSpinBoosted.Player)
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetLastSpinBoosted(opts, player, step.SetLastSpinBoosted.Boosted)
+		})
+
+	case step.SetDailyStreak != nil:
+		player := resolve(step.SetDailyStreak.Player)
+		day, dayErr := r.period(ctx, step.SetDailyStreak.Day, step.SetDailyStreak.DaysAgo, false)
+		if dayErr != nil {
+			return "", dayErr
+		}
+		reason, err := r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetDailyStreak(opts, new(big.Int).SetUint64(day), player)
+		})
+		if reason != "" || err != nil || step.SetDailyStreak.Length == nil {
+			return reason, err
+		}
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetDailyStreakLength(opts, new(big.Int).SetUint64(*step.SetDailyStreak.Length), player)
+		})
+
+	case step.SetWeeklyStreak != nil:
+		player := resolve(step.SetWeeklyStreak.Player)
+		week, weekErr := r.period(ctx, step.SetWeeklyStreak.Week, step.SetWeeklyStreak.WeeksAgo, true)
+		if weekErr != nil {
+			return "", weekErr
+		}
+		reason, err := r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetWeeklyStreak(opts, new(big.Int).SetUint64(week), player)
+		})
+		if reason != "" || err != nil || step.SetWeeklyStreak.Length == nil {
+			return reason, err
+		}
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetWeeklyStreakLength(opts, new(big.Int).SetUint64(*step.SetWeeklyStreak.Length), player)
+		})
+
+	case step.MintGambit != nil:
+		to := resolve(step.MintGambit.To)
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.MintGambit(opts, to, step.MintGambit.Amount.Int)
+		})
+
+	case step.SetCostToSpin != nil:
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetCostToSpin(opts, step.SetCostToSpin.Int)
+		})
+
+	case step.SetCostToRespin != nil:
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetCostToRespin(opts, step.SetCostToRespin.Int)
+		})
+
+	case step.SetBlocksToAct != nil:
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return r.contract.SetBlocksToAct(opts, new(big.Int).SetUint64(*step.SetBlocksToAct))
+		})
+
+	case step.Fund != nil:
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			opts.Value = step.Fund.Int
+			return r.contract.Receive(opts)
+		})
+
+	case step.Spin != nil:
+		var value *big.Int
+		if step.Spin.Value != nil {
+			value = step.Spin.Value.Int
+		} else {
+			spinCost, spinCostErr := r.contract.SpinCost(callOpts, r.self)
+			if spinCostErr != nil {
+				return "", fmt.Errorf("failed to get spinCost: %v", spinCostErr)
+			}
+			value = spinCost
+		}
+		return r.send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			opts.Value = value
+			return r.contract.Spin(opts, step.Spin.Boost)
+		})
+
+	case step.Accept != nil:
+		return r.accept(ctx, step.Accept)
+	}
+
+	return "", nil
+}
+
+// accept accepts the outcome of a spin and records the prize it awarded. The accept is sent first, so
+// that expected reverts (e.g. DeadlineExceeded) are reported as the accept's revert reason.
+func (r *run) accept(ctx context.Context, accept *Accept) (string, error) {
+	player := r.self
+	if accept.For != "" {
+		player, _ = r.scenario.Resolve(accept.For, r.self)
+	}
+
+	receipt, reason, err := r.transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if accept.For != "" {
+			return r.contract.AcceptFor(opts, player)
+		}
+		return r.contract.Accept(opts)
+	})
+	if reason != "" || err != nil {
+		return reason, err
+	}
+
+	left, center, right, outcomeErr := r.acceptedOutcome(ctx, player, receipt.BlockNumber)
+	if outcomeErr != nil {
+		return "", outcomeErr
+	}
+
+	result := &acceptance{left: left, center: center, right: right, amount: big.NewInt(0)}
+	result.prizeIndex, result.won = gambit.PrizeIndex(result.left, result.center, result.right)
+	for _, log := range receipt.Logs {
+		if award, parseErr := r.contract.ParseAward(*log); parseErr == nil && award.Player == player {
+			result.amount = award.Value
+		}
+	}
+	r.lastAccept = result
+	return "", nil
+}
+
+// acceptedOutcome returns the reels of the spin which the player accepted in the given block. Accepting
+// clears the player's spin, so the spin is read from the state before that block. The outcome is
+// computed with outcome rather than inspectOutcome, whose deadline and tick checks would run against
+// the earlier block's number rather than the block in which the spin was accepted.
+func (r *run) acceptedOutcome(ctx context.Context, player common.Address, acceptBlock *big.Int) (left, center, right uint64, err error) {
+	before := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).Sub(acceptBlock, big.NewInt(1))}
+
+	boosted, boostedErr := r.contract.LastSpinBoosted(before, player)
+	if boostedErr != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get LastSpinBoosted for %s: %v", player.Hex(), boostedErr)
+	}
+	entropyIsHash, entropyIsHashErr := r.contract.EntropyIsHash(before)
+	if entropyIsHashErr != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get EntropyIsHash: %v", entropyIsHashErr)
+	}
+
+	var entropy *big.Int
+	if entropyIsHash {
+		spinBlock, spinBlockErr := r.contract.LastSpinBlock(before, player)
+		if spinBlockErr != nil {
+			return 0, 0, 0, fmt.Errorf("failed to get LastSpinBlock for %s: %v", player.Hex(), spinBlockErr)
+		}
+		// The hash is read from the RPC response rather than recomputed from the header, which
+		// go-ethereum cannot do for the blocks of Arbitrum chains.
+		block := new(blockSummary)
+		if err := r.client.Client().CallContext(ctx, block, "eth_getBlockByNumber", hexutil.EncodeBig(spinBlock), false); err != nil {
+			return 0, 0, 0, fmt.Errorf("failed to get block %s: %v", spinBlock.String(), err)
+		}
+		entropy = gambit.Entropy(block.Hash, player)
+	} else {
+		forced, forcedErr := r.contract.EntropyForPlayer(before, player)
+		if forcedErr != nil {
+			return 0, 0, 0, fmt.Errorf("failed to get EntropyForPlayer for %s: %v", player.Hex(), forcedErr)
+		}
+		entropy = forced
+	}
+
+	outcome, outcomeErr := r.contract.Outcome(&bind.CallOpts{Context: ctx, BlockNumber: acceptBlock}, entropy, boosted)
+	if outcomeErr != nil {
+		return 0, 0, 0, fmt.Errorf("failed to compute outcome for %s: %v", player.Hex(), outcomeErr)
+	}
+	return outcome.Left.Uint64(), outcome.Center.Uint64(), outcome.Right.Uint64(), nil
+}
+
+// blockSummary holds the fields of a block that acceptedOutcome needs.
+type blockSummary struct {
+	Hash common.Hash `json:"hash"`
+}
+
+// period returns the streak day (or week, if weekly is true) given either explicitly or as a number of
+// periods before the one containing the latest block.
+func (r *run) period(ctx context.Context, explicit, ago *uint64, weekly bool) (uint64, error) {
+	if explicit != nil {
+		return *explicit, nil
+	}
+
+	header, headerErr := r.client.HeaderByNumber(ctx, nil)
+	if headerErr != nil {
+		return 0, headerErr
+	}
+	current := gambit.Day(header.Time)
+	if weekly {
+		current = gambit.Week(current)
+	}
+	if ago == nil {
+		return current, nil
+	}
+	if *ago > current {
+		return 0, fmt.Errorf("cannot go back %d periods from period %d", *ago, current)
+	}
+	return current - *ago, nil
+}
+
+// send submits a transaction, waits for it to be mined, and returns the reason it reverted (or an
+// empty string if it succeeded).
+func (r *run) send(ctx context.Context, transact txmanager.TransactFunc) (string, error) {
+	_, reason, err := r.transact(ctx, transact)
+	return reason, err
+}
+
+// transact is send, additionally returning the receipt of a successful transaction.
+func (r *run) transact(ctx context.Context, transact txmanager.TransactFunc) (*types.Receipt, string, error) {
+	tx, txErr := r.manager.Transact(ctx, transact)
+	if txErr != nil {
+		if reason := gambit.RevertReason(txErr); reason != "" {
+			return nil, reason, nil
+		}
+		return nil, "", txErr
+	}
+
+	receipt, waitErr := bind.WaitMined(ctx, r.client, tx)
+	if waitErr != nil {
+		return nil, "", waitErr
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, r.replayRevert(ctx, tx, receipt), nil
+	}
+	return receipt, "", nil
+}
+
+// replayRevert replays a reverted transaction at the block it was mined in to find out why it reverted.
+func (r *run) replayRevert(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) string {
+	msg := ethereum.CallMsg{
+		From:  r.self,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	_, callErr := r.client.CallContract(ctx, msg, receipt.BlockNumber)
+	if reason := gambit.RevertReason(callErr); reason != "" {
+		return reason
+	}
+	return "reverted (unknown reason)"
+}
+
+// check evaluates the step's expectations and returns the ones which do not hold.
+func (r *run) check(ctx context.Context, expect *Expect) ([]string, error) {
+	var failures []string
+	failf := func(format string, args ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	player, _ := r.scenario.Resolve(expect.Player, r.self)
+
+	if expect.Prize != nil || expect.NoPrize || expect.PrizeAmount != nil || expect.Reels != nil {
+		accepted := r.lastAccept
+		if accepted == nil {
+			failf("expected an accept before checking its prize, but nothing was accepted yet")
+		} else {
+			if expect.Prize != nil {
+				if !accepted.won {
+					failf("expected prize %d, got no prize", *expect.Prize)
+				} else if accepted.prizeIndex != *expect.Prize {
+					failf("expected prize %d, got prize %d", *expect.Prize, accepted.prizeIndex)
+				}
+			}
+			if expect.NoPrize && accepted.won {
+				failf("expected no prize, got prize %d of %s", accepted.prizeIndex, accepted.amount.String())
+			}
+			if expect.PrizeAmount != nil && accepted.amount.Cmp(expect.PrizeAmount.Int) != 0 {
+				failf("expected prize amount %s, got %s", expect.PrizeAmount.String(), accepted.amount.String())
+			}
+			if expect.Reels != nil {
+				got := []uint64{accepted.left, accepted.center, accepted.right}
+				for i := range got {
+					if got[i] != expect.Reels[i] {
+						failf("expected reels %v, got %v", expect.Reels, got)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if expect.HasPrize != nil {
+		hasPrize, hasPrizeErr := r.contract.HasPrize(callOpts, player)
+		if hasPrizeErr != nil {
+			return failures, fmt.Errorf("failed to check hasPrize for %s: %v", player.Hex(), hasPrizeErr)
+		}
+		if hasPrize != *expect.HasPrize {
+			failf("expected hasPrize(%s) to be %t, got %t", player.Hex(), *expect.HasPrize, hasPrize)
+		}
+	}
+
+	if expect.DailyStreakLength != nil {
+		length, lengthErr := r.contract.CurrentDailyStreakLength(callOpts, player)
+		if lengthErr != nil {
+			return failures, fmt.Errorf("failed to get CurrentDailyStreakLength for %s: %v", player.Hex(), lengthErr)
+		}
+		if !length.IsUint64() || length.Uint64() != *expect.DailyStreakLength {
+			failf("expected daily streak length of %d for %s, got %s", *expect.DailyStreakLength, player.Hex(), length.String())
+		}
+	}
+
+	if expect.WeeklyStreakLength != nil {
+		length, lengthErr := r.contract.CurrentWeeklyStreakLength(callOpts, player)
+		if lengthErr != nil {
+			return failures, fmt.Errorf("failed to get CurrentWeeklyStreakLength for %s: %v", player.Hex(), lengthErr)
+		}
+		if !length.IsUint64() || length.Uint64() != *expect.WeeklyStreakLength {
+			failf("expected weekly streak length of %d for %s, got %s", *expect.WeeklyStreakLength, player.Hex(), length.String())
+		}
+	}
+
+	if expect.GambitBalance != nil {
+		balance, balanceErr := r.contract.BalanceOf(callOpts, player)
+		if balanceErr != nil {
+			return failures, fmt.Errorf("failed to get GAMBIT balance of %s: %v", player.Hex(), balanceErr)
+		}
+		if balance.Cmp(expect.GambitBalance.Int) != 0 {
+			failf("expected GAMBIT balance of %s for %s, got %s", expect.GambitBalance.String(), player.Hex(), balance.String())
+		}
+	}
+
+	if expect.SpinCost != nil {
+		spinCost, spinCostErr := r.contract.SpinCost(callOpts, player)
+		if spinCostErr != nil {
+			return failures, fmt.Errorf("failed to get spinCost for %s: %v", player.Hex(), spinCostErr)
+		}
+		if spinCost.Cmp(expect.SpinCost.Int) != 0 {
+			failf("expected spinCost of %s for %s, got %s", expect.SpinCost.String(), player.Hex(), spinCost.String())
+		}
+	}
+
+	if expect.Pot != nil {
+		pot, potErr := r.client.BalanceAt(ctx, r.address, nil)
+		if potErr != nil {
+			return failures, fmt.Errorf("failed to get balance of %s: %v", r.address.Hex(), potErr)
+		}
+		if pot.Cmp(expect.Pot.Int) != 0 {
+			failf("expected pot of %s, got %s", expect.Pot.String(), pot.String())
+		}
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,430 @@
+// Package scenario runs scripted sequences of setup, spin, accept, and assertion steps against a
+// DevDegenGambit deployment, so that bugs in Degen's Gambit can be reproduced from a file instead of
+// by hand.
+//
+// A scenario is a YAML document. Each step performs at most one action (a DevDegenGambit setter, a
+// spin, an accept, or a transfer into the pot) and then checks its expectations. For example:
+//
+//	name: Minor pair with a major in the center pays prize 3
+//	players:
+//	  bob: "0x000000000000000000000000000000000000b0b0"
+//	steps:
+//	  - fund: 1000000000000000000
+//	  - set-outcome: {reels: [2, 17, 2]}
+//	  - spin
+//	  - name: accept pays 100 times the cost to spin
+//	    accept:
+//	    expect: {prize: 3, prize-amount: 1000000000000000}
+//	  - set-daily-streak: {player: bob, days-ago: 1, length: 4}
+//
+// Players are referred to by the aliases defined under players, by address, or as "self" (the
+// account running the scenario, which is also the default). Steps that spin and accept always act as
+// self, because the contract only lets players spin for themselves.
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+
+	"github.com/PermissionlessGames/degen-casino/gambit"
+)
+
+// Alias for the account that runs the scenario.
+const Self = "self"
+
+// ErrNoSteps is returned when a scenario file does not contain any steps.
+var ErrNoSteps error = errors.New("scenario has no steps")
+
+// Scenario is a scripted sequence of steps.
+type Scenario struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Aliases for the addresses of players referred to in the steps.
+	Players map[string]string `yaml:"players"`
+	Steps   []Step            `yaml:"steps"`
+}
+
+// Step performs at most one action and then checks its expectations.
+type Step struct {
+	Name string `yaml:"name"`
+
+	SetEntropy         *SetEntropy         `yaml:"set-entropy"`
+	SetOutcome         *SetOutcome         `yaml:"set-outcome"`
+	SetEntropySource   *SetEntropySource   `yaml:"set-entropy-source"`
+	SetLastSpinBlock   *SetLastSpinBlock   `yaml:"set-last-spin-block"`
+	SetLastSpinBoosted *SetLastSpinBoosted `yaml:"set-last-spin-boosted"`
+	SetDailyStreak     *SetDailyStreak     `yaml:"set-daily-streak"`
+	SetWeeklyStreak    *SetWeeklyStreak    `yaml:"set-weekly-streak"`
+	MintGambit         *MintGambit         `yaml:"mint-gambit"`
+	SetCostToSpin      *Wei                `yaml:"set-cost-to-spin"`
+	SetCostToRespin    *Wei                `yaml:"set-cost-to-respin"`
+	SetBlocksToAct     *uint64             `yaml:"set-blocks-to-act"`
+	Fund               *Wei                `yaml:"fund"`
+	Spin               *Spin               `yaml:"spin"`
+	Accept             *Accept             `yaml:"accept"`
+
+	// Name of the custom error (e.g. WaitForTick) or revert string the action is expected to revert
+	// with. If empty, the action is expected to succeed and a revert fails the scenario.
+	ExpectRevert string  `yaml:"expect-revert"`
+	Expect       *Expect `yaml:"expect"`
+}
+
+// SetEntropy calls setEntropy, which fixes the entropy used for the player's next spin.
+type SetEntropy struct {
+	Player  string `yaml:"player"`
+	Entropy Wei    `yaml:"entropy"`
+}
+
+// SetOutcome calls setEntropyFromOutcomes, which fixes the symbols the player's next spin lands on.
+type SetOutcome struct {
+	Player string   `yaml:"player"`
+	Reels  []uint64 `yaml:"reels"`
+	// Whether the outcome is for a boosted spin (which uses the improved reels).
+	Boost bool `yaml:"boost"`
+}
+
+// SetEntropySource calls setEntropySource. If FromHash is true, spins use block hashes for entropy
+// as on a production deployment; otherwise they use the entropy set with set-entropy or set-outcome.
+type SetEntropySource struct {
+	FromHash bool `yaml:"from-hash"`
+}
+
+// SetLastSpinBlock calls setLastSpinBlock. Exactly one of Block and BlocksAgo must be set; BlocksAgo
+// is relative to the latest block when the step runs.
+type SetLastSpinBlock struct {
+	Player    string  `yaml:"player"`
+	Block     *uint64 `yaml:"block"`
+	BlocksAgo *uint64 `yaml:"blocks-ago"`
+}
+
+// SetLastSpinBoosted calls setLastSpinBoosted.
+type SetLastSpinBoosted struct {
+	Player  string `yaml:"player"`
+	Boosted bool   `yaml:"boosted"`
+}
+
+// SetDailyStreak calls setDailyStreak and, if Length is set, setDailyStreakLength. At most one of Day
+// and DaysAgo may be set; DaysAgo is relative to the streak day of the latest block.
+type SetDailyStreak struct {
+	Player  string  `yaml:"player"`
+	Day     *uint64 `yaml:"day"`
+	DaysAgo *uint64 `yaml:"days-ago"`
+	Length  *uint64 `yaml:"length"`
+}
+
+// SetWeeklyStreak calls setWeeklyStreak and, if Length is set, setWeeklyStreakLength. At most one of
+// Week and WeeksAgo may be set; WeeksAgo is relative to the streak week of the latest block.
+type SetWeeklyStreak struct {
+	Player   string  `yaml:"player"`
+	Week     *uint64 `yaml:"week"`
+	WeeksAgo *uint64 `yaml:"weeks-ago"`
+	Length   *uint64 `yaml:"length"`
+}
+
+// MintGambit calls mintGambit.
+type MintGambit struct {
+	To     string `yaml:"to"`
+	Amount Wei    `yaml:"amount"`
+}
+
+// Spin spins (or respins) as self. If Value is not set, the step pays spinCost(self).
+type Spin struct {
+	Boost bool `yaml:"boost"`
+	Value *Wei `yaml:"value"`
+}
+
+// Accept accepts the outcome of a spin. If For is set, the step calls acceptFor(For) instead of
+// accept().
+type Accept struct {
+	For string `yaml:"for"`
+}
+
+// Expect lists the assertions checked after a step's action. Prize, NoPrize, PrizeAmount, and Reels
+// refer to the most recent accept in the scenario. The other assertions refer to the state of Player
+// (self by default) in the latest block.
+type Expect struct {
+	Player string `yaml:"player"`
+
+	// Index (in prizes()) of the prize awarded by the most recent accept.
+	Prize *int `yaml:"prize"`
+	// The most recent accept did not award a prize.
+	NoPrize bool `yaml:"no-prize"`
+	// Value of the Award event emitted by the most recent accept, in wei (for native prizes) or
+	// GAMBIT wei (for GAMBIT prizes).
+	PrizeAmount *Wei `yaml:"prize-amount"`
+	// Symbols the spin accepted by the most recent accept landed on.
+	Reels []uint64 `yaml:"reels"`
+
+	HasPrize           *bool   `yaml:"has-prize"`
+	DailyStreakLength  *uint64 `yaml:"daily-streak-length"`
+	WeeklyStreakLength *uint64 `yaml:"weekly-streak-length"`
+	GambitBalance      *Wei    `yaml:"gambit-balance"`
+	SpinCost           *Wei    `yaml:"spin-cost"`
+	// Native token balance of the contract.
+	Pot *Wei `yaml:"pot"`
+}
+
+// Wei is an integer amount in a scenario file. It may be written as a YAML integer or as a string,
+// in decimal or in hexadecimal with a 0x prefix, so that amounts which overflow 64 bits can be
+// expressed.
+type Wei struct {
+	*big.Int
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (w *Wei) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected an integer", node.Line)
+	}
+	value, ok := new(big.Int).SetString(strings.ReplaceAll(node.Value, "_", ""), 0)
+	if !ok || value.Sign() < 0 {
+		return fmt.Errorf("line %d: %q is not a non-negative integer", node.Line, node.Value)
+	}
+	w.Int = value
+	return nil
+}
+
+// Actions which may be written without arguments, either as a bare step ("- spin") or with an empty
+// value ("accept:").
+var argumentlessActions = map[string]bool{"spin": true, "accept": true}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Unknown fields are rejected, so that a typo in a
+// scenario does not silently drop an assertion.
+func (s *Step) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		node = &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Tag:     "!!map",
+			Line:    node.Line,
+			Content: []*yaml.Node{node, {Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}},
+		}
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: a step must be a mapping or the name of an action", node.Line)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if argumentlessActions[key.Value] && value.Tag == "!!null" {
+			node.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line}
+		}
+	}
+
+	// Re-decode the step with a strict decoder, since yaml.Node.Decode does not check for unknown
+	// fields.
+	raw, marshalErr := yaml.Marshal(node)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	type plainStep Step
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode((*plainStep)(s)); err != nil {
+		// Line numbers reported by the strict decoder are relative to the step, so they are replaced
+		// by the line on which the step starts.
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			messages := make([]string, len(typeErr.Errors))
+			for i, message := range typeErr.Errors {
+				if _, rest, found := strings.Cut(message, ": "); found && strings.HasPrefix(message, "line ") {
+					message = rest
+				}
+				messages[i] = message
+			}
+			return fmt.Errorf("line %d: %s", node.Line, strings.Join(messages, "; "))
+		}
+		return fmt.Errorf("line %d: %v", node.Line, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	return nil
+}
+
+// Action returns the name of the action the step performs, or an empty string if the step only
+// checks expectations.
+func (s Step) Action() string {
+	actions := s.actions()
+	if len(actions) == 0 {
+		return ""
+	}
+	return actions[0]
+}
+
+func (s Step) actions() []string {
+	var actions []string
+	add := func(set bool, name string) {
+		if set {
+			actions = append(actions, name)
+		}
+	}
+	add(s.SetEntropy != nil, "set-entropy")
+	add(s.SetOutcome != nil, "set-outcome")
+	add(s.SetEntropySource != nil, "set-entropy-source")
+	add(s.SetLastSpinBlock != nil, "set-last-spin-block")
+	add(s.SetLastSpinBoosted != nil, "set-last-spin-boosted")
+	add(s.SetDailyStreak != nil, "set-daily-streak")
+	add(s.SetWeeklyStreak != nil, "set-weekly-streak")
+	add(s.MintGambit != nil, "mint-gambit")
+	add(s.SetCostToSpin != nil, "set-cost-to-spin")
+	add(s.SetCostToRespin != nil, "set-cost-to-respin")
+	add(s.SetBlocksToAct != nil, "set-blocks-to-act")
+	add(s.Fund != nil, "fund")
+	add(s.Spin != nil, "spin")
+	add(s.Accept != nil, "accept")
+	return actions
+}
+
+// Title returns the name of the step if it has one, and otherwise describes it by its action.
+func (s Step) Title() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if action := s.Action(); action != "" {
+		return action
+	}
+	return "expect"
+}
+
+// Load reads a scenario from a YAML file and validates it.
+func Load(path string) (*Scenario, error) {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	return Parse(contents)
+}
+
+// Parse parses a scenario from YAML and validates it.
+func Parse(contents []byte) (*Scenario, error) {
+	var scenario Scenario
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil {
+		return nil, err
+	}
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// Validate checks that the scenario is well formed: every step performs at most one action, refers
+// only to known players, and uses valid arguments.
+func (s *Scenario) Validate() error {
+	if len(s.Steps) == 0 {
+		return ErrNoSteps
+	}
+
+	for alias, address := range s.Players {
+		if alias == Self {
+			return fmt.Errorf("players: %q is reserved for the account running the scenario", Self)
+		}
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("players: address of %s is not a valid Ethereum address: %s", alias, address)
+		}
+	}
+
+	var errs []error
+	for i, step := range s.Steps {
+		if err := s.validateStep(step); err != nil {
+			errs = append(errs, fmt.Errorf("step %d (%s): %v", i+1, step.Title(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scenario) validateStep(step Step) error {
+	actions := step.actions()
+	if len(actions) > 1 {
+		return fmt.Errorf("a step may perform only one action (got %s)", strings.Join(actions, ", "))
+	}
+	if len(actions) == 0 && step.Expect == nil {
+		return errors.New("step has neither an action nor expectations")
+	}
+	if len(actions) == 0 && step.ExpectRevert != "" {
+		return errors.New("expect-revert requires an action")
+	}
+
+	var players []string
+	switch {
+	case step.SetEntropy != nil:
+		players = append(players, step.SetEntropy.Player)
+		if step.SetEntropy.Entropy.Int == nil {
+			return errors.New("entropy not specified")
+		}
+	case step.SetOutcome != nil:
+		players = append(players, step.SetOutcome.Player)
+		if len(step.SetOutcome.Reels) != 3 {
+			return fmt.Errorf("reels must list 3 symbols (got %d)", len(step.SetOutcome.Reels))
+		}
+		for _, symbol := range step.SetOutcome.Reels {
+			if symbol >= gambit.NumSymbols {
+				return fmt.Errorf("reel symbols must be less than %d (got %d)", gambit.NumSymbols, symbol)
+			}
+		}
+	case step.SetLastSpinBlock != nil:
+		players = append(players, step.SetLastSpinBlock.Player)
+		if (step.SetLastSpinBlock.Block == nil) == (step.SetLastSpinBlock.BlocksAgo == nil) {
+			return errors.New("exactly one of block and blocks-ago must be specified")
+		}
+	case step.SetLastSpinBoosted != nil:
+		players = append(players, step.SetLastSpinBoosted.Player)
+	case step.SetDailyStreak != nil:
+		players = append(players, step.SetDailyStreak.Player)
+		if step.SetDailyStreak.Day != nil && step.SetDailyStreak.DaysAgo != nil {
+			return errors.New("only one of day and days-ago may be specified")
+		}
+	case step.SetWeeklyStreak != nil:
+		players = append(players, step.SetWeeklyStreak.Player)
+		if step.SetWeeklyStreak.Week != nil && step.SetWeeklyStreak.WeeksAgo != nil {
+			return errors.New("only one of week and weeks-ago may be specified")
+		}
+	case step.MintGambit != nil:
+		players = append(players, step.MintGambit.To)
+		if step.MintGambit.Amount.Int == nil {
+			return errors.New("amount not specified")
+		}
+	case step.Accept != nil:
+		players = append(players, step.Accept.For)
+	}
+
+	if expect := step.Expect; expect != nil {
+		players = append(players, expect.Player)
+		if expect.NoPrize && (expect.Prize != nil || expect.PrizeAmount != nil) {
+			return errors.New("no-prize cannot be combined with prize or prize-amount")
+		}
+		if expect.Prize != nil && (*expect.Prize < 0 || *expect.Prize >= gambit.NumPrizes) {
+			return fmt.Errorf("prize must be between 0 and %d (got %d)", gambit.NumPrizes-1, *expect.Prize)
+		}
+		if expect.Reels != nil && len(expect.Reels) != 3 {
+			return fmt.Errorf("reels must list 3 symbols (got %d)", len(expect.Reels))
+		}
+	}
+
+	for _, player := range players {
+		if _, err := s.Resolve(player, common.Address{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resolve returns the address of the given player: an alias defined under players, an address, or
+// self (which is also used when player is empty).
+func (s *Scenario) Resolve(player string, self common.Address) (common.Address, error) {
+	if player == "" || player == Self {
+		return self, nil
+	}
+	if address, ok := s.Players[player]; ok {
+		return common.HexToAddress(address), nil
+	}
+	if common.IsHexAddress(player) {
+		return common.HexToAddress(player), nil
+	}
+	return common.Address{}, fmt.Errorf("unknown player: %s", player)
+}
@@ -0,0 +1,345 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Flags holds the command line arguments which select a signer.
+type Flags struct {
+	Keyfile               string
+	Password              string
+	PrivateKeyFile        string
+	PrivateKeyEnv         string
+	MnemonicFile          string
+	MnemonicEnv           string
+	MnemonicPassphrase    string
+	DerivationPath        string
+	AccountIndex          uint32
+	ExternalSigner        string
+	ExternalSignerAccount string
+}
+
+// AddFlags registers the signer flags on the given flag set. Flags which are already defined on the
+// flag set (for example, --keyfile and --password on the generated transaction commands) are skipped.
+func (f *Flags) AddFlags(flags *pflag.FlagSet) {
+	addString := func(p *string, name, value, usage string) {
+		if flags.Lookup(name) == nil {
+			flags.StringVar(p, name, value, usage)
+		}
+	}
+
+	addString(&f.Keyfile, "keyfile", "", "Path to the keystore file to use for the transaction")
+	addString(&f.Password, "password", "", "Password to use to unlock the keystore (if not specified, you will be prompted for the password when the command executes)")
+	addString(&f.PrivateKeyFile, "private-key-file", "", "Path to a file containing a hex-encoded private key to sign with")
+	addString(&f.PrivateKeyEnv, "private-key-env", "", "Name of an environment variable containing a hex-encoded private key to sign with")
+	addString(&f.MnemonicFile, "mnemonic-file", "", "Path to a file containing a BIP-39 mnemonic to derive the signing account from")
+	addString(&f.MnemonicEnv, "mnemonic-env", "", "Name of an environment variable containing a BIP-39 mnemonic to derive the signing account from")
+	addString(&f.MnemonicPassphrase, "mnemonic-passphrase", "", "BIP-39 passphrase for the mnemonic (optional)")
+	addString(&f.DerivationPath, "derivation-path", DefaultDerivationPath, "Base derivation path for accounts derived from the mnemonic (the account index is appended)")
+	if flags.Lookup("account-index") == nil {
+		flags.Uint32Var(&f.AccountIndex, "account-index", 0, "Index of the account to derive from the mnemonic")
+	}
+	addString(&f.ExternalSigner, "external-signer", "", "URL or IPC path of an external signer (e.g. Clef) to sign with")
+	addString(&f.ExternalSignerAccount, "external-signer-account", "", "Account on the external signer to sign with (optional if the external signer manages a single account)")
+}
+
+// alternatives returns the names of the flags which select a signer other than a keystore file.
+func (f *Flags) alternatives() []string {
+	var selected []string
+	if f.PrivateKeyFile != "" {
+		selected = append(selected, "--private-key-file")
+	}
+	if f.PrivateKeyEnv != "" {
+		selected = append(selected, "--private-key-env")
+	}
+	if f.MnemonicFile != "" {
+		selected = append(selected, "--mnemonic-file")
+	}
+	if f.MnemonicEnv != "" {
+		selected = append(selected, "--mnemonic-env")
+	}
+	if f.ExternalSigner != "" {
+		selected = append(selected, "--external-signer")
+	}
+	return selected
+}
+
+// Validate checks that at most one signer was selected.
+func (f *Flags) Validate() error {
+	selected := f.alternatives()
+	if f.Keyfile != "" {
+		selected = append(selected, "--keyfile")
+	}
+	if len(selected) > 1 {
+		return fmt.Errorf("only one signer may be specified (got %s)", strings.Join(selected, ", "))
+	}
+	return nil
+}
+
+// Signer creates the signer selected by the flags.
+func (f *Flags) Signer() (Signer, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	derivationPath := f.DerivationPath
+	if derivationPath == "" {
+		derivationPath = DefaultDerivationPath
+	}
+
+	switch {
+	case f.Keyfile != "":
+		return FromKeystore(f.Keyfile, f.Password)
+	case f.PrivateKeyFile != "":
+		return FromHexFile(f.PrivateKeyFile)
+	case f.PrivateKeyEnv != "":
+		return FromHexEnv(f.PrivateKeyEnv)
+	case f.MnemonicFile != "":
+		return FromMnemonicFile(f.MnemonicFile, f.MnemonicPassphrase, derivationPath, f.AccountIndex)
+	case f.MnemonicEnv != "":
+		return FromMnemonicEnv(f.MnemonicEnv, f.MnemonicPassphrase, derivationPath, f.AccountIndex)
+	case f.ExternalSigner != "":
+		return FromExternal(f.ExternalSigner, f.ExternalSignerAccount)
+	}
+
+	return nil, ErrNoSigner
+}
+
+// WrapTransactionCommands adds the signer flags to every deployment and transaction command directly
+// under the given command (as generated by seer). If one of the non-keystore signers is selected, the
+// command's own --calldata mode is used to parse its arguments and the resulting transaction is signed
+// with the selected signer. Otherwise, the generated behaviour is left unchanged.
+func WrapTransactionCommands(cmd *cobra.Command) {
+	for _, subcommand := range cmd.Commands() {
+		switch subcommand.GroupID {
+		case "deploy":
+			wrapTransactionCommand(subcommand, true)
+		case "transact":
+			wrapTransactionCommand(subcommand, false)
+		}
+	}
+}
+
+func wrapTransactionCommand(cmd *cobra.Command, deploy bool) {
+	signerFlags := &Flags{}
+	signerFlags.AddFlags(cmd.Flags())
+
+	generatedPreRunE := cmd.PreRunE
+	generatedRunE := cmd.RunE
+
+	// Set when the command signs with one of the alternative signers instead of a keystore file.
+	var signing bool
+
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		calldata, _ := cmd.Flags().GetBool("calldata")
+		signing = len(signerFlags.alternatives()) > 0 && !calldata
+		if !signing {
+			if generatedPreRunE == nil {
+				return nil
+			}
+			return generatedPreRunE(cmd, args)
+		}
+
+		signerFlags.Keyfile, _ = cmd.Flags().GetString("keyfile")
+		if err := signerFlags.Validate(); err != nil {
+			return err
+		}
+
+		if safeAddress, _ := cmd.Flags().GetString("safe"); safeAddress != "" {
+			return fmt.Errorf("--safe is only supported when signing with --keyfile")
+		}
+
+		if rpc, _ := cmd.Flags().GetString("rpc"); rpc == "" {
+			return fmt.Errorf("--rpc not specified (this should be a URL to an Ethereum JSONRPC API)")
+		}
+
+		if !deploy {
+			contractAddressRaw, _ := cmd.Flags().GetString("contract")
+			if contractAddressRaw == "" {
+				return fmt.Errorf("--contract not specified")
+			} else if !common.IsHexAddress(contractAddressRaw) {
+				return fmt.Errorf("--contract is not a valid Ethereum address")
+			}
+		}
+
+		// The generated command only validates its method arguments (and skips the keystore checks)
+		// when asked for calldata.
+		if err := cmd.Flags().Set("calldata", "true"); err != nil {
+			return err
+		}
+		if generatedPreRunE == nil {
+			return nil
+		}
+		return generatedPreRunE(cmd, args)
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !signing {
+			return generatedRunE(cmd, args)
+		}
+
+		out := cmd.OutOrStdout()
+		var calldataBuffer bytes.Buffer
+		cmd.SetOut(&calldataBuffer)
+		calldataErr := generatedRunE(cmd, args)
+		cmd.SetOut(out)
+		if calldataErr != nil {
+			return calldataErr
+		}
+
+		txCalldata, decodeErr := hex.DecodeString(strings.TrimSpace(calldataBuffer.String()))
+		if decodeErr != nil {
+			return fmt.Errorf("failed to decode calldata: %v", decodeErr)
+		}
+
+		s, signerErr := signerFlags.Signer()
+		if signerErr != nil {
+			return signerErr
+		}
+
+		return SendFromCommand(cmd, s, txCalldata, deploy)
+	}
+}
+
+// SendFromCommand sends a transaction with the given calldata using the standard transaction flags
+// of the generated commands (--rpc, --contract, --nonce, --value, --gas-price, --max-fee-per-gas,
+// --max-priority-fee-per-gas, --gas-limit, --simulate, --timeout). If deploy is true, the calldata is
+// treated as contract deployment bytecode.
+func SendFromCommand(cmd *cobra.Command, s Signer, txCalldata []byte, deploy bool) error {
+	flags := cmd.Flags()
+	rpc, _ := flags.GetString("rpc")
+	contractAddressRaw, _ := flags.GetString("contract")
+	nonce, _ := flags.GetString("nonce")
+	value, _ := flags.GetString("value")
+	gasPrice, _ := flags.GetString("gas-price")
+	maxFeePerGas, _ := flags.GetString("max-fee-per-gas")
+	maxPriorityFeePerGas, _ := flags.GetString("max-priority-fee-per-gas")
+	gasLimit, _ := flags.GetUint64("gas-limit")
+	simulate, _ := flags.GetBool("simulate")
+	timeout, _ := flags.GetUint("timeout")
+
+	client, clientErr := ethclient.Dial(rpc)
+	if clientErr != nil {
+		return clientErr
+	}
+
+	chainIDCtx, cancelChainIDCtx := newChainContext(timeout)
+	defer cancelChainIDCtx()
+	chainID, chainIDErr := client.ChainID(chainIDCtx)
+	if chainIDErr != nil {
+		return chainIDErr
+	}
+
+	transactionOpts := NewTransactOpts(s, chainID)
+	if err := SetTransactionParametersFromArgs(transactionOpts, nonce, value, gasPrice, maxFeePerGas, maxPriorityFeePerGas, gasLimit, simulate); err != nil {
+		return err
+	}
+
+	var transaction *types.Transaction
+	var estimationTo *common.Address
+	if deploy {
+		address, deploymentTransaction, _, deploymentErr := bind.DeployContract(transactionOpts, abi.ABI{}, txCalldata, client)
+		if deploymentErr != nil {
+			return deploymentErr
+		}
+		transaction = deploymentTransaction
+		cmd.Printf("Transaction hash: %s\nContract address: %s\n", transaction.Hash().Hex(), address.Hex())
+	} else {
+		contractAddress := common.HexToAddress(contractAddressRaw)
+		estimationTo = &contractAddress
+		contract := bind.NewBoundContract(contractAddress, abi.ABI{}, client, client, client)
+		rawTransaction, transactErr := contract.RawTransact(transactionOpts, txCalldata)
+		if transactErr != nil {
+			return transactErr
+		}
+		transaction = rawTransaction
+		cmd.Printf("Transaction hash: %s\n", transaction.Hash().Hex())
+	}
+
+	if transactionOpts.NoSend {
+		estimationMessage := ethereum.CallMsg{
+			From:  transactionOpts.From,
+			To:    estimationTo,
+			Value: transaction.Value(),
+			Data:  transaction.Data(),
+		}
+
+		gasEstimationCtx, cancelGasEstimationCtx := newChainContext(timeout)
+		defer cancelGasEstimationCtx()
+
+		gasEstimate, gasEstimateErr := client.EstimateGas(gasEstimationCtx, estimationMessage)
+		if gasEstimateErr != nil {
+			return gasEstimateErr
+		}
+
+		transactionBinary, transactionBinaryErr := transaction.MarshalBinary()
+		if transactionBinaryErr != nil {
+			return transactionBinaryErr
+		}
+		transactionBinaryHex := hex.EncodeToString(transactionBinary)
+
+		cmd.Printf("Transaction: %s\nEstimated gas: %d\n", transactionBinaryHex, gasEstimate)
+	} else {
+		cmd.Println("Transaction submitted")
+	}
+
+	return nil
+}
+
+// This method is used to set the parameters on a transaction from command line arguments (represented mostly as
+// strings). It returns an error naming the flag if any of the numeric arguments is not a valid non-negative integer.
+func SetTransactionParametersFromArgs(opts *bind.TransactOpts, nonce, value, gasPrice, maxFeePerGas, maxPriorityFeePerGas string, gasLimit uint64, noSend bool) error {
+	parsed := []struct {
+		flag   string
+		raw    string
+		target **big.Int
+	}{
+		{"nonce", nonce, &opts.Nonce},
+		{"value", value, &opts.Value},
+		{"gas-price", gasPrice, &opts.GasPrice},
+		{"max-fee-per-gas", maxFeePerGas, &opts.GasFeeCap},
+		{"max-priority-fee-per-gas", maxPriorityFeePerGas, &opts.GasTipCap},
+	}
+	for _, argument := range parsed {
+		if argument.raw == "" {
+			continue
+		}
+		parsedValue, ok := new(big.Int).SetString(argument.raw, 0)
+		if !ok {
+			return fmt.Errorf("--%s is not a valid big integer", argument.flag)
+		}
+		if parsedValue.Sign() < 0 {
+			return fmt.Errorf("--%s must not be negative", argument.flag)
+		}
+		*argument.target = parsedValue
+	}
+	if nonce != "" && !opts.Nonce.IsUint64() {
+		return fmt.Errorf("--nonce is too large")
+	}
+
+	if gasLimit != 0 {
+		opts.GasLimit = gasLimit
+	}
+
+	opts.NoSend = noSend
+	return nil
+}
+
+func newChainContext(timeout uint) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
@@ -0,0 +1,47 @@
+package signer
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestSetTransactionParametersFromArgs(t *testing.T) {
+	opts := &bind.TransactOpts{}
+	if err := SetTransactionParametersFromArgs(opts, "7", "0x10", "", "2000000000", "1000000000", 50000, true); err != nil {
+		t.Fatalf("failed to set parameters: %v", err)
+	}
+	if opts.Nonce.Cmp(big.NewInt(7)) != 0 || opts.Value.Cmp(big.NewInt(16)) != 0 {
+		t.Fatalf("unexpected nonce %v or value %v", opts.Nonce, opts.Value)
+	}
+	if opts.GasPrice != nil {
+		t.Fatalf("expected an empty --gas-price to leave the gas price unset, got %v", opts.GasPrice)
+	}
+	if opts.GasFeeCap.Cmp(big.NewInt(2000000000)) != 0 || opts.GasTipCap.Cmp(big.NewInt(1000000000)) != 0 {
+		t.Fatalf("unexpected fee cap %v or tip cap %v", opts.GasFeeCap, opts.GasTipCap)
+	}
+	if opts.GasLimit != 50000 || !opts.NoSend {
+		t.Fatalf("unexpected gas limit %d or no send %v", opts.GasLimit, opts.NoSend)
+	}
+
+	cases := []struct {
+		flag                                                       string
+		nonce, value, gasPrice, maxFeePerGas, maxPriorityFeePerGas string
+	}{
+		{flag: "--nonce", nonce: "seven"},
+		{flag: "--nonce", nonce: "18446744073709551616"},
+		{flag: "--value", value: "1 ether"},
+		{flag: "--value", value: "-1"},
+		{flag: "--gas-price", gasPrice: "0xzz"},
+		{flag: "--max-fee-per-gas", maxFeePerGas: "1.5"},
+		{flag: "--max-priority-fee-per-gas", maxPriorityFeePerGas: "gwei"},
+	}
+	for _, c := range cases {
+		err := SetTransactionParametersFromArgs(&bind.TransactOpts{}, c.nonce, c.value, c.gasPrice, c.maxFeePerGas, c.maxPriorityFeePerGas, 0, false)
+		if err == nil || !strings.Contains(err.Error(), c.flag) {
+			t.Fatalf("expected an error about %s for %+v, got: %v", c.flag, c, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Amount of time to wait for the external signer to respond to a signature request. Clef waits for a
+// human to approve each request, so this is much longer than the timeouts used with JSONRPC APIs.
+var ExternalSignerTimeout time.Duration = 5 * time.Minute
+
+// ExternalSigner delegates signing to an external signer which implements the Clef external API
+// (account_version, account_list, account_signTransaction). The private key never leaves the external
+// signer.
+type ExternalSigner struct {
+	client   *rpc.Client
+	endpoint string
+	address  common.Address
+}
+
+type signTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// FromExternal connects to the external signer at the given endpoint, which may be an HTTP(S) or
+// WebSocket URL or the path to an IPC socket (for example, ~/.clef/clef.ipc). If account is empty, the
+// external signer must manage exactly one account, which is the one used to sign.
+func FromExternal(endpoint string, account string) (*ExternalSigner, error) {
+	client, dialErr := rpc.Dial(endpoint)
+	if dialErr != nil {
+		return nil, fmt.Errorf("failed to connect to external signer at %s: %v", endpoint, dialErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var version string
+	if err := client.CallContext(ctx, &version, "account_version"); err != nil {
+		return nil, fmt.Errorf("external signer at %s did not respond to account_version: %v", endpoint, err)
+	}
+
+	s := &ExternalSigner{client: client, endpoint: endpoint}
+
+	if account != "" {
+		if !common.IsHexAddress(account) {
+			return nil, fmt.Errorf("--external-signer-account is not a valid Ethereum address")
+		}
+		s.address = common.HexToAddress(account)
+		return s, nil
+	}
+
+	// Listing accounts may also require approval on the external signer.
+	listCtx, cancelList := context.WithTimeout(context.Background(), ExternalSignerTimeout)
+	defer cancelList()
+	var addresses []common.Address
+	if err := client.CallContext(listCtx, &addresses, "account_list"); err != nil {
+		return nil, fmt.Errorf("failed to list accounts on external signer: %v", err)
+	}
+	if len(addresses) != 1 {
+		return nil, fmt.Errorf("external signer manages %d accounts, please specify which one to use with --external-signer-account", len(addresses))
+	}
+	s.address = addresses[0]
+
+	return s, nil
+}
+
+func (s *ExternalSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx asks the external signer to sign the transaction with account_signTransaction. The returned
+// transaction is checked to be signed by the expected account for the expected chain.
+func (s *ExternalSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	var to *common.MixedcaseAddress
+	if tx.To() != nil {
+		mixedcaseTo := common.NewMixedcaseAddress(*tx.To())
+		to = &mixedcaseTo
+	}
+
+	args := &apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(s.address),
+		To:      to,
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Input:   &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("external signer does not support transactions of type %d", tx.Type())
+	}
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ExternalSignerTimeout)
+	defer cancel()
+
+	var result signTransactionResult
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("external signer at %s failed to sign transaction: %v", s.endpoint, err)
+	}
+	if result.Tx == nil {
+		return nil, fmt.Errorf("external signer at %s returned no transaction", s.endpoint)
+	}
+
+	sender, senderErr := types.Sender(types.LatestSignerForChainID(chainID), result.Tx)
+	if senderErr != nil {
+		return nil, fmt.Errorf("external signer returned an invalid signature: %v", senderErr)
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("external signer signed with %s instead of %s", sender.Hex(), s.address.Hex())
+	}
+	if result.Tx.Nonce() != tx.Nonce() || result.Tx.Value().Cmp(tx.Value()) != 0 || !bytes.Equal(result.Tx.Data(), tx.Data()) || !sameRecipient(result.Tx.To(), tx.To()) {
+		return nil, fmt.Errorf("external signer modified the transaction")
+	}
+
+	return result.Tx, nil
+}
+
+func sameRecipient(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
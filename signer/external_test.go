@@ -0,0 +1,234 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// mockExternalSigner implements the parts of the Clef external API that ExternalSigner uses.
+type mockExternalSigner struct {
+	accounts []common.Address
+	// Key used to sign transactions.
+	key *ecdsa.PrivateKey
+	// If set, applied to the requested transaction before it is signed.
+	modify func(args *apitypes.SendTxArgs)
+	// Last request received by account_signTransaction.
+	request *apitypes.SendTxArgs
+}
+
+func (m *mockExternalSigner) Version() string {
+	return "6.0.0"
+}
+
+func (m *mockExternalSigner) List() []common.Address {
+	return m.accounts
+}
+
+func (m *mockExternalSigner) SignTransaction(args apitypes.SendTxArgs) (*signTransactionResult, error) {
+	m.request = &args
+	if m.modify != nil {
+		m.modify(&args)
+	}
+	tx, txErr := args.ToTransaction()
+	if txErr != nil {
+		return nil, txErr
+	}
+	signedTx, signErr := types.SignTx(tx, types.LatestSignerForChainID(args.ChainID.ToInt()), m.key)
+	if signErr != nil {
+		return nil, signErr
+	}
+	raw, rawErr := signedTx.MarshalBinary()
+	if rawErr != nil {
+		return nil, rawErr
+	}
+	return &signTransactionResult{Raw: raw, Tx: signedTx}, nil
+}
+
+// startMockExternalSigner serves the mock over HTTP and connects an ExternalSigner to it.
+func startMockExternalSigner(t *testing.T, mock *mockExternalSigner, account string) (*ExternalSigner, error) {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", mock); err != nil {
+		t.Fatalf("failed to register mock external signer: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return FromExternal(httpServer.URL, account)
+}
+
+func newMockExternalSigner(t *testing.T) *mockExternalSigner {
+	t.Helper()
+	key, keyErr := crypto.GenerateKey()
+	if keyErr != nil {
+		t.Fatalf("failed to generate key: %v", keyErr)
+	}
+	return &mockExternalSigner{accounts: []common.Address{crypto.PubkeyToAddress(key.PublicKey)}, key: key}
+}
+
+func testTransactions() map[string]*types.Transaction {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	return map[string]*types.Transaction{
+		"legacy": types.NewTx(&types.LegacyTx{
+			Nonce:    3,
+			GasPrice: big.NewInt(1_000_000_000),
+			Gas:      100000,
+			To:       &to,
+			Value:    big.NewInt(10),
+			Data:     []byte{0x5c, 0xf9, 0x71, 0x07},
+		}),
+		"dynamic fee": types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(13746),
+			Nonce:     7,
+			GasTipCap: big.NewInt(1_000_000),
+			GasFeeCap: big.NewInt(2_000_000_000),
+			Gas:       150000,
+			To:        &to,
+			Value:     big.NewInt(0),
+			Data:      []byte{0x2d, 0x82, 0x36, 0xe8, 0x01},
+		}),
+	}
+}
+
+func TestExternalSignerSignTx(t *testing.T) {
+	chainID := big.NewInt(13746)
+
+	for name, tx := range testTransactions() {
+		t.Run(name, func(t *testing.T) {
+			mock := newMockExternalSigner(t)
+			s, connectErr := startMockExternalSigner(t, mock, "")
+			if connectErr != nil {
+				t.Fatalf("failed to connect to mock external signer: %v", connectErr)
+			}
+			if s.Address() != mock.accounts[0] {
+				t.Fatalf("expected address %s, got %s", mock.accounts[0].Hex(), s.Address().Hex())
+			}
+
+			signedTx, signErr := s.SignTx(tx, chainID)
+			if signErr != nil {
+				t.Fatalf("SignTx failed: %v", signErr)
+			}
+
+			if mock.request.From.Address() != s.Address() {
+				t.Errorf("expected request from %s, got %s", s.Address().Hex(), mock.request.From.Address().Hex())
+			}
+			if mock.request.ChainID.ToInt().Cmp(chainID) != 0 {
+				t.Errorf("expected request for chain %s, got %s", chainID.String(), mock.request.ChainID.String())
+			}
+
+			sender, senderErr := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+			if senderErr != nil {
+				t.Fatalf("failed to recover sender: %v", senderErr)
+			}
+			if sender != s.Address() {
+				t.Errorf("expected sender %s, got %s", s.Address().Hex(), sender.Hex())
+			}
+			if signedTx.Type() != tx.Type() {
+				t.Errorf("expected transaction type %d, got %d", tx.Type(), signedTx.Type())
+			}
+			if signedTx.Nonce() != tx.Nonce() || signedTx.Gas() != tx.Gas() || signedTx.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 || signedTx.GasTipCap().Cmp(tx.GasTipCap()) != 0 {
+				t.Errorf("signed transaction does not match the requested transaction")
+			}
+		})
+	}
+}
+
+func TestExternalSignerRejectsModifiedTransaction(t *testing.T) {
+	modifications := map[string]func(args *apitypes.SendTxArgs){
+		"value": func(args *apitypes.SendTxArgs) {
+			args.Value = hexutil.Big(*big.NewInt(1_000_000))
+		},
+		"nonce": func(args *apitypes.SendTxArgs) {
+			args.Nonce++
+		},
+		"data": func(args *apitypes.SendTxArgs) {
+			data := hexutil.Bytes{0xde, 0xad, 0xbe, 0xef}
+			args.Input = &data
+		},
+		"recipient": func(args *apitypes.SendTxArgs) {
+			to := common.NewMixedcaseAddress(common.HexToAddress("0x00000000000000000000000000000000000000bb"))
+			args.To = &to
+		},
+	}
+
+	for name, modify := range modifications {
+		t.Run(name, func(t *testing.T) {
+			mock := newMockExternalSigner(t)
+			mock.modify = modify
+			s, connectErr := startMockExternalSigner(t, mock, "")
+			if connectErr != nil {
+				t.Fatalf("failed to connect to mock external signer: %v", connectErr)
+			}
+
+			_, signErr := s.SignTx(testTransactions()["dynamic fee"], big.NewInt(13746))
+			if signErr == nil || !strings.Contains(signErr.Error(), "modified the transaction") {
+				t.Fatalf("expected the modified transaction to be rejected, got: %v", signErr)
+			}
+		})
+	}
+}
+
+func TestExternalSignerRejectsWrongSender(t *testing.T) {
+	mock := newMockExternalSigner(t)
+	otherKey, keyErr := crypto.GenerateKey()
+	if keyErr != nil {
+		t.Fatalf("failed to generate key: %v", keyErr)
+	}
+	// The mock lists one account but signs with another.
+	mock.key = otherKey
+
+	s, connectErr := startMockExternalSigner(t, mock, "")
+	if connectErr != nil {
+		t.Fatalf("failed to connect to mock external signer: %v", connectErr)
+	}
+
+	_, signErr := s.SignTx(testTransactions()["dynamic fee"], big.NewInt(13746))
+	if signErr == nil || !strings.Contains(signErr.Error(), "instead of "+s.Address().Hex()) {
+		t.Fatalf("expected a transaction signed by the wrong account to be rejected, got: %v", signErr)
+	}
+}
+
+func TestExternalSignerRejectsWrongChain(t *testing.T) {
+	mock := newMockExternalSigner(t)
+	mock.modify = func(args *apitypes.SendTxArgs) {
+		args.ChainID = (*hexutil.Big)(big.NewInt(1))
+	}
+
+	s, connectErr := startMockExternalSigner(t, mock, "")
+	if connectErr != nil {
+		t.Fatalf("failed to connect to mock external signer: %v", connectErr)
+	}
+
+	if _, signErr := s.SignTx(testTransactions()["dynamic fee"], big.NewInt(13746)); signErr == nil {
+		t.Fatalf("expected a transaction signed for another chain to be rejected")
+	}
+}
+
+func TestFromExternalAccountSelection(t *testing.T) {
+	mock := newMockExternalSigner(t)
+	mock.accounts = append(mock.accounts, common.HexToAddress("0x00000000000000000000000000000000000000cc"))
+
+	if _, connectErr := startMockExternalSigner(t, mock, ""); connectErr == nil || !strings.Contains(connectErr.Error(), "manages 2 accounts") {
+		t.Fatalf("expected an error for an external signer with several accounts, got: %v", connectErr)
+	}
+
+	s, connectErr := startMockExternalSigner(t, mock, mock.accounts[1].Hex())
+	if connectErr != nil {
+		t.Fatalf("failed to connect to mock external signer: %v", connectErr)
+	}
+	if s.Address() != mock.accounts[1] {
+		t.Fatalf("expected address %s, got %s", mock.accounts[1].Hex(), s.Address().Hex())
+	}
+}
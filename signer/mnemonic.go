@@ -0,0 +1,116 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultDerivationPath is the BIP-44 base path for Ethereum accounts. The account index is appended
+// to it to get the path of each account.
+var DefaultDerivationPath string = accounts.DefaultRootDerivationPath.String()
+
+var errInvalidChildKey error = errors.New("derived key is invalid for this index, please use the next index")
+
+// FromMnemonic derives the signer at the given index under basePath from a BIP-39 mnemonic. The
+// passphrase is the optional BIP-39 passphrase (sometimes called the "25th word").
+//
+// With the default base path (m/44'/60'/0'/0), index 0 is the first account that wallets like
+// MetaMask derive from the same mnemonic.
+func FromMnemonic(mnemonic, passphrase, basePath string, index uint32) (*KeySigner, error) {
+	privateKey, err := DeriveKey(mnemonic, passphrase, basePath, index)
+	if err != nil {
+		return nil, err
+	}
+	signer, signerErr := crypto.ToECDSA(privateKey)
+	if signerErr != nil {
+		return nil, signerErr
+	}
+	return NewKeySigner(signer), nil
+}
+
+// FromMnemonicFile reads a mnemonic from a file and derives a signer from it (see FromMnemonic).
+func FromMnemonicFile(path, passphrase, basePath string, index uint32) (*KeySigner, error) {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	return FromMnemonic(string(contents), passphrase, basePath, index)
+}
+
+// FromMnemonicEnv reads a mnemonic from an environment variable and derives a signer from it (see
+// FromMnemonic).
+func FromMnemonicEnv(name, passphrase, basePath string, index uint32) (*KeySigner, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return FromMnemonic(value, passphrase, basePath, index)
+}
+
+// DeriveKey returns the raw secp256k1 private key at the given index under basePath, derived from
+// the BIP-39 mnemonic using BIP-32.
+func DeriveKey(mnemonic, passphrase, basePath string, index uint32) ([]byte, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, seedErr := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if seedErr != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %v", seedErr)
+	}
+
+	path, pathErr := accounts.ParseDerivationPath(basePath)
+	if pathErr != nil {
+		return nil, pathErr
+	}
+	path = append(path, index)
+
+	// BIP-32 master key generation.
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	curveOrder := crypto.S256().Params().N
+	for _, childIndex := range path {
+		var data []byte
+		if childIndex >= 0x80000000 {
+			// Hardened child: 0x00 || ser256(k) || ser32(i)
+			data = append([]byte{0}, key...)
+		} else {
+			// Normal child: serP(point(k)) || ser32(i)
+			privateKey, err := crypto.ToECDSA(key)
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&privateKey.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, childIndex)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curveOrder) >= 0 {
+			return nil, errInvalidChildKey
+		}
+		child := tweak.Add(tweak, new(big.Int).SetBytes(key))
+		child.Mod(child, curveOrder)
+		if child.Sign() == 0 {
+			return nil, errInvalidChildKey
+		}
+
+		key = child.FillBytes(make([]byte, 32))
+		chainCode = sum[32:]
+	}
+
+	return key, nil
+}
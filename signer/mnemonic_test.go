@@ -0,0 +1,69 @@
+package signer
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The mnemonic that Hardhat and Anvil derive their default development accounts from.
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestFromMnemonic(t *testing.T) {
+	expected := []common.Address{
+		common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"),
+		common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"),
+	}
+
+	for index, address := range expected {
+		s, err := FromMnemonic(testMnemonic, "", DefaultDerivationPath, uint32(index))
+		if err != nil {
+			t.Fatalf("failed to derive signer %d: %v", index, err)
+		}
+		if s.Address() != address {
+			t.Fatalf("expected account %d to be %s, got %s", index, address.Hex(), s.Address().Hex())
+		}
+
+		key, keyErr := DeriveKey(testMnemonic, "", DefaultDerivationPath, uint32(index))
+		if keyErr != nil {
+			t.Fatalf("failed to derive key %d: %v", index, keyErr)
+		}
+		privateKey, privateKeyErr := crypto.ToECDSA(key)
+		if privateKeyErr != nil {
+			t.Fatalf("derived key %d is invalid: %v", index, privateKeyErr)
+		}
+		if crypto.PubkeyToAddress(privateKey.PublicKey) != address {
+			t.Fatalf("expected key %d to belong to %s", index, address.Hex())
+		}
+	}
+}
+
+func TestFromMnemonicNormalizesWhitespace(t *testing.T) {
+	s, err := FromMnemonic("  test test test test test test\ntest test test test test   junk\n", "", DefaultDerivationPath, 0)
+	if err != nil {
+		t.Fatalf("failed to derive signer: %v", err)
+	}
+	if s.Address() != common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266") {
+		t.Fatalf("unexpected address %s", s.Address().Hex())
+	}
+}
+
+func TestFromMnemonicRejectsInvalidInput(t *testing.T) {
+	if _, err := FromMnemonic("test test test test test test test test test test test test", "", DefaultDerivationPath, 0); err == nil {
+		t.Fatalf("expected a mnemonic with a bad checksum to be rejected")
+	}
+	if _, err := FromMnemonic(testMnemonic, "", "not a path", 0); err == nil {
+		t.Fatalf("expected an invalid derivation path to be rejected")
+	}
+
+	// A passphrase derives different accounts from the same mnemonic.
+	s, err := FromMnemonic(testMnemonic, "passphrase", DefaultDerivationPath, 0)
+	if err != nil {
+		t.Fatalf("failed to derive signer: %v", err)
+	}
+	if s.Address() == common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266") {
+		t.Fatalf("expected the passphrase to change the derived account")
+	}
+}
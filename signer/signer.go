@@ -0,0 +1,152 @@
+// Package signer provides the accounts that the Degen Casino command line tools sign transactions
+// with. Besides the geth keystore files that the generated bindings support, transactions can be
+// signed with raw private keys (read from a file or an environment variable), with accounts derived
+// from BIP-39 mnemonics, and by external signers like Clef which keep the private key on another
+// machine.
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/term"
+)
+
+var ErrNoSigner error = errors.New("no signer specified -- please pass one of --keyfile, --private-key-file, --private-key-env, --mnemonic-file, --mnemonic-env, or --external-signer")
+
+// Signer is an account which can sign transactions.
+type Signer interface {
+	// Address returns the address of the account that the signer signs for.
+	Address() common.Address
+	// SignTx signs the given transaction for the chain with the given ID.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// HashSigner is a signer which can also sign arbitrary 32-byte hashes, as Safe owners do to approve
+// Safe transactions. Signers which hold the private key implement it; external signers do not.
+type HashSigner interface {
+	Signer
+	// SignHash signs the given hash and returns the signature as r || s || v, with v = 27 or 28.
+	SignHash(hash common.Hash) ([]byte, error)
+}
+
+// NewTransactOpts creates transaction options which sign using the given signer.
+func NewTransactOpts(s Signer, chainID *big.Int) *bind.TransactOpts {
+	from := s.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(tx, chainID)
+		},
+		Context: context.Background(),
+	}
+}
+
+// KeySigner signs with a private key held in memory.
+type KeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+// NewKeySigner creates a signer from a private key.
+func NewKeySigner(privateKey *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *KeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.privateKey)
+}
+
+func (s *KeySigner) SignHash(hash common.Hash) ([]byte, error) {
+	signature, signErr := crypto.Sign(hash.Bytes(), s.privateKey)
+	if signErr != nil {
+		return nil, signErr
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// PrivateKey returns the private key that the signer signs with.
+func (s *KeySigner) PrivateKey() *ecdsa.PrivateKey {
+	return s.privateKey
+}
+
+// Key returns the signer's private key in the form used by the generated bindings (for example, to
+// create Safe proposals).
+func (s *KeySigner) Key() *keystore.Key {
+	return &keystore.Key{Address: s.address, PrivateKey: s.privateKey}
+}
+
+// FromKeystore loads a signer from a geth keystore file, prompting the user for the password if it is
+// not provided as a function argument.
+func FromKeystore(keystoreFile string, password string) (*KeySigner, error) {
+	keystoreContent, readErr := os.ReadFile(keystoreFile)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	// If password is "", prompt user for password.
+	if password == "" {
+		fmt.Printf("Please provide a password for keystore (%s): ", keystoreFile)
+		passwordRaw, inputErr := term.ReadPassword(int(os.Stdin.Fd()))
+		if inputErr != nil {
+			return nil, fmt.Errorf("error reading password: %s", inputErr.Error())
+		}
+		fmt.Print("\n")
+		password = string(passwordRaw)
+	}
+
+	key, err := keystore.DecryptKey(keystoreContent, password)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(key.PrivateKey), nil
+}
+
+// FromHex creates a signer from a hex-encoded private key (with or without the 0x prefix).
+func FromHex(privateKeyHex string) (*KeySigner, error) {
+	privateKeyHex = strings.TrimPrefix(strings.TrimSpace(privateKeyHex), "0x")
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return NewKeySigner(privateKey), nil
+}
+
+// FromHexFile creates a signer from a file containing a hex-encoded private key.
+func FromHexFile(path string) (*KeySigner, error) {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	return FromHex(string(contents))
+}
+
+// FromHexEnv creates a signer from an environment variable containing a hex-encoded private key.
+func FromHexEnv(name string) (*KeySigner, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return FromHex(value)
+}
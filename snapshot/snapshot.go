@@ -0,0 +1,275 @@
+// Package snapshot saves the state of a DevDegenGambit deployment to JSON and restores it with the
+// contract's dev setters, so that a shared test deployment can be reset to a known state.
+//
+// A snapshot holds the contract's configuration (BlocksToAct, CostToSpin, CostToRespin, and
+// EntropyIsHash) and, for a list of players, their spin state (LastSpinBlock, LastSpinBoosted,
+// EntropyForPlayer), streak state, and GAMBIT balance. The native token balance of the contract (the
+// pot) and the records of past winners are not part of a snapshot, because no dev setter controls
+// them.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/PermissionlessGames/degen-casino/bindings/DevDegenGambit"
+	"github.com/PermissionlessGames/degen-casino/txmanager"
+)
+
+// Config is the global configuration of a DevDegenGambit contract.
+type Config struct {
+	BlocksToAct   *big.Int `json:"blocksToAct"`
+	CostToSpin    *big.Int `json:"costToSpin"`
+	CostToRespin  *big.Int `json:"costToRespin"`
+	EntropyIsHash bool     `json:"entropyIsHash"`
+}
+
+// PlayerState is the state a DevDegenGambit contract stores for a player.
+type PlayerState struct {
+	Player                    common.Address `json:"player"`
+	LastSpinBlock             *big.Int       `json:"lastSpinBlock"`
+	LastSpinBoosted           bool           `json:"lastSpinBoosted"`
+	EntropyForPlayer          *big.Int       `json:"entropyForPlayer"`
+	LastStreakDay             *big.Int       `json:"lastStreakDay"`
+	CurrentDailyStreakLength  *big.Int       `json:"currentDailyStreakLength"`
+	LastStreakWeek            *big.Int       `json:"lastStreakWeek"`
+	CurrentWeeklyStreakLength *big.Int       `json:"currentWeeklyStreakLength"`
+	GambitBalance             *big.Int       `json:"gambitBalance"`
+}
+
+// Snapshot is the state of a DevDegenGambit contract at a block.
+type Snapshot struct {
+	Contract    common.Address `json:"contract"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Config      Config         `json:"config"`
+	Players     []PlayerState  `json:"players"`
+}
+
+// Take reads the configuration of the contract, and the state of the given players, from the block
+// selected by opts. Callers should set opts.BlockNumber so that every value is read from the same
+// block; it is recorded as the snapshot's BlockNumber.
+func Take(opts *bind.CallOpts, contractAddress common.Address, contract *DevDegenGambit.DevDegenGambit, players []common.Address) (*Snapshot, error) {
+	config, configErr := ReadConfig(opts, contract)
+	if configErr != nil {
+		return nil, configErr
+	}
+
+	snapshot := &Snapshot{Contract: contractAddress, Config: config}
+	if opts.BlockNumber != nil {
+		snapshot.BlockNumber = opts.BlockNumber.Uint64()
+	}
+
+	for _, player := range players {
+		state, stateErr := ReadPlayerState(opts, contract, player)
+		if stateErr != nil {
+			return nil, stateErr
+		}
+		snapshot.Players = append(snapshot.Players, state)
+	}
+
+	return snapshot, nil
+}
+
+// ReadConfig reads the global configuration of the contract.
+func ReadConfig(opts *bind.CallOpts, contract *DevDegenGambit.DevDegenGambit) (Config, error) {
+	var config Config
+	var err error
+
+	if config.BlocksToAct, err = contract.BlocksToAct(opts); err != nil {
+		return config, fmt.Errorf("failed to get BlocksToAct: %v", err)
+	}
+	if config.CostToSpin, err = contract.CostToSpin(opts); err != nil {
+		return config, fmt.Errorf("failed to get CostToSpin: %v", err)
+	}
+	if config.CostToRespin, err = contract.CostToRespin(opts); err != nil {
+		return config, fmt.Errorf("failed to get CostToRespin: %v", err)
+	}
+	if config.EntropyIsHash, err = contract.EntropyIsHash(opts); err != nil {
+		return config, fmt.Errorf("failed to get EntropyIsHash: %v", err)
+	}
+
+	return config, nil
+}
+
+// ReadPlayerState reads the state the contract stores for the given player.
+func ReadPlayerState(opts *bind.CallOpts, contract *DevDegenGambit.DevDegenGambit, player common.Address) (PlayerState, error) {
+	state := PlayerState{Player: player}
+	var err error
+
+	if state.LastSpinBlock, err = contract.LastSpinBlock(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get LastSpinBlock for %s: %v", player.Hex(), err)
+	}
+	if state.LastSpinBoosted, err = contract.LastSpinBoosted(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get LastSpinBoosted for %s: %v", player.Hex(), err)
+	}
+	if state.EntropyForPlayer, err = contract.EntropyForPlayer(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get EntropyForPlayer for %s: %v", player.Hex(), err)
+	}
+	if state.LastStreakDay, err = contract.LastStreakDay(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get LastStreakDay for %s: %v", player.Hex(), err)
+	}
+	if state.CurrentDailyStreakLength, err = contract.CurrentDailyStreakLength(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get CurrentDailyStreakLength for %s: %v", player.Hex(), err)
+	}
+	if state.LastStreakWeek, err = contract.LastStreakWeek(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get LastStreakWeek for %s: %v", player.Hex(), err)
+	}
+	if state.CurrentWeeklyStreakLength, err = contract.CurrentWeeklyStreakLength(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get CurrentWeeklyStreakLength for %s: %v", player.Hex(), err)
+	}
+	if state.GambitBalance, err = contract.BalanceOf(opts, player); err != nil {
+		return state, fmt.Errorf("failed to get GAMBIT balance of %s: %v", player.Hex(), err)
+	}
+
+	return state, nil
+}
+
+// Write writes the snapshot to w as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Load reads a snapshot from a JSON file and checks that it is complete.
+func Load(path string) (*Snapshot, error) {
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(contents, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot from %s: %v", path, err)
+	}
+	if err := snapshot.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid snapshot in %s: %v", path, err)
+	}
+	return &snapshot, nil
+}
+
+// Validate checks that every value of the snapshot is present.
+func (s *Snapshot) Validate() error {
+	config := s.Config
+	if config.BlocksToAct == nil || config.CostToSpin == nil || config.CostToRespin == nil {
+		return fmt.Errorf("config is incomplete")
+	}
+	for _, state := range s.Players {
+		values := []*big.Int{state.LastSpinBlock, state.EntropyForPlayer, state.LastStreakDay, state.CurrentDailyStreakLength, state.LastStreakWeek, state.CurrentWeeklyStreakLength, state.GambitBalance}
+		for _, value := range values {
+			if value == nil {
+				return fmt.Errorf("state of player %s is incomplete", state.Player.Hex())
+			}
+		}
+	}
+	return nil
+}
+
+// Change is a dev setter call which moves the contract towards the state in a snapshot.
+type Change struct {
+	Description string
+	Transact    txmanager.TransactFunc
+}
+
+// Plan compares the snapshot with the current state of the contract (read with opts) and returns the
+// dev setter calls which restore the snapshot. Values which already match the snapshot are left alone.
+//
+// GAMBIT balances are restored by minting the difference. A balance which is higher than in the
+// snapshot cannot be lowered by the dev setters, so it is reported in the returned warnings instead.
+func Plan(opts *bind.CallOpts, contract *DevDegenGambit.DevDegenGambit, snapshot *Snapshot) ([]Change, []string, error) {
+	var changes []Change
+	var warnings []string
+	add := func(description string, transact txmanager.TransactFunc) {
+		changes = append(changes, Change{Description: description, Transact: transact})
+	}
+
+	current, configErr := ReadConfig(opts, contract)
+	if configErr != nil {
+		return nil, nil, configErr
+	}
+	target := snapshot.Config
+
+	if current.BlocksToAct.Cmp(target.BlocksToAct) != 0 {
+		add(fmt.Sprintf("setBlocksToAct(%s)", target.BlocksToAct.String()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.SetBlocksToAct(opts, target.BlocksToAct)
+		})
+	}
+	if current.CostToSpin.Cmp(target.CostToSpin) != 0 {
+		add(fmt.Sprintf("setCostToSpin(%s)", target.CostToSpin.String()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.SetCostToSpin(opts, target.CostToSpin)
+		})
+	}
+	if current.CostToRespin.Cmp(target.CostToRespin) != 0 {
+		add(fmt.Sprintf("setCostToRespin(%s)", target.CostToRespin.String()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.SetCostToRespin(opts, target.CostToRespin)
+		})
+	}
+	if current.EntropyIsHash != target.EntropyIsHash {
+		add(fmt.Sprintf("setEntropySource(%t)", target.EntropyIsHash), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.SetEntropySource(opts, target.EntropyIsHash)
+		})
+	}
+
+	for _, state := range snapshot.Players {
+		player := state.Player
+		now, stateErr := ReadPlayerState(opts, contract, player)
+		if stateErr != nil {
+			return nil, nil, stateErr
+		}
+
+		if now.LastSpinBlock.Cmp(state.LastSpinBlock) != 0 {
+			add(fmt.Sprintf("setLastSpinBlock(%s, %s)", player.Hex(), state.LastSpinBlock.String()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetLastSpinBlock(opts, player, state.LastSpinBlock)
+			})
+		}
+		if now.LastSpinBoosted != state.LastSpinBoosted {
+			add(fmt.Sprintf("setLastSpinBoosted(%s, %t)", player.Hex(), state.LastSpinBoosted), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetLastSpinBoosted(opts, player, state.LastSpinBoosted)
+			})
+		}
+		if now.EntropyForPlayer.Cmp(state.EntropyForPlayer) != 0 {
+			add(fmt.Sprintf("setEntropy(%s, %s)", player.Hex(), state.EntropyForPlayer.String()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetEntropy(opts, player, state.EntropyForPlayer)
+			})
+		}
+		if now.LastStreakDay.Cmp(state.LastStreakDay) != 0 {
+			add(fmt.Sprintf("setDailyStreak(%s, %s)", state.LastStreakDay.String(), player.Hex()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetDailyStreak(opts, state.LastStreakDay, player)
+			})
+		}
+		if now.CurrentDailyStreakLength.Cmp(state.CurrentDailyStreakLength) != 0 {
+			add(fmt.Sprintf("setDailyStreakLength(%s, %s)", state.CurrentDailyStreakLength.String(), player.Hex()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetDailyStreakLength(opts, state.CurrentDailyStreakLength, player)
+			})
+		}
+		if now.LastStreakWeek.Cmp(state.LastStreakWeek) != 0 {
+			add(fmt.Sprintf("setWeeklyStreak(%s, %s)", state.LastStreakWeek.String(), player.Hex()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetWeeklyStreak(opts, state.LastStreakWeek, player)
+			})
+		}
+		if now.CurrentWeeklyStreakLength.Cmp(state.CurrentWeeklyStreakLength) != 0 {
+			add(fmt.Sprintf("setWeeklyStreakLength(%s, %s)", state.CurrentWeeklyStreakLength.String(), player.Hex()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.SetWeeklyStreakLength(opts, state.CurrentWeeklyStreakLength, player)
+			})
+		}
+
+		switch now.GambitBalance.Cmp(state.GambitBalance) {
+		case -1:
+			shortfall := new(big.Int).Sub(state.GambitBalance, now.GambitBalance)
+			add(fmt.Sprintf("mintGambit(%s, %s)", player.Hex(), shortfall.String()), func(opts *bind.TransactOpts) (*types.Transaction, error) {
+				return contract.MintGambit(opts, player, shortfall)
+			})
+		case 1:
+			warnings = append(warnings, fmt.Sprintf("GAMBIT balance of %s is %s, higher than %s in the snapshot; it cannot be lowered with the dev setters", player.Hex(), now.GambitBalance.String(), state.GambitBalance.String()))
+		}
+	}
+
+	return changes, warnings, nil
+}
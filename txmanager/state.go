@@ -0,0 +1,92 @@
+package txmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/PermissionlessGames/degen-casino/atomicfile"
+)
+
+// State is the part of the manager which is persisted to disk.
+type State struct {
+	Address   common.Address       `json:"address"`
+	ChainID   string               `json:"chainId"`
+	NextNonce uint64               `json:"nextNonce"`
+	Pending   []PendingTransaction `json:"pending"`
+}
+
+// PendingTransaction is a transaction which the manager submitted and has not yet seen mined.
+type PendingTransaction struct {
+	Nonce           uint64        `json:"nonce"`
+	Hash            common.Hash   `json:"hash"`
+	Raw             hexutil.Bytes `json:"raw"`
+	SubmittedAt     time.Time     `json:"submittedAt"`
+	LastBroadcastAt time.Time     `json:"lastBroadcastAt"`
+	Bumps           int           `json:"bumps"`
+	// Hashes of the earlier versions of this transaction which were replaced by fee bumps.
+	Replaced []common.Hash `json:"replaced,omitempty"`
+}
+
+// NewPendingTransaction records a signed transaction that was just broadcast.
+func NewPendingTransaction(tx *types.Transaction) (PendingTransaction, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return PendingTransaction{}, fmt.Errorf("failed to encode transaction %s: %v", tx.Hash().Hex(), err)
+	}
+	now := time.Now()
+	return PendingTransaction{
+		Nonce:           tx.Nonce(),
+		Hash:            tx.Hash(),
+		Raw:             raw,
+		SubmittedAt:     now,
+		LastBroadcastAt: now,
+	}, nil
+}
+
+// Transaction decodes the signed transaction.
+func (p PendingTransaction) Transaction() (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(p.Raw); err != nil {
+		return nil, fmt.Errorf("failed to decode pending transaction %s: %v", p.Hash.Hex(), err)
+	}
+	return tx, nil
+}
+
+// LoadState reads manager state from the given file. It returns a nil state (and no error) if the
+// path is empty or the file does not exist.
+func LoadState(path string) (*State, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	contents, readErr := os.ReadFile(path)
+	if errors.Is(readErr, os.ErrNotExist) {
+		return nil, nil
+	} else if readErr != nil {
+		return nil, readErr
+	}
+
+	var state State
+	if err := json.Unmarshal(contents, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction manager state from %s: %v", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state to the given file. The file is replaced atomically so that a crash while
+// saving does not corrupt the previous state.
+func (s *State) Save(path string) error {
+	contents, marshalErr := json.MarshalIndent(s, "", "  ")
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	return atomicfile.WriteFile(path, contents)
+}
@@ -0,0 +1,454 @@
+// Package txmanager manages the transactions submitted by a single account which sends transactions
+// at a high frequency (for example, automated players of Degen's Gambit).
+//
+// The manager tracks nonces locally instead of asking the node for the pending nonce of the account
+// on every transaction, queues transactions so that they are signed and submitted in order, and
+// rebroadcasts transactions which get stuck in the mempool with bumped fees (replacing them by nonce).
+// Its state is persisted to disk so that a bot which restarts does not reuse nonces of transactions
+// that it already submitted.
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// Default amount of time after which an unmined transaction is considered stuck.
+	DefaultStuckAfter = 30 * time.Second
+
+	// Default percentage by which fees are bumped when replacing a stuck transaction. Nodes running
+	// the go-ethereum transaction pool reject replacements with a bump smaller than 10%.
+	DefaultBumpPercent = 12
+
+	// Default maximum number of times a single transaction will be replaced.
+	DefaultMaxBumps = 5
+
+	// Default interval at which the manager checks on its pending transactions.
+	DefaultPollInterval = 5 * time.Second
+
+	// Number of transactions which can be waiting in the queue before Enqueue blocks.
+	QueueSize = 256
+
+	// Amount of time the manager waits for the node's pending nonce after a failed send.
+	SyncTimeout = 10 * time.Second
+)
+
+// ErrStopped is the error delivered for queued transactions which were not submitted because Run
+// returned.
+var ErrStopped error = errors.New("transaction manager stopped")
+
+// Backend is the chain interface the manager needs. *ethclient.Client satisfies it.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// TransactFunc submits a single transaction using the given transaction options. Methods on the
+// generated transactors (e.g. DegenGambitTransactor.Spin) can be wrapped in a TransactFunc.
+type TransactFunc func(opts *bind.TransactOpts) (*types.Transaction, error)
+
+// Result is the outcome of a queued transaction.
+type Result struct {
+	Transaction *types.Transaction
+	Err         error
+}
+
+type queuedTransaction struct {
+	transact TransactFunc
+	result   chan Result
+}
+
+// Manager submits transactions from a single account, assigning nonces locally.
+type Manager struct {
+	// Amount of time after which an unmined transaction is replaced with a fee-bumped copy.
+	StuckAfter time.Duration
+	// Percentage by which fees are bumped on replacement.
+	BumpPercent int64
+	// Maximum number of times a single transaction is replaced.
+	MaxBumps int
+	// Interval at which Run checks on pending transactions.
+	PollInterval time.Duration
+	// Destination for messages about transient errors, which the manager retries instead of
+	// returning. Defaults to os.Stderr.
+	Log io.Writer
+
+	mu        sync.Mutex
+	backend   Backend
+	opts      bind.TransactOpts
+	chainID   *big.Int
+	statePath string
+	state     State
+	queue     chan queuedTransaction
+	stopped   chan struct{}
+	stopOnce  sync.Once
+}
+
+// New creates a transaction manager for the account which signs with the given transaction options.
+// The From, Signer, and (optionally) fee and gas limit fields of opts are used as a template for every
+// transaction the manager submits. The Nonce field is ignored.
+//
+// If statePath is non-empty, the manager state is loaded from (and persisted to) that file. Pending
+// transactions recorded in the state file are rebroadcast. A transaction which cannot be rebroadcast
+// does not prevent the manager from starting: the error is logged and the transaction is replaced by
+// the next call to Rebroadcast.
+func New(ctx context.Context, backend Backend, opts *bind.TransactOpts, chainID *big.Int, statePath string) (*Manager, error) {
+	if opts == nil || opts.Signer == nil {
+		return nil, errors.New("transaction options must have a signer")
+	}
+
+	m := &Manager{
+		StuckAfter:   DefaultStuckAfter,
+		BumpPercent:  DefaultBumpPercent,
+		MaxBumps:     DefaultMaxBumps,
+		PollInterval: DefaultPollInterval,
+		Log:          os.Stderr,
+		backend:      backend,
+		opts:         *opts,
+		chainID:      chainID,
+		statePath:    statePath,
+		queue:        make(chan queuedTransaction, QueueSize),
+		stopped:      make(chan struct{}),
+	}
+	m.opts.Nonce = nil
+
+	state, stateErr := LoadState(statePath)
+	if stateErr != nil {
+		return nil, stateErr
+	}
+	if state == nil {
+		state = &State{Address: opts.From, ChainID: chainID.String()}
+	} else if state.Address != opts.From {
+		return nil, fmt.Errorf("state file %s belongs to %s, not %s", statePath, state.Address.Hex(), opts.From.Hex())
+	} else if state.ChainID != chainID.String() {
+		return nil, fmt.Errorf("state file %s was created for chain %s, not %s", statePath, state.ChainID, chainID.String())
+	}
+	m.state = *state
+
+	if err := m.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	// Transactions recorded as pending may have been dropped by the node while the bot was offline.
+	for i, pending := range m.state.Pending {
+		tx, txErr := pending.Transaction()
+		if txErr != nil {
+			return nil, txErr
+		}
+		if sendErr := backend.SendTransaction(ctx, tx); sendErr != nil && !isKnownTransactionError(sendErr) {
+			m.logf("Failed to rebroadcast transaction %s (nonce %d), it will be replaced: %v", pending.Hash.Hex(), pending.Nonce, sendErr)
+			// Mark the transaction as stuck so that the next Rebroadcast replaces it.
+			m.state.Pending[i].LastBroadcastAt = time.Time{}
+		}
+	}
+
+	return m, m.save()
+}
+
+// Address returns the account the manager submits transactions from.
+func (m *Manager) Address() common.Address {
+	return m.opts.From
+}
+
+// NextNonce returns the nonce that the manager will use for the next transaction.
+func (m *Manager) NextNonce() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state.NextNonce
+}
+
+// Pending returns a copy of the transactions which the manager has submitted but not yet seen mined.
+func (m *Manager) Pending() []PendingTransaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := make([]PendingTransaction, len(m.state.Pending))
+	copy(pending, m.state.Pending)
+	return pending
+}
+
+// TransactOpts returns a copy of the manager's template transaction options, bound to the given
+// nonce and context. It is exposed for callers who need to build transactions manually.
+func (m *Manager) TransactOpts(ctx context.Context, nonce uint64) *bind.TransactOpts {
+	opts := m.opts
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.Context = ctx
+	if m.opts.Value != nil {
+		opts.Value = new(big.Int).Set(m.opts.Value)
+	}
+	return &opts
+}
+
+// Transact submits a transaction using the next local nonce. The nonce is only consumed if the
+// transaction is accepted by the node. If submitting fails, the local nonce is resynchronized with the
+// node's pending nonce, in case the node accepted the transaction anyway.
+func (m *Manager) Transact(ctx context.Context, transact TransactFunc) (*types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	opts := m.TransactOpts(ctx, m.state.NextNonce)
+	tx, err := transact(opts)
+	if err != nil {
+		// If the nonce is too low, somebody else is using this account. Any other error is ambiguous: a
+		// send which timed out may still have reached the node. Either way, resynchronize with the
+		// node's pending nonce before the nonce is reused. The original context may be what expired, so
+		// the resync gets its own.
+		syncCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), SyncTimeout)
+		defer cancel()
+		if syncErr := m.sync(syncCtx); syncErr != nil {
+			return nil, fmt.Errorf("%v (resync failed: %v)", err, syncErr)
+		}
+		if saveErr := m.save(); saveErr != nil {
+			return nil, fmt.Errorf("%v (failed to save state: %v)", err, saveErr)
+		}
+		return nil, err
+	}
+
+	if opts.NoSend {
+		return tx, nil
+	}
+
+	pending, pendingErr := NewPendingTransaction(tx)
+	if pendingErr != nil {
+		return tx, pendingErr
+	}
+	m.state.Pending = append(m.state.Pending, pending)
+	m.state.NextNonce = tx.Nonce() + 1
+
+	return tx, m.save()
+}
+
+// Enqueue adds a transaction to the manager's queue. Queued transactions are submitted in the order
+// in which they were enqueued by Run, which must be running for the returned channel to receive a
+// result before the transaction is submitted. Transactions which are still queued when Run returns, or
+// which are enqueued after it has returned, receive a result with ErrStopped. Enqueue blocks if the
+// queue is full.
+func (m *Manager) Enqueue(transact TransactFunc) <-chan Result {
+	result := make(chan Result, 1)
+	select {
+	case m.queue <- queuedTransaction{transact: transact, result: result}:
+	case <-m.stopped:
+		result <- Result{Err: ErrStopped}
+		return result
+	}
+
+	// Run may have returned while the transaction was being queued.
+	select {
+	case <-m.stopped:
+		m.drain()
+	default:
+	}
+	return result
+}
+
+// Run submits queued transactions in order and periodically rebroadcasts stuck transactions until
+// the context is cancelled. Errors while rebroadcasting are logged and retried on the next poll.
+// Run must not be called more than once.
+func (m *Manager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.PollInterval)
+	defer ticker.Stop()
+
+	defer func() {
+		m.stopOnce.Do(func() { close(m.stopped) })
+		m.drain()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case queued := <-m.queue:
+			tx, err := m.Transact(ctx, queued.transact)
+			queued.result <- Result{Transaction: tx, Err: err}
+		case <-ticker.C:
+			if err := m.Rebroadcast(ctx); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				m.logf("Failed to rebroadcast pending transactions: %v", err)
+			}
+		}
+	}
+}
+
+// drain delivers ErrStopped to every transaction left in the queue.
+func (m *Manager) drain() {
+	for {
+		select {
+		case queued := <-m.queue:
+			queued.result <- Result{Err: ErrStopped}
+		default:
+			return
+		}
+	}
+}
+
+// Rebroadcast forgets pending transactions which have been mined and replaces transactions which have
+// been pending for longer than StuckAfter with copies that pay higher fees. A transaction which cannot
+// be replaced is logged and kept as it is, and does not prevent the others from being replaced.
+func (m *Manager) Rebroadcast(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	minedNonce, nonceErr := m.backend.NonceAt(ctx, m.opts.From, nil)
+	if nonceErr != nil {
+		return fmt.Errorf("failed to get nonce for %s: %v", m.opts.From.Hex(), nonceErr)
+	}
+
+	stillPending := make([]PendingTransaction, 0, len(m.state.Pending))
+	for _, pending := range m.state.Pending {
+		if pending.Nonce < minedNonce {
+			continue
+		}
+
+		if time.Since(pending.LastBroadcastAt) >= m.StuckAfter && pending.Bumps < m.MaxBumps {
+			replaced, replaceErr := m.replace(ctx, pending)
+			if replaceErr != nil {
+				// Keep the original transaction, it is retried on the next call.
+				m.logf("Failed to replace transaction %s (nonce %d): %v", pending.Hash.Hex(), pending.Nonce, replaceErr)
+			} else {
+				pending = replaced
+			}
+		}
+
+		stillPending = append(stillPending, pending)
+	}
+	m.state.Pending = stillPending
+
+	if minedNonce > m.state.NextNonce {
+		m.state.NextNonce = minedNonce
+	}
+
+	return m.save()
+}
+
+// replace signs and broadcasts a copy of the pending transaction with bumped fees.
+func (m *Manager) replace(ctx context.Context, pending PendingTransaction) (PendingTransaction, error) {
+	tx, txErr := pending.Transaction()
+	if txErr != nil {
+		return pending, txErr
+	}
+
+	var replacement types.TxData
+	switch tx.Type() {
+	case types.LegacyTxType:
+		gasPrice, gasPriceErr := m.backend.SuggestGasPrice(ctx)
+		if gasPriceErr != nil {
+			return pending, gasPriceErr
+		}
+		replacement = &types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: maxBig(bump(tx.GasPrice(), m.BumpPercent), gasPrice),
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	case types.DynamicFeeTxType:
+		gasTipCap, gasTipCapErr := m.backend.SuggestGasTipCap(ctx)
+		if gasTipCapErr != nil {
+			return pending, gasTipCapErr
+		}
+		tipCap := maxBig(bump(tx.GasTipCap(), m.BumpPercent), gasTipCap)
+		feeCap := bump(tx.GasFeeCap(), m.BumpPercent)
+		if feeCap.Cmp(tipCap) < 0 {
+			feeCap = new(big.Int).Set(tipCap)
+		}
+		replacement = &types.DynamicFeeTx{
+			ChainID:    m.chainID,
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tipCap,
+			GasFeeCap:  feeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	default:
+		return pending, fmt.Errorf("cannot replace transaction %s of type %d", tx.Hash().Hex(), tx.Type())
+	}
+
+	signedTx, signErr := m.opts.Signer(m.opts.From, types.NewTx(replacement))
+	if signErr != nil {
+		return pending, fmt.Errorf("failed to sign replacement for transaction %s: %v", tx.Hash().Hex(), signErr)
+	}
+
+	if sendErr := m.backend.SendTransaction(ctx, signedTx); sendErr != nil {
+		if isNonceError(sendErr) {
+			// The original transaction was mined in the meantime.
+			return pending, nil
+		}
+		return pending, fmt.Errorf("failed to send replacement for transaction %s: %v", tx.Hash().Hex(), sendErr)
+	}
+
+	replaced, replacedErr := NewPendingTransaction(signedTx)
+	if replacedErr != nil {
+		return pending, replacedErr
+	}
+	replaced.SubmittedAt = pending.SubmittedAt
+	replaced.Bumps = pending.Bumps + 1
+	replaced.Replaced = append(append([]common.Hash{}, pending.Replaced...), pending.Hash)
+
+	return replaced, nil
+}
+
+// sync makes sure that the local nonce is not behind the pending nonce reported by the node.
+func (m *Manager) sync(ctx context.Context) error {
+	chainNonce, err := m.backend.PendingNonceAt(ctx, m.opts.From)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce for %s: %v", m.opts.From.Hex(), err)
+	}
+	if chainNonce > m.state.NextNonce {
+		m.state.NextNonce = chainNonce
+	}
+	return nil
+}
+
+func (m *Manager) logf(format string, args ...interface{}) {
+	if m.Log != nil {
+		fmt.Fprintf(m.Log, format+"\n", args...)
+	}
+}
+
+func (m *Manager) save() error {
+	if m.statePath == "" {
+		return nil
+	}
+	return m.state.Save(m.statePath)
+}
+
+// bump increases value by the given percentage, rounding up.
+func bump(value *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(value, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return new(big.Int).Set(b)
+}
+
+func isNonceError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "nonce too low")
+}
+
+func isKnownTransactionError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "already known") || strings.Contains(message, "nonce too low")
+}
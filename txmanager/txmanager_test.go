@@ -0,0 +1,168 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var testChainID = big.NewInt(1337)
+
+// testBackend is a node which accepts every transaction, except those whose nonce is in reject.
+type testBackend struct {
+	Backend
+
+	minedNonce   uint64
+	pendingNonce uint64
+	reject       map[uint64]error
+	sent         []*types.Transaction
+}
+
+func (b *testBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return b.minedNonce, nil
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.pendingNonce, nil
+}
+
+func (b *testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.reject[tx.Nonce()]; err != nil {
+		return err
+	}
+	b.sent = append(b.sent, tx)
+	if tx.Nonce() >= b.pendingNonce {
+		b.pendingNonce = tx.Nonce() + 1
+	}
+	return nil
+}
+
+func newTestManager(t *testing.T, backend *testBackend, statePath string) *Manager {
+	t.Helper()
+	key, keyErr := crypto.GenerateKey()
+	if keyErr != nil {
+		t.Fatalf("failed to generate key: %v", keyErr)
+	}
+	opts, optsErr := bind.NewKeyedTransactorWithChainID(key, testChainID)
+	if optsErr != nil {
+		t.Fatalf("failed to create transactor: %v", optsErr)
+	}
+	manager, managerErr := New(context.Background(), backend, opts, testChainID, statePath)
+	if managerErr != nil {
+		t.Fatalf("failed to create manager: %v", managerErr)
+	}
+	manager.Log = nil
+	return manager
+}
+
+// sendTransfer returns a TransactFunc which signs a transfer and sends it to backend, failing with
+// sendErr after the send if it is non-nil.
+func sendTransfer(backend *testBackend, sendErr error) TransactFunc {
+	return func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		tx, signErr := opts.Signer(opts.From, types.NewTx(&types.DynamicFeeTx{
+			ChainID:   testChainID,
+			Nonce:     opts.Nonce.Uint64(),
+			GasTipCap: big.NewInt(100),
+			GasFeeCap: big.NewInt(1000),
+			Gas:       21000,
+			To:        &to,
+		}))
+		if signErr != nil {
+			return nil, signErr
+		}
+		if err := backend.SendTransaction(opts.Context, tx); err != nil {
+			return nil, err
+		}
+		return tx, sendErr
+	}
+}
+
+func TestTransactResyncsAfterAmbiguousError(t *testing.T) {
+	backend := &testBackend{}
+	manager := newTestManager(t, backend, "")
+	ctx := context.Background()
+
+	// The node received the transaction, but the response was lost.
+	if _, err := manager.Transact(ctx, sendTransfer(backend, context.DeadlineExceeded)); err == nil {
+		t.Fatalf("expected the error from the transaction to be returned")
+	}
+	if nonce := manager.NextNonce(); nonce != 1 {
+		t.Fatalf("expected the nonce used by the timed out transaction to be skipped, next nonce is %d", nonce)
+	}
+
+	tx, err := manager.Transact(ctx, sendTransfer(backend, nil))
+	if err != nil {
+		t.Fatalf("failed to transact: %v", err)
+	}
+	if tx.Nonce() != 1 {
+		t.Fatalf("expected nonce 1, got %d", tx.Nonce())
+	}
+
+	// A transaction which the node rejected does not consume its nonce.
+	backend.reject = map[uint64]error{2: errors.New("insufficient funds for gas * price + value")}
+	if _, err := manager.Transact(ctx, sendTransfer(backend, nil)); err == nil {
+		t.Fatalf("expected the rejected transaction to fail")
+	}
+	if nonce := manager.NextNonce(); nonce != 2 {
+		t.Fatalf("expected nonce 2 to be reused, next nonce is %d", nonce)
+	}
+}
+
+func TestRebroadcastContinuesAfterFailedReplacement(t *testing.T) {
+	backend := &testBackend{}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	manager := newTestManager(t, backend, statePath)
+	ctx := context.Background()
+
+	originals := make([]*types.Transaction, 3)
+	for i := range originals {
+		tx, err := manager.Transact(ctx, sendTransfer(backend, nil))
+		if err != nil {
+			t.Fatalf("failed to transact: %v", err)
+		}
+		originals[i] = tx
+	}
+
+	// The first transaction was mined. Of the stuck ones, the replacement for nonce 1 is rejected.
+	backend.minedNonce = 1
+	backend.reject = map[uint64]error{1: errors.New("replacement transaction underpriced")}
+	manager.StuckAfter = 0
+	if err := manager.Rebroadcast(ctx); err != nil {
+		t.Fatalf("failed to rebroadcast: %v", err)
+	}
+
+	pending := manager.Pending()
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending transactions, got %d", len(pending))
+	}
+	if pending[0].Hash != originals[1].Hash() || pending[0].Bumps != 0 {
+		t.Fatalf("expected the transaction whose replacement failed to be kept unchanged, got %+v", pending[0])
+	}
+	if pending[1].Bumps != 1 || len(pending[1].Replaced) != 1 || pending[1].Replaced[0] != originals[2].Hash() {
+		t.Fatalf("expected the last transaction to be replaced, got %+v", pending[1])
+	}
+
+	state, stateErr := LoadState(statePath)
+	if stateErr != nil {
+		t.Fatalf("failed to load state: %v", stateErr)
+	}
+	if len(state.Pending) != 2 || state.Pending[1].Hash != pending[1].Hash {
+		t.Fatalf("expected the replacement to be saved, got %+v", state.Pending)
+	}
+	if time.Since(state.Pending[1].LastBroadcastAt) > time.Minute {
+		t.Fatalf("expected the replacement's broadcast time to be recorded")
+	}
+}